@@ -0,0 +1,33 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+// ErrNotSupported is returned for features the engine doesn't have, like
+// sessions of SQLite, which has no server.
+var ErrNotSupported = errors.New("not supported by the engine")
+
+var errNoSession = fmt.Errorf("%w: no such session", errs.ErrValidation)
+
+// Session is a connection to the server, with the query it runs if any.
+type Session struct {
+	ID       int64
+	User     string
+	Database string
+	Client   string
+	State    string
+	Query    string
+	// Duration is how long the query has been running, or how long the
+	// session has been in its state when it runs none.
+	Duration time.Duration
+	// Wait is what the session waits for, empty when it doesn't.
+	Wait string
+	// BlockedBy holds ids of the sessions holding locks this one waits
+	// for.
+	BlockedBy []int64
+}
@@ -0,0 +1,99 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+const serverCancelTimeout = time.Second * 5
+
+// serverCancel knows how to stop a statement on the server. Cancelling
+// the context only makes the driver give up waiting, while the server
+// keeps working on the statement, so it has to be told explicitly.
+type serverCancel struct {
+	// sessionQuery returns the id of the current session.
+	sessionQuery string
+	// cancel builds the statement stopping work of the session.
+	cancel func(id string) (string, []any)
+}
+
+var (
+	postgresCancel = &serverCancel{
+		sessionQuery: "SELECT pg_backend_pid()",
+		cancel: func(id string) (string, []any) {
+			return "SELECT pg_cancel_backend($1)", []any{id}
+		},
+	}
+
+	// cockroachCancel stops the statements of the session by their query
+	// ids, as CockroachDB has no pg_cancel_backend.
+	cockroachCancel = &serverCancel{
+		sessionQuery: "SHOW session_id",
+		cancel: func(id string) (string, []any) {
+			return `CANCEL QUERIES IF EXISTS (
+				SELECT query_id FROM [SHOW CLUSTER STATEMENTS] WHERE session_id = $1
+			)`, []any{id}
+		},
+	}
+
+	mysqlCancel = &serverCancel{
+		sessionQuery: "SELECT CONNECTION_ID()",
+		cancel: func(id string) (string, []any) {
+			return "KILL QUERY " + id, nil
+		},
+	}
+)
+
+// run calls fn on a dedicated connection and cancels the statement on
+// the server once ctx is done before fn returns.
+func (sc *serverCancel) run(ctx context.Context, db *sqlx.DB, fn func(conn *sqlx.Conn) error) error {
+	conn, err := db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	var id string
+	if err := conn.GetContext(ctx, &id, sc.sessionQuery); err != nil {
+		return fmt.Errorf("get session id: %w", err)
+	}
+
+	var (
+		done = make(chan struct{})
+		wg   sync.WaitGroup
+	)
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		select {
+		case <-done:
+		case <-ctx.Done():
+			sc.cancelSession(db, id)
+		}
+	}()
+
+	err = fn(conn)
+
+	// The connection must not go back to the pool while a cancel for
+	// it may still be on the way.
+	close(done)
+	wg.Wait()
+
+	return err
+}
+
+func (sc *serverCancel) cancelSession(db *sqlx.DB, id string) {
+	ctx, cancel := context.WithTimeout(context.Background(), serverCancelTimeout)
+	defer cancel()
+
+	query, args := sc.cancel(id)
+	if _, err := db.ExecContext(ctx, query, args...); err != nil {
+		slog.Error("Cancel query on server", slog.String("session", id), slog.Any("err", err))
+	}
+}
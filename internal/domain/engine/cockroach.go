@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// cockroachDB speaks the protocol of PostgreSQL, but keeps its statistics
+// in crdb_internal, explains plans as text only and tells sessions apart
+// by ids which are not numbers.
+type cockroachDB struct {
+	*postgreSQL
+}
+
+// newCockroachDB wraps e so the statements it runs are cancelled the way
+// CockroachDB does it.
+func newCockroachDB(e *postgreSQL) *cockroachDB {
+	e.cancel = cockroachCancel
+	return &cockroachDB{postgreSQL: e}
+}
+
+// isCockroachDB tells whether version() was returned by CockroachDB, like
+// "CockroachDB CCL v24.1.0 (x86_64-pc-linux-gnu, ...)".
+func isCockroachDB(version string) bool {
+	return strings.HasPrefix(version, "CockroachDB")
+}
+
+// cockroachDBVersion returns the release of the server, without the
+// edition and the build details.
+func cockroachDBVersion(version string) string {
+	for _, field := range strings.Fields(version) {
+		if release, ok := strings.CutPrefix(field, "v"); ok {
+			return release
+		}
+	}
+	return version
+}
+
+// Explain analyzes within a transaction which is rolled back, so the
+// changes of a statement which writes aren't kept.
+func (e *cockroachDB) Explain(ctx context.Context, query string, analyze bool) ([]PlanNode, error) {
+	run := within
+	statement := "EXPLAIN "
+	if analyze {
+		if err := checkReadOnly(e.readOnly, query); err != nil {
+			return nil, err
+		}
+		run = rolledBack
+		statement = "EXPLAIN ANALYZE "
+	}
+
+	var lines []string
+	err := e.serverCancel().run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return run(ctx, conn, e.readOnly, func(q queryer) error {
+			rows, err := q.QueryxContext(ctx, statement+query)
+			if err != nil {
+				return err
+			}
+			defer rows.Close()
+
+			for rows.Next() {
+				var line string
+				if err := rows.Scan(&line); err != nil {
+					return err
+				}
+				lines = append(lines, line)
+			}
+			return rows.Err()
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain query %q: %w", query, err)
+	}
+
+	return parseCockroachDBPlan(lines), nil
+}
+
+// GetActivity is not supported, as sessions of CockroachDB have ids
+// which don't fit the numeric ones of the activity view.
+func (e *cockroachDB) GetActivity(context.Context) ([]Session, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *cockroachDB) GetLocks(context.Context) ([]Lock, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *cockroachDB) CancelQuery(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *cockroachDB) TerminateSession(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *cockroachDB) ReadOnly() Explorer {
+	return &cockroachDB{postgreSQL: e.postgreSQL.ReadOnly().(*postgreSQL)}
+}
+
+func (e *cockroachDB) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SELECT version()"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "CockroachDB", Version: cockroachDBVersion(version)}, nil
+}
+
+// GetTables reads information_schema, leaving out the virtual schemas
+// CockroachDB adds, like crdb_internal, along with the ones of
+// PostgreSQL.
+func (e *cockroachDB) GetTables(ctx context.Context) ([]Table, error) {
+	const query = `
+		SELECT table_name AS tablename, table_schema AS schemaname
+		FROM information_schema.tables
+		WHERE table_type = 'BASE TABLE' AND table_schema NOT IN (
+			'pg_catalog', 'information_schema', 'crdb_internal', 'pg_extension'
+		)
+	`
+
+	var tables []postgreSQLTable
+	if err := e.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, err
+	}
+
+	return e.toTables(tables), nil
+}
+
+type cockroachDBTableStats struct {
+	Name string `db:"name"`
+	Rows int64  `db:"rows"`
+}
+
+// GetTableStats reads the row counts estimated from the last statistics
+// collected. CockroachDB has no cheap way to tell table sizes, so they
+// are left zero.
+func (e *cockroachDB) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	const query = `
+		SELECT t.name, COALESCE(s.estimated_row_count, 0) AS rows
+		FROM crdb_internal.tables t
+		JOIN crdb_internal.table_row_statistics s ON s.table_id = t.table_id
+		WHERE t.database_name = current_database()
+			AND t.schema_name != 'pg_catalog' AND t.schema_name != 'information_schema'
+			AND t.state = 'PUBLIC'
+	`
+
+	var tables []cockroachDBTableStats
+	if err := e.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, fmt.Errorf("get table stats: %w", err)
+	}
+
+	stats := make(map[string]TableStats, len(tables))
+	for _, t := range tables {
+		stats[t.Name] = TableStats{Rows: t.Rows}
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,173 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+var cockroachDBTestDSN string
+
+func Test_cockroachDBVersion(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name            string
+		version         string
+		wantCockroachDB bool
+		want            string
+	}{
+		{
+			name:            "Should detect CockroachDB",
+			version:         "CockroachDB CCL v24.1.0 (x86_64-pc-linux-gnu, built 2024/05/15 21:28:29, go1.22.2)",
+			wantCockroachDB: true,
+			want:            "24.1.0",
+		},
+		{
+			name:            "Should not detect PostgreSQL",
+			version:         "PostgreSQL 15.6 on x86_64-pc-linux-musl, compiled by gcc, 64-bit",
+			wantCockroachDB: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.wantCockroachDB, isCockroachDB(tt.version))
+			if tt.wantCockroachDB {
+				require.Equal(t, tt.want, cockroachDBVersion(tt.version))
+			}
+		})
+	}
+}
+
+func Test_cockroachDB_ServerInfo(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, cockroachDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e, err := postgreSQLEngine{}.Explorer(t.Context(), db, Target{Database: dbName})
+	require.NoError(t, err)
+	require.IsType(t, &cockroachDB{}, e)
+
+	got, err := e.ServerInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "CockroachDB", got.Engine)
+	require.NotEmpty(t, got.Version)
+
+	_, err = e.GetActivity(t.Context())
+	require.ErrorIs(t, err, ErrNotSupported)
+}
+
+func Test_cockroachDB_GetTables(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, cockroachDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := newCockroachDB(&postgreSQL{
+		db:     db,
+		schema: dbName,
+	})
+
+	got, err := e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []Table{{Name: tableName, Schema: "public"}}, got)
+}
+
+func Test_cockroachDB_GetTableStats(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, cockroachDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := newCockroachDB(&postgreSQL{
+		db:     db,
+		schema: dbName,
+	})
+
+	_, err := db.ExecContext(t.Context(), "ANALYZE "+tableName)
+	require.NoError(t, err)
+
+	got, err := e.GetTableStats(t.Context())
+	require.NoError(t, err)
+	require.Contains(t, got, tableName)
+	require.Equal(t, int64(3), got[tableName].Rows)
+}
+
+func Test_cockroachDB_Explain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, cockroachDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := newCockroachDB(&postgreSQL{
+		db:     db,
+		schema: dbName,
+	})
+
+	got, err := e.Explain(t.Context(), "SELECT * FROM users", false)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	scan := got[0]
+	require.Equal(t, "Scan", scan.Operation)
+	require.Equal(t, "users", scan.Relation)
+	require.True(t, scan.FullScan)
+}
+
+func Test_cockroachDB_Query_Cancel(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, cockroachDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := newCockroachDB(&postgreSQL{
+		db:     db,
+		schema: dbName,
+	})
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond*200)
+	defer cancel()
+
+	_, _, err := e.Query(ctx, "SELECT pg_sleep(30)")
+	require.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		var running int
+		err := db.GetContext(
+			t.Context(),
+			&running,
+			"SELECT count(*) FROM [SHOW CLUSTER STATEMENTS] WHERE query LIKE 'SELECT pg_sleep%'",
+		)
+		return err == nil && running == 0
+	}, time.Second*5, time.Millisecond*100)
+}
+
+func newTestCockroachDB(ctx context.Context) (testcontainers.Container, error) {
+	container, err := testcontainers.GenericContainer(ctx, testcontainers.GenericContainerRequest{
+		ContainerRequest: testcontainers.ContainerRequest{
+			Image:        "cockroachdb/cockroach:v24.1.0",
+			Cmd:          []string{"start-single-node", "--insecure"},
+			ExposedPorts: []string{"26257/tcp", "8080/tcp"},
+			Env:          map[string]string{"COCKROACH_DATABASE": dbName},
+			WaitingFor:   wait.ForHTTP("/health?ready=1").WithPort("8080/tcp"),
+		},
+		Started: true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("start cockroachdb container: %w", err)
+	}
+
+	endpoint, err := container.PortEndpoint(ctx, "26257/tcp", "")
+	if err != nil {
+		return nil, fmt.Errorf("get cockroachdb endpoint: %w", err)
+	}
+
+	cockroachDBTestDSN = fmt.Sprintf("postgres://root@%s/%s?sslmode=disable", endpoint, dbName)
+
+	return container, nil
+}
@@ -0,0 +1,306 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const (
+	duckDBExtension = ".duckdb"
+	duckDBMemory    = ":memory:"
+)
+
+// duckDBReaders are the table functions reading data files, by extension.
+var duckDBReaders = map[string]string{
+	".parquet": "read_parquet(%s)",
+	".csv":     "read_csv_auto(%s)",
+	".tsv":     `read_csv_auto(%s, delim = '\t')`,
+}
+
+// duckDBEngine opens DuckDB files, an in-memory database for the bare
+// duckdb:// scheme, or one exposing the Parquet and CSV files of a
+// directory as tables when the DSN points to a directory.
+type duckDBEngine struct{}
+
+func (duckDBEngine) Driver() string {
+	return duckdbDB
+}
+
+func (duckDBEngine) Schemes() []string {
+	return []string{"duckdb"}
+}
+
+func (duckDBEngine) Detect(dsn string) bool {
+	if strings.Contains(dsn, "@") {
+		return false
+	}
+
+	path, _, _ := strings.Cut(strings.ToLower(dsn), "?")
+	return strings.HasSuffix(path, duckDBExtension)
+}
+
+func (duckDBEngine) Parse(dsn string) (Target, error) {
+	path := trimScheme(dsn)
+	if path == "" || path == duckDBMemory {
+		return Target{DSN: duckDBMemory}, nil
+	}
+
+	file, _, _ := strings.Cut(path, "?")
+	info, err := os.Stat(file)
+	if err != nil || !info.IsDir() {
+		return Target{DSN: path, Database: file}, nil
+	}
+
+	files, err := duckDBFiles(file)
+	if err != nil {
+		return Target{}, err
+	}
+	if len(files) == 0 {
+		return Target{}, fmt.Errorf("%w: no parquet or csv files in %q", errs.ErrValidation, file)
+	}
+
+	return Target{DSN: duckDBMemory, Database: file, Files: files}, nil
+}
+
+// duckDBFiles lists the files of the directory DuckDB can read.
+func duckDBFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read directory %q: %w", dir, err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		ext := strings.ToLower(filepath.Ext(entry.Name()))
+		if _, ok := duckDBReaders[ext]; ok && !entry.IsDir() {
+			files = append(files, filepath.Join(dir, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// Explorer creates a view over each of the files of the target, named
+// after the file, replacing the ones made by an earlier connect so
+// files added since show up.
+func (duckDBEngine) Explorer(ctx context.Context, db *sqlx.DB, target Target) (Explorer, error) {
+	for _, file := range target.Files {
+		ext := filepath.Ext(file)
+		reader := fmt.Sprintf(duckDBReaders[strings.ToLower(ext)], duckDBLiteral(file))
+		name := strings.TrimSuffix(filepath.Base(file), ext)
+
+		query := fmt.Sprintf("CREATE OR REPLACE VIEW %s AS SELECT * FROM %s", duckDBIdentifier(name), reader)
+		if _, err := db.ExecContext(ctx, query); err != nil {
+			return nil, fmt.Errorf("expose %q: %w", file, err)
+		}
+	}
+
+	return &duckDB{db: db, path: target.Database}, nil
+}
+
+func duckDBIdentifier(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, `""`) + `"`
+}
+
+func duckDBLiteral(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", "''") + "'"
+}
+
+// duckDBTableName names tables of duckdb_tables() and the like the way
+// GetTables does: tables of the current schema by their name alone, the
+// others qualified by the attached database and the schema.
+const duckDBTableName = `
+	CASE
+		WHEN database_name = current_database() AND schema_name = current_schema() THEN table_name
+		WHEN schema_name = 'main' THEN database_name || '.' || table_name
+		ELSE database_name || '.' || schema_name || '.' || table_name
+	END
+`
+
+type duckDB struct {
+	db       *sqlx.DB
+	path     string
+	readOnly bool
+}
+
+type duckDBTable struct {
+	Name     string `db:"name"`
+	Database string `db:"database_name"`
+}
+
+func (e *duckDB) Execute(ctx context.Context, query string) (int64, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return 0, err
+	}
+	return execute(ctx, e.db, query)
+}
+
+// Explain reads the plan as JSON. DuckDB has no read-only transactions,
+// so analyzing is refused for statements which write instead.
+func (e *duckDB) Explain(ctx context.Context, query string, analyze bool) ([]PlanNode, error) {
+	options := "FORMAT JSON"
+	if analyze {
+		if err := checkReadOnly(e.readOnly, query); err != nil {
+			return nil, err
+		}
+		options = "ANALYZE, " + options
+	}
+
+	var key, raw string
+	statement := fmt.Sprintf("EXPLAIN (%s) %s", options, query)
+	if err := e.db.QueryRowxContext(ctx, statement).Scan(&key, &raw); err != nil {
+		return nil, fmt.Errorf("explain query %q: %w", query, err)
+	}
+
+	return parseDuckDBPlan([]byte(raw))
+}
+
+func (e *duckDB) GetActivity(context.Context) ([]Session, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *duckDB) GetLocks(context.Context) ([]Lock, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *duckDB) CancelQuery(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *duckDB) TerminateSession(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *duckDB) GetPrimaryKey(ctx context.Context, table string) ([]Column, error) {
+	query := `
+		SELECT unnest(constraint_column_names)
+		FROM duckdb_constraints()
+		WHERE constraint_type = 'PRIMARY KEY' AND ` + duckDBTableName + ` = ?
+	`
+	var key []Column
+	if err := e.db.SelectContext(ctx, &key, query, table); err != nil {
+		return nil, fmt.Errorf("get primary key of %q: %w", table, err)
+	}
+	return key, nil
+}
+
+// ReadOnly returns an explorer rejecting statements which write. DuckDB
+// has no read-only transactions, so they are not relied upon.
+func (e *duckDB) ReadOnly() Explorer {
+	ro := *e
+	ro.readOnly = true
+	return &ro
+}
+
+func (e *duckDB) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SELECT version()"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "DuckDB", Version: strings.TrimPrefix(version, "v")}, nil
+}
+
+func (e *duckDB) Query(ctx context.Context, query string) ([]Row, []Column, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return nil, nil, err
+	}
+
+	rows, cols, err := queryRows(ctx, e.db, query)
+	if err != nil {
+		return nil, nil, fmt.Errorf("run query %q: %w", query, err)
+	}
+	return rows, cols, nil
+}
+
+// GetTables lists tables and views of every attached database, telling
+// which database each one belongs to.
+func (e *duckDB) GetTables(ctx context.Context) ([]Table, error) {
+	query := `
+		SELECT ` + duckDBTableName + ` AS name, database_name
+		FROM (
+			SELECT database_name, schema_name, table_name FROM duckdb_tables() WHERE NOT internal
+			UNION ALL
+			SELECT database_name, schema_name, view_name FROM duckdb_views() WHERE NOT internal
+		)
+		ORDER BY database_name != current_database(), name
+	`
+
+	var tables []duckDBTable
+	if err := e.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, err
+	}
+
+	result := make([]Table, 0, len(tables))
+	for _, t := range tables {
+		result = append(result, Table{Name: t.Name, Schema: t.Database})
+	}
+	return result, nil
+}
+
+type duckDBTableStats struct {
+	Name string `db:"name"`
+	Rows int64  `db:"estimated_size"`
+}
+
+// GetTableStats reads the row counts DuckDB keeps for its tables. Views
+// over files are left out, as telling their size means reading them.
+func (e *duckDB) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	query := `
+		SELECT ` + duckDBTableName + ` AS name, estimated_size
+		FROM duckdb_tables()
+		WHERE NOT internal
+	`
+
+	var tables []duckDBTableStats
+	if err := e.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, fmt.Errorf("get table stats: %w", err)
+	}
+
+	stats := make(map[string]TableStats, len(tables))
+	for _, t := range tables {
+		stats[t.Name] = TableStats{Rows: t.Rows}
+	}
+	return stats, nil
+}
+
+func (e *duckDB) GetColumns(ctx context.Context, table string) ([]Row, []Column, error) {
+	return queryRows(ctx, e.db, "DESCRIBE "+table)
+}
+
+func (e *duckDB) GetRows(ctx context.Context, table string) ([]Row, []Column, error) {
+	return queryRows(ctx, e.db, "SELECT * FROM "+table)
+}
+
+func (e *duckDB) GetIndexes(ctx context.Context, table string) ([]Row, []Column, error) {
+	// Describing a view doesn't read the files behind it, unlike GetRows.
+	if _, _, err := e.GetColumns(ctx, table); err != nil {
+		return nil, nil, fmt.Errorf("table may not exist: %w", err)
+	}
+
+	query := `
+		SELECT index_name, is_unique, is_primary, expressions, sql
+		FROM duckdb_indexes()
+		WHERE ` + duckDBTableName + ` = ?
+	`
+	return queryRows(ctx, e.db, query, table)
+}
+
+func (e *duckDB) GetConstraints(ctx context.Context, table string) ([]Row, []Column, error) {
+	// Describing a view doesn't read the files behind it, unlike GetRows.
+	if _, _, err := e.GetColumns(ctx, table); err != nil {
+		return nil, nil, fmt.Errorf("table may not exist: %w", err)
+	}
+
+	query := `
+		SELECT constraint_name, constraint_type, constraint_text, constraint_column_names
+		FROM duckdb_constraints()
+		WHERE ` + duckDBTableName + ` = ?
+	`
+	return queryRows(ctx, e.db, query, table)
+}
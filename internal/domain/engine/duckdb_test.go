@@ -0,0 +1,234 @@
+package engine
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/marcboeker/go-duckdb"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func Test_duckDBEngine_Parse(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users.csv"), "id,name\n1,John\n")
+	writeFile(t, filepath.Join(dir, "events.tsv"), "id\tkind\n1\tlogin\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "not a table")
+
+	empty := t.TempDir()
+
+	tests := []struct {
+		name    string
+		dsn     string
+		want    Target
+		wantErr bool
+	}{
+		{
+			name: "Should open in-memory database for bare scheme",
+			dsn:  "duckdb://",
+			want: Target{DSN: duckDBMemory},
+		},
+		{
+			name: "Should open file",
+			dsn:  "duckdb://data/app.duckdb?access_mode=read_only",
+			want: Target{DSN: "data/app.duckdb?access_mode=read_only", Database: "data/app.duckdb"},
+		},
+		{
+			name: "Should expose files of directory",
+			dsn:  "duckdb://" + dir,
+			want: Target{
+				DSN:      duckDBMemory,
+				Database: dir,
+				Files:    []string{filepath.Join(dir, "events.tsv"), filepath.Join(dir, "users.csv")},
+			},
+		},
+		{
+			name:    "Should return an error when directory has no files to read",
+			dsn:     "duckdb://" + empty,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := duckDBEngine{}.Parse(tt.dsn)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_duckDBEngine_Explorer(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "users.csv"), "id,name\n1,John\n2,Jane\n")
+	writeFile(t, filepath.Join(dir, "events.tsv"), "id\tkind\n1\tlogin\n")
+
+	target, err := duckDBEngine{}.Parse("duckdb://" + dir)
+	require.NoError(t, err)
+
+	db := sqlx.MustOpen(duckdbDB, target.DSN)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	e, err := duckDBEngine{}.Explorer(t.Context(), db, target)
+	require.NoError(t, err)
+
+	tables, err := e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []Table{
+		{Name: "events", Schema: "memory"},
+		{Name: "users", Schema: "memory"},
+	}, tables)
+
+	rows, cols, err := e.GetRows(t.Context(), "events")
+	require.NoError(t, err)
+	require.Equal(t, []Column{"id", "kind"}, cols)
+	require.Equal(t, []Row{{"1", "login"}}, rows)
+
+	// Connecting again picks up files added since.
+	writeFile(t, filepath.Join(dir, "orders.csv"), "id\n1\n")
+	target, err = duckDBEngine{}.Parse("duckdb://" + dir)
+	require.NoError(t, err)
+
+	e, err = duckDBEngine{}.Explorer(t.Context(), db, target)
+	require.NoError(t, err)
+
+	tables, err = e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.Len(t, tables, 3)
+}
+
+func Test_duckDB_GetTables(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedDuckDB(t)
+	t.Cleanup(cleanup)
+
+	_, err := db.ExecContext(t.Context(), "ATTACH ':memory:' AS archive")
+	require.NoError(t, err)
+	_, err = db.ExecContext(t.Context(), "CREATE TABLE archive.events (id INTEGER)")
+	require.NoError(t, err)
+
+	e := &duckDB{db: db}
+
+	got, err := e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []Table{
+		{Name: tableName, Schema: "memory"},
+		{Name: "archive.events", Schema: "archive"},
+	}, got)
+
+	stats, err := e.GetTableStats(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, int64(3), stats[tableName].Rows)
+	require.Contains(t, stats, "archive.events")
+
+	_, cols, err := e.GetColumns(t.Context(), "archive.events")
+	require.NoError(t, err)
+	require.Contains(t, cols, "column_name")
+}
+
+func Test_duckDB_GetPrimaryKey(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedDuckDB(t)
+	t.Cleanup(cleanup)
+
+	e := &duckDB{db: db}
+
+	got, err := e.GetPrimaryKey(t.Context(), tableName)
+	require.NoError(t, err)
+	require.Equal(t, []Column{"id"}, got)
+
+	rows, _, err := e.GetConstraints(t.Context(), tableName)
+	require.NoError(t, err)
+	require.NotEmpty(t, rows)
+
+	_, _, err = e.GetIndexes(t.Context(), "missing")
+	require.Error(t, err)
+}
+
+func Test_duckDB_Explain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedDuckDB(t)
+	t.Cleanup(cleanup)
+
+	e := &duckDB{db: db}
+
+	for _, analyze := range []bool{false, true} {
+		got, err := e.Explain(t.Context(), "SELECT * FROM users", analyze)
+		require.NoError(t, err)
+		require.Len(t, got, 1)
+
+		scan := got[0]
+		require.Equal(t, tableName, scan.Relation)
+		require.True(t, scan.FullScan)
+		require.Equal(t, analyze, scan.Analyzed)
+	}
+}
+
+func Test_duckDB_ReadOnly(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedDuckDB(t)
+	t.Cleanup(cleanup)
+
+	e := (&duckDB{db: db}).ReadOnly()
+
+	_, err := e.Execute(t.Context(), "DELETE FROM users")
+	require.ErrorIs(t, err, ErrReadOnly)
+
+	rows, _, err := e.Query(t.Context(), "SELECT * FROM users")
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+
+	info, err := e.ServerInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "DuckDB", info.Engine)
+
+	_, err = e.GetActivity(t.Context())
+	require.ErrorIs(t, err, ErrNotSupported)
+}
+
+func seedDuckDB(t *testing.T) (*sqlx.DB, func()) {
+	ctx := t.Context()
+	db, err := sqlx.ConnectContext(ctx, duckdbDB, duckDBMemory)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			name VARCHAR(100),
+			email VARCHAR(100)
+		)
+	`)
+	require.NoError(t, err)
+
+	_, err = db.ExecContext(ctx, `
+		INSERT INTO users (id, name, email) VALUES
+			(1, 'John Doe', 'john@example.com'),
+			(2, 'Jane Smith', 'jane@example.com'),
+			(3, 'Bob Wilson', 'bob@example.com')
+	`)
+	require.NoError(t, err)
+
+	return db, func() {
+		require.NoError(t, db.Close(), "Failed to close database connection")
+	}
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+}
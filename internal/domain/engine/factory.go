@@ -4,8 +4,8 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
-	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/errs"
@@ -14,41 +14,98 @@ import (
 const (
 	mysqlDB      = "mysql"
 	postgresqlDB = "postgres"
-	fileDBSuffix = ".db"
 	sqliteDB     = "sqlite3"
+	duckdbDB     = "duckdb"
+	filesDB      = "files"
 )
 
 //go:generate mockgen -destination=mock_factory.go -package=engine . Explorer
 type Explorer interface {
 	GetTables(ctx context.Context) ([]Table, error)
+	// GetTableStats returns how big the tables are, by table name. It is
+	// slower than GetTables, so it is meant to be called once the list is
+	// shown.
+	GetTableStats(ctx context.Context) (map[string]TableStats, error)
 	GetRows(ctx context.Context, table string) ([]Row, []Column, error)
 	GetColumns(ctx context.Context, table string) ([]Row, []Column, error)
 	GetIndexes(ctx context.Context, table string) ([]Row, []Column, error)
 	GetConstraints(ctx context.Context, table string) ([]Row, []Column, error)
-	Execute(ctx context.Context, query string) error
+	GetPrimaryKey(ctx context.Context, table string) ([]Column, error)
+	Execute(ctx context.Context, query string) (int64, error)
+	Query(ctx context.Context, query string) ([]Row, []Column, error)
+	// Explain returns the plan of the query. With analyze the query is
+	// run as well, so the plan shows how long each step took where the
+	// engine can tell.
+	Explain(ctx context.Context, query string, analyze bool) ([]PlanNode, error)
+	ServerInfo(ctx context.Context) (ServerInfo, error)
+	// GetActivity lists the sessions connected to the server, except the
+	// one asking.
+	GetActivity(ctx context.Context) ([]Session, error)
+	// GetLocks returns the locks sessions wait for, along with the locks
+	// they wait for held by other sessions.
+	GetLocks(ctx context.Context) ([]Lock, error)
+	// CancelQuery stops the query the session runs, keeping it connected.
+	CancelQuery(ctx context.Context, id int64) error
+	// TerminateSession disconnects the session.
+	TerminateSession(ctx context.Context, id int64) error
+	// ReadOnly returns an explorer rejecting statements which write and
+	// running queries in read-only transactions.
+	ReadOnly() Explorer
 }
 
 type Table struct {
 	Name   string `db:"TABLE_NAME"`
 	Schema string `db:"TABLE_TYPE"`
+	// Stats is nil until fetched with GetTableStats.
+	Stats *TableStats
+}
+
+// TableStats describe the size of a table. Whatever the engine can't tell
+// is left zero: rows are an estimate except in SQLite, sizes are in bytes,
+// vacuum and analyze times are set by PostgreSQL only and the next
+// auto-increment value by MySQL only.
+type TableStats struct {
+	Rows          int64
+	Size          int64
+	IndexSize     int64
+	LastVacuum    time.Time
+	LastAnalyze   time.Time
+	AutoIncrement int64
+}
+
+type ServerInfo struct {
+	Engine  string
+	Version string
 }
 
 func NewFactory(pool Pool) *Factory {
+	return NewFactoryWithRegistry(pool, DefaultRegistry())
+}
+
+// NewFactoryWithRegistry returns a factory connecting to the engines of
+// the registry.
+func NewFactoryWithRegistry(pool Pool, registry *Registry) *Factory {
 	return &Factory{
-		pool: pool,
+		pool:     pool,
+		registry: registry,
 	}
 }
 
 //go:generate mockgen -destination=mocks/mock_pool.go -package=mocks . Pool
 type Pool interface {
-	Get(ctx context.Context, name, driver, dsn string) (*sqlx.DB, error)
+	// Get returns the connection of the context saved with the DSN,
+	// opening it with driverDSN, which the engine turned the DSN into.
+	Get(ctx context.Context, name, driver, dsn, driverDSN string) (*sqlx.DB, error)
 }
 
 type Factory struct {
-	pool Pool
+	pool     Pool
+	registry *Registry
 }
 
-func (f *Factory) Create(ctx context.Context, name, dsn string) (Explorer, error) {
+// Create connects to the database of the DSN. The driver picks the
+// engine, which is guessed from the DSN when it is empty.
+func (f *Factory) Create(ctx context.Context, name, driver, dsn string) (Explorer, error) {
 	if dsn == "" {
 		return nil, fmt.Errorf("%w: dsn is required", errs.ErrValidation)
 	}
@@ -57,62 +114,26 @@ func (f *Factory) Create(ctx context.Context, name, dsn string) (Explorer, error
 		return nil, fmt.Errorf("%w: name is required", errs.ErrValidation)
 	}
 
-	switch {
-	case strings.HasPrefix(dsn, postgresqlDB):
-		return f.createPostgres(ctx, name, dsn)
-	case strings.Contains(dsn, fileDBSuffix):
-		return f.createSQLite(ctx, name, dsn)
-	case strings.HasPrefix(dsn, mysqlDB) || !strings.HasPrefix(dsn, "://"):
-		return f.createMySQL(ctx, name, cleanDBType(dsn))
-	default:
-		return nil, fmt.Errorf("%w: unsupported database type", errs.ErrValidation)
-	}
-}
-
-func (f *Factory) createSQLite(ctx context.Context, name, file string) (*sqlite, error) {
-	db, err := f.pool.Get(ctx, name, sqliteDB, file)
-	if err != nil {
-		return nil, fmt.Errorf("connect to sqlite: %w", err)
-	}
-
-	return &sqlite{db, file}, nil
-}
-
-func (f *Factory) createPostgres(ctx context.Context, name, dsn string) (*postgreSQL, error) {
 	dsn = strings.TrimSpace(dsn)
-	if !strings.Contains(dsn, "sslmode=") {
-		dsn += "?sslmode=disable"
+	e, err := f.registry.Lookup(driver, dsn)
+	if err != nil {
+		return nil, err
 	}
 
-	db, err := f.pool.Get(ctx, name, postgresqlDB, dsn)
+	target, err := e.Parse(dsn)
 	if err != nil {
-		return nil, fmt.Errorf("connect to postgres: %w", err)
+		return nil, fmt.Errorf("validate %s url: %w", e.Driver(), err)
 	}
 
-	parts := strings.Split(dsn, "/")
-	dbName := strings.Split(parts[len(parts)-1], "?")[0]
-
-	return &postgreSQL{db, dbName}, nil
-}
-
-func (f *Factory) createMySQL(ctx context.Context, name, dsn string) (*mySQL, error) {
-	params, err := mysql.ParseDSN(dsn)
+	db, err := f.pool.Get(ctx, name, e.Driver(), dsn, target.DSN)
 	if err != nil {
-		return nil, fmt.Errorf("validate mysql url: %w", err)
+		return nil, fmt.Errorf("connect to %s: %w", e.Driver(), err)
 	}
 
-	db, err := f.pool.Get(ctx, name, mysqlDB, dsn)
+	explorer, err := e.Explorer(ctx, db, target)
 	if err != nil {
-		return nil, fmt.Errorf("connect to mysql: %w", err)
+		return nil, fmt.Errorf("explore %s: %w", e.Driver(), err)
 	}
 
-	return &mySQL{db, params.DBName}, nil
-}
-
-func cleanDBType(dsn string) string {
-	splits := strings.Split(dsn, "://")
-	if len(splits) != 2 {
-		return dsn
-	}
-	return splits[1]
+	return explorer, nil
 }
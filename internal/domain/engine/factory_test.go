@@ -25,8 +25,10 @@ func TestMain(m *testing.M) {
 	defer cancel()
 
 	var (
-		mysql    *mysql.MySQLContainer
-		postgres *postgres.PostgresContainer
+		mariaDB   *mysql.MySQLContainer
+		mysql     *mysql.MySQLContainer
+		postgres  *postgres.PostgresContainer
+		cockroach testcontainers.Container
 	)
 
 	g, ctx := errgroup.WithContext(ctx)
@@ -48,6 +50,24 @@ func TestMain(m *testing.M) {
 		return nil
 	})
 
+	g.Go(func() error {
+		var err error
+		mariaDB, err = newTestMariaDB(ctx)
+		if err != nil {
+			return fmt.Errorf("start mariadb container: %w", err)
+		}
+		return nil
+	})
+
+	g.Go(func() error {
+		var err error
+		cockroach, err = newTestCockroachDB(ctx)
+		if err != nil {
+			return fmt.Errorf("start cockroachdb container: %w", err)
+		}
+		return nil
+	})
+
 	if err := g.Wait(); err != nil {
 		log.Fatal("Could not start container: ", err)
 	}
@@ -68,6 +88,20 @@ func TestMain(m *testing.M) {
 			return nil
 		})
 
+		g.Go(func() error {
+			if err := testcontainers.TerminateContainer(mariaDB); err != nil {
+				return fmt.Errorf("terminate mariadb container: %w", err)
+			}
+			return nil
+		})
+
+		g.Go(func() error {
+			if err := testcontainers.TerminateContainer(cockroach); err != nil {
+				return fmt.Errorf("terminate cockroachdb container: %w", err)
+			}
+			return nil
+		})
+
 		if err := g.Wait(); err != nil {
 			log.Fatal("Could not terminate container: ", err)
 		}
@@ -93,7 +127,8 @@ func TestNewFactory(t *testing.T) {
 				pool: mocks.NewMockPool(gomock.NewController(t)),
 			},
 			want: &Factory{
-				pool: mocks.NewMockPool(gomock.NewController(t)),
+				pool:     mocks.NewMockPool(gomock.NewController(t)),
+				registry: DefaultRegistry(),
 			},
 		},
 	}
@@ -110,13 +145,28 @@ func TestNewFactory(t *testing.T) {
 func TestFactory_Create(t *testing.T) {
 	xtest.SkipUnitIfRequired(t)
 	t.Parallel()
+
+	// Explorers ask the server for its version, so they need a live one.
+	var (
+		pg        = sqlx.MustOpen(postgresqlDB, postgresTestDSN)
+		cockroach = sqlx.MustOpen(postgresqlDB, cockroachDBTestDSN)
+		my        = sqlx.MustOpen(mysqlDB, mysqlTestDSN)
+		maria     = sqlx.MustOpen(mysqlDB, mariaDBTestDSN)
+	)
+	t.Cleanup(func() {
+		for _, db := range []*sqlx.DB{pg, cockroach, my, maria} {
+			require.NoError(t, db.Close())
+		}
+	})
+
 	type fields struct {
 		pool func(ctrl *gomock.Controller) Pool
 	}
 	type args struct {
-		ctx  context.Context
-		name string
-		dsn  string
+		ctx    context.Context
+		name   string
+		driver string
+		dsn    string
 	}
 	tests := []struct {
 		name    string
@@ -177,7 +227,8 @@ func TestFactory_Create(t *testing.T) {
 						"pg",
 						postgresqlDB,
 						"postgres://localhost:5432/testdb?sslmode=disable",
-					).Times(1).Return(&sqlx.DB{}, nil)
+						"postgres://localhost:5432/testdb?sslmode=disable",
+					).Times(1).Return(pg, nil)
 					return p
 				},
 			},
@@ -188,7 +239,7 @@ func TestFactory_Create(t *testing.T) {
 			},
 			want: &postgreSQL{
 				schema: "testdb",
-				db:     &sqlx.DB{},
+				db:     pg,
 			},
 		},
 		{
@@ -200,8 +251,9 @@ func TestFactory_Create(t *testing.T) {
 						gomock.Any(),
 						"mysql",
 						mysqlDB,
-						"root:rootpas@(0.0.0.0:3306)/testdb",
-					).Times(1).Return(&sqlx.DB{}, nil)
+						"mysql://root:rootpas@(0.0.0.0:3306)/testdb",
+						"mysql://root:rootpas@(0.0.0.0:3306)/testdb",
+					).Times(1).Return(my, nil)
 					return p
 				},
 			},
@@ -212,9 +264,60 @@ func TestFactory_Create(t *testing.T) {
 			},
 			want: &mySQL{
 				schema: "testdb",
-				db:     &sqlx.DB{},
+				db:     my,
 			},
 		},
+		{
+			name: "Should create MariaDB connection",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"maria",
+						mysqlDB,
+						"mysql://root:rootpas@(0.0.0.0:3306)/testdb",
+						"mysql://root:rootpas@(0.0.0.0:3306)/testdb",
+					).Times(1).Return(maria, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:  t.Context(),
+				name: "maria",
+				dsn:  "mysql://root:rootpas@(0.0.0.0:3306)/testdb",
+			},
+			want: &mariaDB{mySQL: &mySQL{
+				schema: "testdb",
+				db:     maria,
+			}},
+		},
+		{
+			name: "Should create CockroachDB connection",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"crdb",
+						postgresqlDB,
+						"postgres://localhost:26257/testdb?sslmode=disable",
+						"postgres://localhost:26257/testdb?sslmode=disable",
+					).Times(1).Return(cockroach, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:  t.Context(),
+				name: "crdb",
+				dsn:  "postgres://localhost:26257/testdb?sslmode=disable",
+			},
+			want: &cockroachDB{postgreSQL: &postgreSQL{
+				schema: "testdb",
+				db:     cockroach,
+				cancel: cockroachCancel,
+			}},
+		},
 		{
 			name: "Should create SQLite connection",
 			fields: fields{
@@ -225,6 +328,7 @@ func TestFactory_Create(t *testing.T) {
 						"lite",
 						sqliteDB,
 						"file.db",
+						"file.db",
 					).Times(1).Return(&sqlx.DB{}, nil)
 					return p
 				},
@@ -239,16 +343,130 @@ func TestFactory_Create(t *testing.T) {
 				db:     &sqlx.DB{},
 			},
 		},
+		{
+			name: "Should create PostgreSQL connection from postgresql scheme keeping dsn as written",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"pg",
+						postgresqlDB,
+						"postgresql://localhost:5432/testdb?connect_timeout=5",
+						"postgresql://localhost:5432/testdb?connect_timeout=5",
+					).Times(1).Return(pg, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:  t.Context(),
+				name: "pg",
+				dsn:  "postgresql://localhost:5432/testdb?connect_timeout=5",
+			},
+			want: &postgreSQL{
+				schema: "testdb",
+				db:     pg,
+			},
+		},
+		{
+			name: "Should create MySQL connection when host ends with .db",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"mysql",
+						mysqlDB,
+						"root:rootpas@tcp(mysql.db:3306)/testdb",
+						"root:rootpas@tcp(mysql.db:3306)/testdb",
+					).Times(1).Return(my, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:  t.Context(),
+				name: "mysql",
+				dsn:  "root:rootpas@tcp(mysql.db:3306)/testdb",
+			},
+			want: &mySQL{
+				schema: "testdb",
+				db:     my,
+			},
+		},
+		{
+			name: "Should get connection by the dsn it is saved with",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"lite",
+						sqliteDB,
+						"sqlite://file.db",
+						"file.db",
+					).Times(1).Return(&sqlx.DB{}, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:  t.Context(),
+				name: "lite",
+				dsn:  "sqlite://file.db",
+			},
+			want: &sqlite{
+				dbPath: "file.db",
+				db:     &sqlx.DB{},
+			},
+		},
+		{
+			name: "Should use driver over detection",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					p := mocks.NewMockPool(ctrl)
+					p.EXPECT().Get(
+						gomock.Any(),
+						"lite",
+						sqliteDB,
+						"/var/lib/app/data",
+						"/var/lib/app/data",
+					).Times(1).Return(&sqlx.DB{}, nil)
+					return p
+				},
+			},
+			args: args{
+				ctx:    t.Context(),
+				name:   "lite",
+				driver: "sqlite",
+				dsn:    "/var/lib/app/data",
+			},
+			want: &sqlite{
+				dbPath: "/var/lib/app/data",
+				db:     &sqlx.DB{},
+			},
+		},
+		{
+			name: "Should return an error when driver is unknown",
+			fields: fields{
+				pool: func(ctrl *gomock.Controller) Pool {
+					return mocks.NewMockPool(ctrl)
+				},
+			},
+			args: args{
+				ctx:    t.Context(),
+				name:   "name",
+				driver: "oracle",
+				dsn:    "file.db",
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
-			f := &Factory{
-				pool: tt.fields.pool(gomock.NewController(t)),
-			}
+			f := NewFactory(tt.fields.pool(gomock.NewController(t)))
 
-			got, err := f.Create(tt.args.ctx, tt.args.name, tt.args.dsn)
+			got, err := f.Create(tt.args.ctx, tt.args.name, tt.args.driver, tt.args.dsn)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -0,0 +1,386 @@
+package engine
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const (
+	// filesSeparator separates the files of a DSN.
+	filesSeparator = ","
+	// filesSampleRows is how many rows column types are inferred from.
+	filesSampleRows = 1000
+)
+
+var (
+	filesDelimiters = map[string]rune{".csv": ',', ".tsv": '\t'}
+	filesJSON       = []string{".json", ".jsonl", ".ndjson"}
+)
+
+// filesEngine loads CSV, TSV and JSON lines files into an in-memory
+// SQLite database, a table per file named after it, and explores it as
+// any other SQLite database. The DSN lists the files, or glob patterns
+// matching them, separated by commas, optionally prefixed with the
+// file:// scheme.
+type filesEngine struct{}
+
+func (filesEngine) Driver() string {
+	return filesDB
+}
+
+func (filesEngine) Schemes() []string {
+	return []string{"file"}
+}
+
+// Detect tells whether every path of the DSN has an extension of a flat
+// file.
+func (filesEngine) Detect(dsn string) bool {
+	if strings.Contains(dsn, "@") {
+		return false
+	}
+
+	for _, path := range strings.Split(dsn, filesSeparator) {
+		if !isFlatFile(path) {
+			return false
+		}
+	}
+	return true
+}
+
+func isFlatFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(strings.TrimSpace(path)))
+	_, delimited := filesDelimiters[ext]
+	return delimited || slices.Contains(filesJSON, ext)
+}
+
+func (filesEngine) Parse(dsn string) (Target, error) {
+	var files []string
+	for _, pattern := range strings.Split(trimScheme(dsn), filesSeparator) {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return Target{}, fmt.Errorf("%w: pattern %q: %w", errs.ErrValidation, pattern, err)
+		}
+		if len(matches) == 0 {
+			return Target{}, fmt.Errorf("%w: no files match %q", errs.ErrValidation, pattern)
+		}
+
+		for _, file := range matches {
+			if !isFlatFile(file) {
+				return Target{}, fmt.Errorf("%w: %q is not a csv, tsv or json file", errs.ErrValidation, file)
+			}
+			if !slices.Contains(files, file) {
+				files = append(files, file)
+			}
+		}
+	}
+
+	if len(files) == 0 {
+		return Target{}, fmt.Errorf("%w: file is required", errs.ErrValidation)
+	}
+
+	tables := make(map[string]string, len(files))
+	for _, file := range files {
+		name := fileTable(file)
+		if other, ok := tables[name]; ok {
+			return Target{}, fmt.Errorf(
+				"%w: %q and %q would both be loaded as %q",
+				errs.ErrValidation, other, file, name,
+			)
+		}
+		tables[name] = file
+	}
+
+	// The database is named after the files, so contexts of other files
+	// get one of their own. The pool keeps a single connection to it
+	// open, as it is gone once the last one closes.
+	h := fnv.New64a()
+	h.Write([]byte(strings.Join(files, filesSeparator)))
+	dsn = fmt.Sprintf("file:files-%x?mode=memory&cache=shared", h.Sum64())
+
+	return Target{DSN: dsn, Files: files}, nil
+}
+
+func fileTable(file string) string {
+	return strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+}
+
+// Explorer loads the files, replacing the tables loaded by an earlier
+// connect so changes to the files show up.
+func (filesEngine) Explorer(ctx context.Context, db *sqlx.DB, target Target) (Explorer, error) {
+	for _, file := range target.Files {
+		if err := loadFile(ctx, db, file); err != nil {
+			return nil, fmt.Errorf("load %q: %w", file, err)
+		}
+	}
+
+	return &sqlite{db: db, dbPath: target.DSN}, nil
+}
+
+func loadFile(ctx context.Context, db *sqlx.DB, file string) error {
+	f, err := os.Open(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var columns []string
+	var records [][]string
+	if delimiter, ok := filesDelimiters[strings.ToLower(filepath.Ext(file))]; ok {
+		columns, records, err = readDelimited(f, delimiter)
+	} else {
+		columns, records, err = readJSON(f)
+	}
+	if err != nil {
+		return err
+	}
+
+	return loadTable(ctx, db, fileTable(file), uniqueColumns(columns), records)
+}
+
+// readDelimited reads a CSV or TSV file, whose first record names the
+// columns.
+func readDelimited(r io.Reader, delimiter rune) ([]string, [][]string, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, nil, fmt.Errorf("read records: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil, fmt.Errorf("%w: file has no header", errs.ErrValidation)
+	}
+
+	return records[0], records[1:], nil
+}
+
+// readJSON reads a file of JSON objects, either one per line or in an
+// array. Columns are the keys of all the objects, sorted, as the order
+// of keys is lost when decoding. Nested values are kept as JSON.
+func readJSON(r io.Reader) ([]string, [][]string, error) {
+	br := bufio.NewReader(r)
+	first, _ := peekNonSpace(br)
+
+	decoder := json.NewDecoder(br)
+	decoder.UseNumber()
+
+	var objects []map[string]any
+	if first == '[' {
+		if err := decoder.Decode(&objects); err != nil {
+			return nil, nil, fmt.Errorf("decode objects: %w", err)
+		}
+	} else {
+		for {
+			var object map[string]any
+			if err := decoder.Decode(&object); err == io.EOF {
+				break
+			} else if err != nil {
+				return nil, nil, fmt.Errorf("decode object %d: %w", len(objects)+1, err)
+			}
+			objects = append(objects, object)
+		}
+	}
+
+	var columns []string
+	for _, object := range objects {
+		for key := range object {
+			if !slices.Contains(columns, key) {
+				columns = append(columns, key)
+			}
+		}
+	}
+	slices.Sort(columns)
+
+	records := make([][]string, 0, len(objects))
+	for _, object := range objects {
+		record := make([]string, len(columns))
+		for i, column := range columns {
+			record[i] = jsonValue(object[column])
+		}
+		records = append(records, record)
+	}
+
+	return columns, records, nil
+}
+
+func peekNonSpace(br *bufio.Reader) (byte, error) {
+	for {
+		b, err := br.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !strings.ContainsRune(" \t\r\n", rune(b[0])) {
+			return b[0], nil
+		}
+		if _, err := br.ReadByte(); err != nil {
+			return 0, err
+		}
+	}
+}
+
+func jsonValue(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	default:
+		nested, _ := json.Marshal(v)
+		return string(nested)
+	}
+}
+
+// uniqueColumns names columns left unnamed after their position and
+// numbers the ones named the same.
+func uniqueColumns(columns []string) []string {
+	unique := make([]string, 0, len(columns))
+	for i, c := range columns {
+		c = strings.TrimSpace(c)
+		if c == "" {
+			c = fmt.Sprintf("column_%d", i+1)
+		}
+
+		name := c
+		for n := 2; slices.Contains(unique, name); n++ {
+			name = fmt.Sprintf("%s_%d", c, n)
+		}
+		unique = append(unique, name)
+	}
+	return unique
+}
+
+// columnType is the SQLite type of a column of a flat file.
+type columnType string
+
+const (
+	integerColumn columnType = "INTEGER"
+	realColumn    columnType = "REAL"
+	textColumn    columnType = "TEXT"
+)
+
+// inferTypes picks the narrowest type fitting the values of each column
+// in the first records. Empty values fit any type, as they are loaded as
+// NULL, and columns with no other values are text.
+func inferTypes(columns int, records [][]string) []columnType {
+	types := make([]columnType, columns)
+	for _, record := range records[:min(len(records), filesSampleRows)] {
+		for i := range types {
+			if i < len(record) && record[i] != "" {
+				types[i] = widen(types[i], record[i])
+			}
+		}
+	}
+
+	for i, t := range types {
+		if t == "" {
+			types[i] = textColumn
+		}
+	}
+	return types
+}
+
+// widen returns the narrowest type fitting both the values of t and v.
+func widen(t columnType, v string) columnType {
+	if t == "" || t == integerColumn {
+		if _, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return integerColumn
+		}
+	}
+	if t != textColumn {
+		if _, err := strconv.ParseFloat(v, 64); err == nil {
+			return realColumn
+		}
+	}
+	return textColumn
+}
+
+// value converts a field to the type of its column. Fields past the
+// sample which don't fit are stored as text, which SQLite allows.
+func (t columnType) value(field string) any {
+	if field == "" {
+		return nil
+	}
+
+	switch t {
+	case integerColumn:
+		if v, err := strconv.ParseInt(field, 10, 64); err == nil {
+			return v
+		}
+	case realColumn:
+		if v, err := strconv.ParseFloat(field, 64); err == nil {
+			return v
+		}
+	}
+	return field
+}
+
+func loadTable(ctx context.Context, db *sqlx.DB, table string, columns []string, records [][]string) error {
+	types := inferTypes(len(columns), records)
+
+	definitions := make([]string, 0, len(columns))
+	for i, c := range columns {
+		definitions = append(definitions, fmt.Sprintf("%q %s", c, types[i]))
+	}
+
+	tx, err := db.BeginTxx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf("DROP TABLE IF EXISTS %q", table)); err != nil {
+		return fmt.Errorf("drop table: %w", err)
+	}
+
+	create := fmt.Sprintf("CREATE TABLE %q (%s)", table, strings.Join(definitions, ", "))
+	if _, err := tx.ExecContext(ctx, create); err != nil {
+		return fmt.Errorf("create table: %w", err)
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(columns)), ", ")
+	insert, err := tx.PrepareContext(ctx, fmt.Sprintf("INSERT INTO %q VALUES (%s)", table, placeholders))
+	if err != nil {
+		return fmt.Errorf("prepare insert: %w", err)
+	}
+	defer insert.Close()
+
+	args := make([]any, len(columns))
+	for n, record := range records {
+		for i, t := range types {
+			args[i] = nil
+			if i < len(record) {
+				args[i] = t.value(record[i])
+			}
+		}
+		if _, err := insert.ExecContext(ctx, args...); err != nil {
+			return fmt.Errorf("insert record %d: %w", n+1, err)
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,184 @@
+package engine
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func Test_filesEngine_Parse(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.csv")
+	orders := filepath.Join(dir, "orders.tsv")
+	events := filepath.Join(dir, "events.jsonl")
+	writeFile(t, users, "id\n")
+	writeFile(t, orders, "id\n")
+	writeFile(t, events, "{}\n")
+	writeFile(t, filepath.Join(dir, "notes.txt"), "")
+	writeFile(t, filepath.Join(dir, "nested", "users.csv"), "id\n")
+
+	tests := []struct {
+		name      string
+		dsn       string
+		wantFiles []string
+		wantErr   bool
+	}{
+		{
+			name:      "Should parse file",
+			dsn:       "file://" + users,
+			wantFiles: []string{users},
+		},
+		{
+			name:      "Should parse several files",
+			dsn:       users + ", " + events,
+			wantFiles: []string{users, events},
+		},
+		{
+			name:      "Should expand patterns",
+			dsn:       "file://" + filepath.Join(dir, "*.?sv"),
+			wantFiles: []string{orders, users},
+		},
+		{
+			name:    "Should return an error when file is missing",
+			dsn:     "file://" + filepath.Join(dir, "missing.csv"),
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error when file is not flat",
+			dsn:     "file://" + filepath.Join(dir, "*"),
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error when files would load into same table",
+			dsn:     "file://" + users + "," + filepath.Join(dir, "nested", "users.csv"),
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error when dsn has no files",
+			dsn:     "file://",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := filesEngine{}.Parse(tt.dsn)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantFiles, got.Files)
+			require.Contains(t, got.DSN, "mode=memory&cache=shared")
+
+			again, err := filesEngine{}.Parse(tt.dsn)
+			require.NoError(t, err)
+			require.Equal(t, got.DSN, again.DSN, "Same files must share the database")
+		})
+	}
+}
+
+func Test_inferTypes(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		records [][]string
+		want    []columnType
+	}{
+		{
+			name:    "Should infer integer, real and text",
+			records: [][]string{{"1", "1.5", "a"}, {"2", "2", "3"}},
+			want:    []columnType{integerColumn, realColumn, textColumn},
+		},
+		{
+			name:    "Should widen integer to real",
+			records: [][]string{{"1"}, {"2.5"}},
+			want:    []columnType{realColumn},
+		},
+		{
+			name:    "Should skip empty values",
+			records: [][]string{{""}, {"7"}, {}},
+			want:    []columnType{integerColumn},
+		},
+		{
+			name:    "Should make empty column text",
+			records: [][]string{{""}},
+			want:    []columnType{textColumn},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, inferTypes(len(tt.want), tt.records))
+		})
+	}
+}
+
+func Test_filesEngine_Explorer(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	dir := t.TempDir()
+	users := filepath.Join(dir, "users.csv")
+	writeFile(t, users, "id,name,score,\n1,John,1.5,x\n2,,2,y\n")
+	writeFile(t, filepath.Join(dir, "orders.tsv"), "id\tuser_id\n10\t1\n")
+	writeFile(t, filepath.Join(dir, "events.jsonl"), `{"id": 1, "kind": "login"}
+{"id": 2, "meta": {"ip": "::1"}, "ok": true}
+`)
+	writeFile(t, filepath.Join(dir, "tags.json"), `[{"tag": "a"}, {"tag": "b"}]`)
+
+	target, err := filesEngine{}.Parse("file://" + filepath.Join(dir, "*"))
+	require.NoError(t, err)
+
+	db := sqlx.MustOpen(sqliteDB, target.DSN)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+
+	e, err := filesEngine{}.Explorer(t.Context(), db, target)
+	require.NoError(t, err)
+
+	tables, err := e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.ElementsMatch(t, []Table{
+		{Name: "users", Schema: "main"},
+		{Name: "orders", Schema: "main"},
+		{Name: "events", Schema: "main"},
+		{Name: "tags", Schema: "main"},
+	}, tables)
+
+	var types []string
+	require.NoError(t, db.SelectContext(t.Context(), &types, "SELECT type FROM pragma_table_info('users')"))
+	require.Equal(t, []string{"INTEGER", "TEXT", "REAL", "TEXT"}, types)
+
+	rows, cols, err := e.Query(t.Context(), "SELECT * FROM users WHERE name IS NULL")
+	require.NoError(t, err)
+	require.Equal(t, []Column{"id", "name", "score", "column_4"}, cols)
+	require.Equal(t, []Row{{"2", "<nil>", "2", "y"}}, rows)
+
+	rows, cols, err = e.GetRows(t.Context(), "events")
+	require.NoError(t, err)
+	require.Equal(t, []Column{"id", "kind", "meta", "ok"}, cols)
+	require.Equal(t, []Row{
+		{"1", "login", "<nil>", "<nil>"},
+		{"2", "<nil>", `{"ip":"::1"}`, "true"},
+	}, rows)
+
+	// Connecting again reloads the files.
+	writeFile(t, users, "id\n1\n")
+	e, err = filesEngine{}.Explorer(t.Context(), db, target)
+	require.NoError(t, err)
+
+	rows, _, err = e.GetRows(t.Context(), "users")
+	require.NoError(t, err)
+	require.Equal(t, []Row{{"1"}}, rows)
+}
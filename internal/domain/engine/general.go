@@ -1,9 +1,104 @@
 package engine
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
+	"log/slog"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
 )
 
+// ErrReadOnly is returned by read-only explorers for statements which
+// write, before they are sent to the database.
+var ErrReadOnly = fmt.Errorf("%w: context is read-only", errs.ErrValidation)
+
+// queryer is implemented by *sqlx.DB, *sqlx.Conn and *sqlx.Tx.
+type queryer interface {
+	QueryxContext(ctx context.Context, query string, args ...any) (*sqlx.Rows, error)
+	QueryRowxContext(ctx context.Context, query string, args ...any) *sqlx.Row
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// txBeginner is implemented by both *sqlx.DB and *sqlx.Conn.
+type txBeginner interface {
+	queryer
+	BeginTxx(ctx context.Context, opts *sql.TxOptions) (*sqlx.Tx, error)
+}
+
+func checkReadOnly(readOnly bool, query string) error {
+	if readOnly && !IsReadOnly(query) {
+		return ErrReadOnly
+	}
+	return nil
+}
+
+// within calls fn with db, or with a read-only transaction on it when
+// readOnly is set. The transaction is rolled back, as it has nothing to
+// commit.
+func within(ctx context.Context, db txBeginner, readOnly bool, fn func(q queryer) error) error {
+	if !readOnly {
+		return fn(db)
+	}
+	return rolledBack(ctx, db, readOnly, fn)
+}
+
+// rolledBack calls fn with a transaction on db which is rolled back
+// afterwards, so nothing the statements change is kept.
+func rolledBack(ctx context.Context, db txBeginner, readOnly bool, fn func(q queryer) error) error {
+	tx, err := db.BeginTxx(ctx, &sql.TxOptions{ReadOnly: readOnly})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	return fn(tx)
+}
+
+func execute(ctx context.Context, db queryer, query string) (int64, error) {
+	res, err := db.ExecContext(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("execute command %q: %w", query, err)
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("get affected rows: %w", err)
+	}
+
+	return affected, nil
+}
+
+func queryRows(ctx context.Context, db queryer, query string, args ...any) ([]Row, []Column, error) {
+	entries, err := db.QueryxContext(ctx, query, args...)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer entries.Close()
+
+	cols, err := entries.Columns()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([][]any, 0)
+	for entries.Next() {
+		rowCols, err := entries.SliceScan()
+		if err != nil {
+			slog.Error("Got err", slog.Any("err", err))
+		}
+		rows = append(rows, rowCols)
+	}
+
+	if err := entries.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return convertFromBinary(rows), cols, nil
+}
+
 func convertFromBinary(entries [][]any) []Row {
 	rows := make([]Row, 0)
 
@@ -0,0 +1,141 @@
+package engine
+
+import (
+	"slices"
+	"time"
+)
+
+// Lock is a lock a session waits for, or holds while another session
+// waits for it.
+type Lock struct {
+	Session int64
+	User    string
+	Query   string
+	// Duration is how long the query of the session has been running.
+	Duration time.Duration
+	Mode     string
+	Object   string
+	Granted  bool
+	// BlockedBy holds ids of the sessions the lock is waited for, set
+	// only when it is not granted.
+	BlockedBy []int64
+}
+
+// LockNode is a session of a blocking tree, with the sessions waiting
+// for it as children.
+type LockNode struct {
+	Session  int64
+	User     string
+	Query    string
+	Duration time.Duration
+	// Locks are the locks the session waits for, followed by the ones it
+	// holds.
+	Locks    []Lock
+	Children []LockNode
+}
+
+// Waiting tells whether the session waits for a lock.
+func (n LockNode) Waiting() bool {
+	return slices.ContainsFunc(n.Locks, func(l Lock) bool { return !l.Granted })
+}
+
+// BuildLockTree groups the locks by session and nests each session under
+// the ones it waits for, so the roots are the sessions blocking others
+// without waiting themselves. A session waiting for several others is
+// shown under each of them. Sessions waiting in a cycle have no such
+// root, so the first of them becomes one.
+func BuildLockTree(locks []Lock) []LockNode {
+	var (
+		order    []int64
+		sessions = make(map[int64]LockNode)
+		waiters  = make(map[int64][]int64)
+		blocked  = make(map[int64]bool)
+	)
+
+	for _, l := range locks {
+		n, ok := sessions[l.Session]
+		if !ok {
+			order = append(order, l.Session)
+			n = LockNode{Session: l.Session, User: l.User, Query: l.Query, Duration: l.Duration}
+		}
+		n.Locks = append(n.Locks, l)
+		sessions[l.Session] = n
+
+		for _, b := range l.BlockedBy {
+			if b != l.Session && !slices.Contains(waiters[b], l.Session) {
+				waiters[b] = append(waiters[b], l.Session)
+				blocked[l.Session] = true
+			}
+		}
+	}
+
+	// A blocker may hold no lock we were told about, it is still shown
+	// as the root of its waiters.
+	for _, l := range locks {
+		for _, b := range l.BlockedBy {
+			if _, ok := sessions[b]; !ok {
+				order = append(order, b)
+				sessions[b] = LockNode{Session: b}
+			}
+		}
+	}
+
+	for id, n := range sessions {
+		slices.SortStableFunc(n.Locks, func(a, b Lock) int {
+			return boolOrder(a.Granted) - boolOrder(b.Granted)
+		})
+		sessions[id] = n
+	}
+
+	var build func(id int64, path []int64) LockNode
+	build = func(id int64, path []int64) LockNode {
+		n := sessions[id]
+		path = append(path, id)
+
+		n.Children = nil
+		for _, w := range waiters[id] {
+			if !slices.Contains(path, w) {
+				n.Children = append(n.Children, build(w, path))
+			}
+		}
+		return n
+	}
+
+	var (
+		roots   []LockNode
+		visited = make(map[int64]bool)
+	)
+
+	var mark func(n LockNode)
+	mark = func(n LockNode) {
+		visited[n.Session] = true
+		for _, c := range n.Children {
+			mark(c)
+		}
+	}
+
+	for _, id := range order {
+		if !blocked[id] {
+			root := build(id, nil)
+			mark(root)
+			roots = append(roots, root)
+		}
+	}
+
+	for _, id := range order {
+		if !visited[id] {
+			root := build(id, nil)
+			mark(root)
+			roots = append(roots, root)
+		}
+	}
+
+	return roots
+}
+
+func boolOrder(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,109 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestBuildLockTree(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	type node struct {
+		session  int64
+		children []node
+	}
+
+	var shape func(nodes []LockNode) []node
+	shape = func(nodes []LockNode) []node {
+		var out []node
+		for _, n := range nodes {
+			out = append(out, node{session: n.Session, children: shape(n.Children)})
+		}
+		return out
+	}
+
+	tests := []struct {
+		name  string
+		locks []Lock
+		want  []node
+	}{
+		{
+			name: "Should nest waiters under the holder",
+			locks: []Lock{
+				{Session: 3, BlockedBy: []int64{2}},
+				{Session: 1, Granted: true},
+				{Session: 2, Granted: true},
+				{Session: 2, BlockedBy: []int64{1}},
+			},
+			want: []node{
+				{session: 1, children: []node{
+					{session: 2, children: []node{{session: 3}}},
+				}},
+			},
+		},
+		{
+			name: "Should show waiter under each of its blockers",
+			locks: []Lock{
+				{Session: 1, Granted: true},
+				{Session: 2, Granted: true},
+				{Session: 3, BlockedBy: []int64{1, 2}},
+			},
+			want: []node{
+				{session: 1, children: []node{{session: 3}}},
+				{session: 2, children: []node{{session: 3}}},
+			},
+		},
+		{
+			name: "Should add blocker holding no listed lock",
+			locks: []Lock{
+				{Session: 5, BlockedBy: []int64{9}},
+			},
+			want: []node{
+				{session: 9, children: []node{{session: 5}}},
+			},
+		},
+		{
+			name: "Should break deadlock at first session",
+			locks: []Lock{
+				{Session: 1, BlockedBy: []int64{2}},
+				{Session: 2, BlockedBy: []int64{1}},
+			},
+			want: []node{
+				{session: 1, children: []node{{session: 2}}},
+			},
+		},
+		{
+			name: "Should be empty without locks",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, shape(BuildLockTree(tt.locks)))
+		})
+	}
+}
+
+func TestBuildLockTree_Locks(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	tree := BuildLockTree([]Lock{
+		{Session: 2, Mode: "AccessShareLock", Granted: true},
+		{Session: 2, Mode: "AccessExclusiveLock", BlockedBy: []int64{1}},
+		{Session: 1, Mode: "RowExclusiveLock", Granted: true, Query: "UPDATE users SET name = 'a'"},
+	})
+
+	require.Len(t, tree, 1)
+	require.Equal(t, "UPDATE users SET name = 'a'", tree[0].Query)
+	require.False(t, tree[0].Waiting())
+
+	waiter := tree[0].Children[0]
+	require.True(t, waiter.Waiting())
+	require.Equal(t, "AccessExclusiveLock", waiter.Locks[0].Mode, "awaited lock goes first")
+}
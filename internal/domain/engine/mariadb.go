@@ -0,0 +1,81 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// mariaDB speaks the protocol of MySQL, but leaves performance_schema off
+// by default, so locks are read from the InnoDB tables of
+// information_schema instead.
+type mariaDB struct {
+	*mySQL
+}
+
+// isMariaDB tells whether VERSION() was returned by MariaDB, like
+// "11.4.2-MariaDB-ubu2404".
+func isMariaDB(version string) bool {
+	return strings.Contains(strings.ToLower(version), "mariadb")
+}
+
+// mariaDBVersion returns the release of the server, without the build
+// suffix or the prefix old servers put for replication with MySQL.
+func mariaDBVersion(version string) string {
+	version = strings.TrimPrefix(version, "5.5.5-")
+	release, _, _ := strings.Cut(version, "-")
+	return release
+}
+
+const mariaDBLockWaitsQuery = `
+	SELECT r.trx_mysql_thread_id AS waiting, b.trx_mysql_thread_id AS blocking
+	FROM information_schema.INNODB_LOCK_WAITS w
+	JOIN information_schema.INNODB_TRX r ON r.trx_id = w.requesting_trx_id
+	JOIN information_schema.INNODB_TRX b ON b.trx_id = w.blocking_trx_id
+`
+
+func (e *mariaDB) GetActivity(ctx context.Context) ([]Session, error) {
+	return e.activity(ctx, mariaDBLockWaitsQuery)
+}
+
+// GetLocks reads row locks of InnoDB, which lists only the locks sessions
+// wait for or which hold others back. Metadata locks are left out, as
+// MariaDB shows them only with the METADATA_LOCK_INFO plugin.
+func (e *mariaDB) GetLocks(ctx context.Context) ([]Lock, error) {
+	const dataQuery = `
+		SELECT t.trx_mysql_thread_id AS session,
+			COALESCE(p.USER, '') AS user,
+			COALESCE(p.INFO, '') AS query,
+			COALESCE(p.TIME, 0) AS seconds,
+			l.lock_mode AS mode,
+			CONCAT(l.lock_table, COALESCE(CONCAT(' (', l.lock_index, ')'), '')) AS object,
+			l.lock_id NOT IN (SELECT requested_lock_id FROM information_schema.INNODB_LOCK_WAITS) AS granted,
+			l.lock_id AS lock_id
+		FROM information_schema.INNODB_LOCKS l
+		JOIN information_schema.INNODB_TRX t ON t.trx_id = l.lock_trx_id
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.trx_mysql_thread_id
+	`
+
+	const blockersQuery = `
+		SELECT w.requested_lock_id AS lock_id, t.trx_mysql_thread_id AS blocking
+		FROM information_schema.INNODB_LOCK_WAITS w
+		JOIN information_schema.INNODB_TRX t ON t.trx_id = w.blocking_trx_id
+	`
+
+	return e.locks(ctx, mySQLLockQueries{
+		data:     dataQuery,
+		blockers: blockersQuery,
+	})
+}
+
+func (e *mariaDB) ReadOnly() Explorer {
+	return &mariaDB{mySQL: e.mySQL.ReadOnly().(*mySQL)}
+}
+
+func (e *mariaDB) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SELECT VERSION()"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "MariaDB", Version: mariaDBVersion(version)}, nil
+}
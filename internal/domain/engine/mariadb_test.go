@@ -0,0 +1,157 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/mysql"
+	"github.com/testcontainers/testcontainers-go/wait"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+var mariaDBTestDSN string
+
+func Test_mariaDBVersion(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name        string
+		version     string
+		wantMariaDB bool
+		want        string
+	}{
+		{
+			name:        "Should detect MariaDB",
+			version:     "11.4.2-MariaDB-ubu2404",
+			wantMariaDB: true,
+			want:        "11.4.2",
+		},
+		{
+			name:        "Should trim replication prefix",
+			version:     "5.5.5-10.6.16-MariaDB-1:10.6.16+maria~ubu2004-log",
+			wantMariaDB: true,
+			want:        "10.6.16",
+		},
+		{
+			name:        "Should not detect MySQL",
+			version:     "8.0.36",
+			wantMariaDB: false,
+			want:        "8.0.36",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.wantMariaDB, isMariaDB(tt.version))
+			require.Equal(t, tt.want, mariaDBVersion(tt.version))
+		})
+	}
+}
+
+func Test_mariaDB_ServerInfo(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mariaDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e, err := mySQLEngine{}.Explorer(t.Context(), db, Target{Database: dbName})
+	require.NoError(t, err)
+	require.IsType(t, &mariaDB{}, e)
+
+	got, err := e.ServerInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "MariaDB", got.Engine)
+	require.NotContains(t, got.Version, "MariaDB")
+}
+
+func Test_mariaDB_GetLocks(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mariaDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mariaDB{mySQL: &mySQL{
+		db:     db,
+		schema: dbName,
+	}}
+
+	tx, err := db.BeginTxx(t.Context(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tx.Rollback() })
+
+	_, err = tx.ExecContext(t.Context(), "SELECT * FROM users WHERE id = 1 FOR UPDATE")
+	require.NoError(t, err)
+
+	const update = "UPDATE users SET name = 'John' WHERE id = 1"
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	go func() {
+		_, _ = db.ExecContext(ctx, update)
+	}()
+
+	var tree []LockNode
+	require.Eventually(t, func() bool {
+		locks, err := e.GetLocks(t.Context())
+		if err != nil {
+			return false
+		}
+		tree = BuildLockTree(locks)
+		return len(tree) == 1 && len(tree[0].Children) == 1
+	}, time.Second*5, time.Millisecond*100)
+
+	require.False(t, tree[0].Waiting())
+	require.True(t, tree[0].Children[0].Waiting())
+	require.Equal(t, update, tree[0].Children[0].Query)
+
+	sessions, err := e.GetActivity(t.Context())
+	require.NoError(t, err)
+
+	var blocked bool
+	for _, s := range sessions {
+		if s.Query == update {
+			blocked = len(s.BlockedBy) == 1
+		}
+	}
+	require.True(t, blocked, "Blocked session not found")
+}
+
+func Test_mariaDB_GetTables(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mariaDBTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mariaDB{mySQL: &mySQL{
+		db:     db,
+		schema: dbName,
+	}}
+
+	got, err := e.GetTables(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, []Table{{Name: tableName, Schema: dbName}}, got)
+}
+
+func newTestMariaDB(ctx context.Context) (*mysql.MySQLContainer, error) {
+	container, err := mysql.Run(ctx,
+		"mariadb:11.4",
+		mysql.WithDatabase("test"),
+		mysql.WithUsername("root"),
+		mysql.WithPassword("password"),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("port: 3306  mariadb.org binary distribution"),
+		),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("start mariadb container: %w", err)
+	}
+
+	mariaDBTestDSN, err = container.ConnectionString(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get mariadb dsn: %w", err)
+	}
+
+	return container, nil
+}
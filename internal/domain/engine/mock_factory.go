@@ -40,20 +40,65 @@ func (m *MockExplorer) EXPECT() *MockExplorerMockRecorder {
 	return m.recorder
 }
 
-// Execute mocks base method.
-func (m *MockExplorer) Execute(ctx context.Context, query string) error {
+// CancelQuery mocks base method.
+func (m *MockExplorer) CancelQuery(ctx context.Context, id int64) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Execute", ctx, query)
+	ret := m.ctrl.Call(m, "CancelQuery", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// CancelQuery indicates an expected call of CancelQuery.
+func (mr *MockExplorerMockRecorder) CancelQuery(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelQuery", reflect.TypeOf((*MockExplorer)(nil).CancelQuery), ctx, id)
+}
+
+// Execute mocks base method.
+func (m *MockExplorer) Execute(ctx context.Context, query string) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Execute", ctx, query)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Execute indicates an expected call of Execute.
 func (mr *MockExplorerMockRecorder) Execute(ctx, query any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Execute", reflect.TypeOf((*MockExplorer)(nil).Execute), ctx, query)
 }
 
+// Explain mocks base method.
+func (m *MockExplorer) Explain(ctx context.Context, query string, analyze bool) ([]PlanNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Explain", ctx, query, analyze)
+	ret0, _ := ret[0].([]PlanNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Explain indicates an expected call of Explain.
+func (mr *MockExplorerMockRecorder) Explain(ctx, query, analyze any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Explain", reflect.TypeOf((*MockExplorer)(nil).Explain), ctx, query, analyze)
+}
+
+// GetActivity mocks base method.
+func (m *MockExplorer) GetActivity(ctx context.Context) ([]Session, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetActivity", ctx)
+	ret0, _ := ret[0].([]Session)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetActivity indicates an expected call of GetActivity.
+func (mr *MockExplorerMockRecorder) GetActivity(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetActivity", reflect.TypeOf((*MockExplorer)(nil).GetActivity), ctx)
+}
+
 // GetColumns mocks base method.
 func (m *MockExplorer) GetColumns(ctx context.Context, table string) ([][]string, []string, error) {
 	m.ctrl.T.Helper()
@@ -102,6 +147,36 @@ func (mr *MockExplorerMockRecorder) GetIndexes(ctx, table any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIndexes", reflect.TypeOf((*MockExplorer)(nil).GetIndexes), ctx, table)
 }
 
+// GetLocks mocks base method.
+func (m *MockExplorer) GetLocks(ctx context.Context) ([]Lock, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetLocks", ctx)
+	ret0, _ := ret[0].([]Lock)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetLocks indicates an expected call of GetLocks.
+func (mr *MockExplorerMockRecorder) GetLocks(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetLocks", reflect.TypeOf((*MockExplorer)(nil).GetLocks), ctx)
+}
+
+// GetPrimaryKey mocks base method.
+func (m *MockExplorer) GetPrimaryKey(ctx context.Context, table string) ([]Column, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetPrimaryKey", ctx, table)
+	ret0, _ := ret[0].([]Column)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetPrimaryKey indicates an expected call of GetPrimaryKey.
+func (mr *MockExplorerMockRecorder) GetPrimaryKey(ctx, table any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPrimaryKey", reflect.TypeOf((*MockExplorer)(nil).GetPrimaryKey), ctx, table)
+}
+
 // GetRows mocks base method.
 func (m *MockExplorer) GetRows(ctx context.Context, table string) ([][]string, []string, error) {
 	m.ctrl.T.Helper()
@@ -118,6 +193,21 @@ func (mr *MockExplorerMockRecorder) GetRows(ctx, table any) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetRows", reflect.TypeOf((*MockExplorer)(nil).GetRows), ctx, table)
 }
 
+// GetTableStats mocks base method.
+func (m *MockExplorer) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTableStats", ctx)
+	ret0, _ := ret[0].(map[string]TableStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTableStats indicates an expected call of GetTableStats.
+func (mr *MockExplorerMockRecorder) GetTableStats(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTableStats", reflect.TypeOf((*MockExplorer)(nil).GetTableStats), ctx)
+}
+
 // GetTables mocks base method.
 func (m *MockExplorer) GetTables(ctx context.Context) ([]Table, error) {
 	m.ctrl.T.Helper()
@@ -132,3 +222,62 @@ func (mr *MockExplorerMockRecorder) GetTables(ctx any) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTables", reflect.TypeOf((*MockExplorer)(nil).GetTables), ctx)
 }
+
+// Query mocks base method.
+func (m *MockExplorer) Query(ctx context.Context, query string) ([]Row, []Column, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Query", ctx, query)
+	ret0, _ := ret[0].([]Row)
+	ret1, _ := ret[1].([]Column)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Query indicates an expected call of Query.
+func (mr *MockExplorerMockRecorder) Query(ctx, query any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Query", reflect.TypeOf((*MockExplorer)(nil).Query), ctx, query)
+}
+
+// ReadOnly mocks base method.
+func (m *MockExplorer) ReadOnly() Explorer {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReadOnly")
+	ret0, _ := ret[0].(Explorer)
+	return ret0
+}
+
+// ReadOnly indicates an expected call of ReadOnly.
+func (mr *MockExplorerMockRecorder) ReadOnly() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReadOnly", reflect.TypeOf((*MockExplorer)(nil).ReadOnly))
+}
+
+// ServerInfo mocks base method.
+func (m *MockExplorer) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ServerInfo", ctx)
+	ret0, _ := ret[0].(ServerInfo)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ServerInfo indicates an expected call of ServerInfo.
+func (mr *MockExplorerMockRecorder) ServerInfo(ctx any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ServerInfo", reflect.TypeOf((*MockExplorer)(nil).ServerInfo), ctx)
+}
+
+// TerminateSession mocks base method.
+func (m *MockExplorer) TerminateSession(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TerminateSession", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// TerminateSession indicates an expected call of TerminateSession.
+func (mr *MockExplorerMockRecorder) TerminateSession(ctx, id any) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TerminateSession", reflect.TypeOf((*MockExplorer)(nil).TerminateSession), ctx, id)
+}
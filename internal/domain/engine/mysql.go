@@ -2,16 +2,64 @@ package engine
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
+	"time"
 
+	"github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 )
 
+// mySQLEngine connects to MySQL with the DSNs of go-sql-driver/mysql,
+// optionally prefixed with the mysql:// scheme.
+type mySQLEngine struct{}
+
+func (mySQLEngine) Driver() string {
+	return mysqlDB
+}
+
+func (mySQLEngine) Schemes() []string {
+	return []string{"mysql"}
+}
+
+func (mySQLEngine) Detect(dsn string) bool {
+	_, err := mysql.ParseDSN(dsn)
+	return err == nil
+}
+
+// Parse keeps the DSN as written, since it names the connection in the
+// config. The scheme is left for the connection to drop.
+func (mySQLEngine) Parse(dsn string) (Target, error) {
+	params, err := mysql.ParseDSN(trimScheme(dsn))
+	if err != nil {
+		return Target{}, err
+	}
+	return Target{DSN: dsn, Database: params.DBName}, nil
+}
+
+// Explorer asks the server for its version, as MariaDB keeps lock
+// information elsewhere than MySQL.
+func (mySQLEngine) Explorer(ctx context.Context, db *sqlx.DB, target Target) (Explorer, error) {
+	e := &mySQL{db: db, schema: target.Database}
+
+	var version string
+	if err := db.GetContext(ctx, &version, "SELECT VERSION()"); err != nil {
+		return nil, fmt.Errorf("get server version: %w", err)
+	}
+
+	if isMariaDB(version) {
+		return &mariaDB{mySQL: e}, nil
+	}
+	return e, nil
+}
+
 type mySQL struct {
-	db     *sqlx.DB
-	schema string
+	db       *sqlx.DB
+	schema   string
+	readOnly bool
 }
 
 type mySQLTable struct {
@@ -19,13 +67,323 @@ type mySQLTable struct {
 	Type string `db:"TABLE_TYPE"`
 }
 
-func (e *mySQL) Execute(ctx context.Context, query string) error {
-	if _, err := e.db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("execute command %q: %w", query, err)
+func (e *mySQL) Execute(ctx context.Context, query string) (int64, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return 0, err
+	}
+
+	var affected int64
+	err := mysqlCancel.run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return within(ctx, conn, e.readOnly, func(q queryer) error {
+			var err error
+			affected, err = execute(ctx, q, query)
+			return err
+		})
+	})
+	return affected, err
+}
+
+// Explain ignores analyze, as the JSON plan of MySQL has no timings.
+func (e *mySQL) Explain(ctx context.Context, query string, _ bool) ([]PlanNode, error) {
+	var raw []byte
+	err := mysqlCancel.run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return within(ctx, conn, e.readOnly, func(q queryer) error {
+			return q.QueryRowxContext(ctx, "EXPLAIN FORMAT=JSON "+query).Scan(&raw)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain query %q: %w", query, err)
+	}
+
+	return parseMySQLPlan(raw)
+}
+
+type mySQLSession struct {
+	ID       int64  `db:"ID"`
+	User     string `db:"USER"`
+	Database string `db:"DB"`
+	Client   string `db:"HOST"`
+	Command  string `db:"COMMAND"`
+	State    string `db:"STATE"`
+	Query    string `db:"INFO"`
+	Seconds  int64  `db:"TIME"`
+}
+
+type mySQLLockWait struct {
+	Waiting  int64 `db:"waiting"`
+	Blocking int64 `db:"blocking"`
+}
+
+// mySQLLockWaitsQuery finds who waits for whom in performance_schema.
+const mySQLLockWaitsQuery = `
+	SELECT rt.PROCESSLIST_ID AS waiting, bt.PROCESSLIST_ID AS blocking
+	FROM performance_schema.data_lock_waits w
+	JOIN performance_schema.threads rt ON rt.THREAD_ID = w.REQUESTING_THREAD_ID
+	JOIN performance_schema.threads bt ON bt.THREAD_ID = w.BLOCKING_THREAD_ID
+`
+
+// GetActivity reads the process list. Blocking sessions come from
+// performance_schema, which may be turned off, in which case they are
+// left out.
+func (e *mySQL) GetActivity(ctx context.Context) ([]Session, error) {
+	return e.activity(ctx, mySQLLockWaitsQuery)
+}
+
+// activity reads the process list, along with who waits for whom as
+// told by waitsQuery.
+func (e *mySQL) activity(ctx context.Context, waitsQuery string) ([]Session, error) {
+	const query = `
+		SELECT ID,
+			COALESCE(USER, '') AS USER,
+			COALESCE(DB, '') AS DB,
+			COALESCE(HOST, '') AS HOST,
+			COALESCE(COMMAND, '') AS COMMAND,
+			COALESCE(STATE, '') AS STATE,
+			COALESCE(INFO, '') AS INFO,
+			TIME
+		FROM information_schema.PROCESSLIST
+		WHERE ID <> CONNECTION_ID()
+	`
+
+	var sessions []mySQLSession
+	if err := e.db.SelectContext(ctx, &sessions, query); err != nil {
+		return nil, fmt.Errorf("get activity: %w", err)
+	}
+
+	var waits []mySQLLockWait
+	if err := e.db.SelectContext(ctx, &waits, waitsQuery); err != nil {
+		slog.Debug("Lock waits are not available", slog.Any("err", err))
+	}
+
+	blockedBy := make(map[int64][]int64, len(waits))
+	for _, w := range waits {
+		blockedBy[w.Waiting] = append(blockedBy[w.Waiting], w.Blocking)
+	}
+
+	result := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, Session{
+			ID:        s.ID,
+			User:      s.User,
+			Database:  s.Database,
+			Client:    s.Client,
+			State:     s.Command,
+			Query:     s.Query,
+			Duration:  time.Duration(s.Seconds) * time.Second,
+			Wait:      s.State,
+			BlockedBy: blockedBy[s.ID],
+		})
+	}
+
+	return result, nil
+}
+
+type mySQLLock struct {
+	Session int64  `db:"session"`
+	User    string `db:"user"`
+	Query   string `db:"query"`
+	Seconds int64  `db:"seconds"`
+	Mode    string `db:"mode"`
+	Object  string `db:"object"`
+	Granted bool   `db:"granted"`
+	LockID  string `db:"lock_id"`
+}
+
+type mySQLLockBlocker struct {
+	LockID   string `db:"lock_id"`
+	Blocking int64  `db:"blocking"`
+}
+
+// mySQLLockQueries read the locks of a server. The data query returns
+// mySQLLock rows, the blockers query mySQLLockBlocker rows, and the
+// metadata query, when set, mySQLLock rows of table metadata locks.
+type mySQLLockQueries struct {
+	data     string
+	blockers string
+	metadata string
+}
+
+// GetLocks reads row locks of InnoDB and table metadata locks, which
+// hold back DDL, from performance_schema.
+func (e *mySQL) GetLocks(ctx context.Context) ([]Lock, error) {
+	const dataQuery = `
+		SELECT t.PROCESSLIST_ID AS session,
+			COALESCE(p.USER, '') AS user,
+			COALESCE(p.INFO, '') AS query,
+			COALESCE(p.TIME, 0) AS seconds,
+			l.LOCK_MODE AS mode,
+			CONCAT(l.OBJECT_SCHEMA, '.', l.OBJECT_NAME, COALESCE(CONCAT(' (', l.INDEX_NAME, ')'), '')) AS object,
+			l.LOCK_STATUS = 'GRANTED' AS granted,
+			l.ENGINE_LOCK_ID AS lock_id
+		FROM performance_schema.data_locks l
+		JOIN performance_schema.threads t ON t.THREAD_ID = l.THREAD_ID
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.PROCESSLIST_ID
+		WHERE t.PROCESSLIST_ID IS NOT NULL AND (
+			l.LOCK_STATUS = 'WAITING' OR
+			l.ENGINE_LOCK_ID IN (SELECT BLOCKING_ENGINE_LOCK_ID FROM performance_schema.data_lock_waits)
+		)
+	`
+
+	const blockersQuery = `
+		SELECT w.REQUESTING_ENGINE_LOCK_ID AS lock_id, t.PROCESSLIST_ID AS blocking
+		FROM performance_schema.data_lock_waits w
+		JOIN performance_schema.threads t ON t.THREAD_ID = w.BLOCKING_THREAD_ID
+		WHERE t.PROCESSLIST_ID IS NOT NULL
+	`
+
+	const metadataQuery = `
+		SELECT t.PROCESSLIST_ID AS session,
+			COALESCE(p.USER, '') AS user,
+			COALESCE(p.INFO, '') AS query,
+			COALESCE(p.TIME, 0) AS seconds,
+			m.LOCK_TYPE AS mode,
+			CONCAT(m.OBJECT_SCHEMA, '.', m.OBJECT_NAME) AS object,
+			m.LOCK_STATUS = 'GRANTED' AS granted,
+			'' AS lock_id
+		FROM performance_schema.metadata_locks m
+		JOIN performance_schema.threads t ON t.THREAD_ID = m.OWNER_THREAD_ID
+		LEFT JOIN information_schema.PROCESSLIST p ON p.ID = t.PROCESSLIST_ID
+		WHERE t.PROCESSLIST_ID IS NOT NULL AND m.OBJECT_TYPE = 'TABLE' AND EXISTS (
+			SELECT 1 FROM performance_schema.metadata_locks w
+			WHERE w.LOCK_STATUS = 'PENDING' AND w.OBJECT_TYPE = 'TABLE'
+				AND w.OBJECT_SCHEMA = m.OBJECT_SCHEMA AND w.OBJECT_NAME = m.OBJECT_NAME
+		)
+	`
+
+	return e.locks(ctx, mySQLLockQueries{
+		data:     dataQuery,
+		blockers: blockersQuery,
+		metadata: metadataQuery,
+	})
+}
+
+func (e *mySQL) locks(ctx context.Context, queries mySQLLockQueries) ([]Lock, error) {
+	var data, metadata []mySQLLock
+	if err := e.db.SelectContext(ctx, &data, queries.data); err != nil {
+		return nil, fmt.Errorf("get data locks: %w", err)
+	}
+	if queries.metadata != "" {
+		if err := e.db.SelectContext(ctx, &metadata, queries.metadata); err != nil {
+			return nil, fmt.Errorf("get metadata locks: %w", err)
+		}
+	}
+
+	var blockers []mySQLLockBlocker
+	if err := e.db.SelectContext(ctx, &blockers, queries.blockers); err != nil {
+		return nil, fmt.Errorf("get lock waits: %w", err)
+	}
+
+	blockedBy := make(map[string][]int64, len(blockers))
+	for _, b := range blockers {
+		blockedBy[b.LockID] = append(blockedBy[b.LockID], b.Blocking)
+	}
+
+	locks := make([]Lock, 0, len(data)+len(metadata))
+	for _, l := range data {
+		lock := l.lock()
+		if !l.Granted {
+			lock.BlockedBy = blockedBy[l.LockID]
+		}
+		locks = append(locks, lock)
+	}
+
+	// MySQL doesn't tell who a pending metadata lock waits for, so it is
+	// taken to wait for every session holding one on the same table.
+	for _, l := range metadata {
+		lock := l.lock()
+		if !l.Granted {
+			for _, h := range metadata {
+				if h.Granted && h.Object == l.Object && h.Session != l.Session &&
+					!slices.Contains(lock.BlockedBy, h.Session) {
+					lock.BlockedBy = append(lock.BlockedBy, h.Session)
+				}
+			}
+		}
+		locks = append(locks, lock)
+	}
+
+	return locks, nil
+}
+
+func (l mySQLLock) lock() Lock {
+	return Lock{
+		Session:  l.Session,
+		User:     l.User,
+		Query:    l.Query,
+		Duration: time.Duration(l.Seconds) * time.Second,
+		Mode:     l.Mode,
+		Object:   l.Object,
+		Granted:  l.Granted,
+	}
+}
+
+func (e *mySQL) CancelQuery(ctx context.Context, id int64) error {
+	return e.kill(ctx, "KILL QUERY", id)
+}
+
+func (e *mySQL) TerminateSession(ctx context.Context, id int64) error {
+	return e.kill(ctx, "KILL CONNECTION", id)
+}
+
+func (e *mySQL) kill(ctx context.Context, statement string, id int64) error {
+	// KILL takes no placeholders, the id is a number so it is safe to
+	// format in.
+	if _, err := e.db.ExecContext(ctx, fmt.Sprintf("%s %d", statement, id)); err != nil {
+		return fmt.Errorf("kill session %d: %w", id, err)
 	}
 	return nil
 }
 
+func (e *mySQL) GetPrimaryKey(ctx context.Context, table string) ([]Column, error) {
+	const query = `
+		SELECT COLUMN_NAME
+		FROM information_schema.KEY_COLUMN_USAGE
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND CONSTRAINT_NAME = 'PRIMARY'
+		ORDER BY ORDINAL_POSITION
+		`
+	var key []Column
+	if err := e.db.SelectContext(ctx, &key, query, table); err != nil {
+		return nil, fmt.Errorf("get primary key of %q: %w", table, err)
+	}
+	return key, nil
+}
+
+func (e *mySQL) ReadOnly() Explorer {
+	ro := *e
+	ro.readOnly = true
+	return &ro
+}
+
+func (e *mySQL) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SELECT VERSION()"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "MySQL", Version: version}, nil
+}
+
+func (e *mySQL) Query(ctx context.Context, query string) ([]Row, []Column, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		rows []Row
+		cols []Column
+	)
+	err := mysqlCancel.run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return within(ctx, conn, e.readOnly, func(q queryer) error {
+			var err error
+			rows, cols, err = queryRows(ctx, q, query)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("run query %q: %w", query, err)
+	}
+	return rows, cols, nil
+}
+
 func (e *mySQL) GetTables(ctx context.Context) ([]Table, error) {
 	const query = `
 		SELECT TABLE_NAME, TABLE_TYPE FROM INFORMATION_SCHEMA.TABLES 
@@ -51,6 +409,39 @@ func (e *mySQL) toTables(tables []mySQLTable) []Table {
 	return result
 }
 
+type mySQLTableStats struct {
+	Name          string        `db:"TABLE_NAME"`
+	Rows          sql.NullInt64 `db:"TABLE_ROWS"`
+	Size          sql.NullInt64 `db:"DATA_LENGTH"`
+	IndexSize     sql.NullInt64 `db:"INDEX_LENGTH"`
+	AutoIncrement sql.NullInt64 `db:"AUTO_INCREMENT"`
+}
+
+func (e *mySQL) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	const query = `
+		SELECT TABLE_NAME, TABLE_ROWS, DATA_LENGTH, INDEX_LENGTH, AUTO_INCREMENT
+		FROM INFORMATION_SCHEMA.TABLES
+		WHERE TABLE_SCHEMA=?
+	`
+
+	var tables []mySQLTableStats
+	if err := e.db.SelectContext(ctx, &tables, query, e.schema); err != nil {
+		return nil, fmt.Errorf("get table stats: %w", err)
+	}
+
+	stats := make(map[string]TableStats, len(tables))
+	for _, t := range tables {
+		stats[t.Name] = TableStats{
+			Rows:          t.Rows.Int64,
+			Size:          t.Size.Int64,
+			IndexSize:     t.IndexSize.Int64,
+			AutoIncrement: t.AutoIncrement.Int64,
+		}
+	}
+
+	return stats, nil
+}
+
 func (e *mySQL) GetColumns(ctx context.Context, table string) ([]Row, []Column, error) {
 	const queryFmt = `
 		SELECT * from information_schema.columns
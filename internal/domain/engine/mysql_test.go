@@ -62,7 +62,7 @@ func Test_mySQL_Execute(t *testing.T) {
 				schema: dbName,
 			}
 
-			err := e.Execute(tt.args.ctx, tt.args.query)
+			_, err := e.Execute(tt.args.ctx, tt.args.query)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -73,6 +73,110 @@ func Test_mySQL_Execute(t *testing.T) {
 	}
 }
 
+func Test_mySQL_Query_Cancel(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mysqlTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mySQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond*200)
+	defer cancel()
+
+	_, _, err := e.Query(ctx, "SELECT SLEEP(30)")
+	require.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		var running int
+		err := db.GetContext(
+			t.Context(),
+			&running,
+			"SELECT COUNT(*) FROM information_schema.PROCESSLIST WHERE INFO LIKE 'SELECT SLEEP%'",
+		)
+		return err == nil && running == 0
+	}, time.Second*5, time.Millisecond*100)
+}
+
+func Test_mySQL_CancelQuery(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mysqlTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mySQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(t.Context(), "SELECT SLEEP(30)")
+		done <- err
+	}()
+
+	var sleeping Session
+	require.Eventually(t, func() bool {
+		sessions, err := e.GetActivity(t.Context())
+		if err != nil {
+			return false
+		}
+		for _, s := range sessions {
+			if s.Query == "SELECT SLEEP(30)" {
+				sleeping = s
+				return true
+			}
+		}
+		return false
+	}, time.Second*5, time.Millisecond*100)
+
+	require.NoError(t, e.CancelQuery(t.Context(), sleeping.ID))
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("query was not cancelled")
+	}
+}
+
+func Test_mySQL_Explain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mysqlTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mySQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	got, err := e.Explain(t.Context(), "SELECT * FROM users", true)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	scan := got[0].Children[0]
+	require.Equal(t, "Full scan", scan.Operation)
+	require.Equal(t, "users", scan.Relation)
+	require.True(t, scan.FullScan)
+}
+
+func Test_mySQL_GetTableStats(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedMySQL(t, mysqlDB, mysqlTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &mySQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	got, err := e.GetTableStats(t.Context())
+	require.NoError(t, err)
+	require.Contains(t, got, tableName)
+	require.Positive(t, got[tableName].Size)
+}
+
 func Test_mySQL_GetTables(t *testing.T) {
 	xtest.SkipUnitIfRequired(t)
 	type args struct {
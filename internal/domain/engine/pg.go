@@ -2,16 +2,87 @@ package engine
 
 import (
 	"context"
+	"database/sql"
 	"fmt"
 	"log/slog"
+	"net/url"
 	"strings"
+	"time"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
 )
 
+// postgreSQLEngine connects to PostgreSQL with URL or key=value DSNs.
+type postgreSQLEngine struct{}
+
+func (postgreSQLEngine) Driver() string {
+	return postgresqlDB
+}
+
+func (postgreSQLEngine) Schemes() []string {
+	return []string{"postgres", "postgresql"}
+}
+
+func (postgreSQLEngine) Detect(dsn string) bool {
+	return strings.Contains(dsn, "dbname=")
+}
+
+func (postgreSQLEngine) Parse(dsn string) (Target, error) {
+	if !strings.Contains(dsn, schemeSeparator) {
+		return parsePostgreSQLKeyValues(dsn), nil
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return Target{}, err
+	}
+
+	// The DSN is kept as written, since it names the connection in the
+	// config. TLS is set up when connecting.
+	return Target{DSN: dsn, Database: strings.TrimPrefix(u.Path, "/")}, nil
+}
+
+// Explorer asks the server for its version, as CockroachDB speaks the
+// protocol of PostgreSQL but keeps its statistics elsewhere.
+func (postgreSQLEngine) Explorer(ctx context.Context, db *sqlx.DB, target Target) (Explorer, error) {
+	e := &postgreSQL{db: db, schema: target.Database}
+
+	var version string
+	if err := db.GetContext(ctx, &version, "SELECT version()"); err != nil {
+		return nil, fmt.Errorf("get server version: %w", err)
+	}
+
+	if isCockroachDB(version) {
+		return newCockroachDB(e), nil
+	}
+	return e, nil
+}
+
+func parsePostgreSQLKeyValues(dsn string) Target {
+	t := Target{DSN: dsn}
+	for _, field := range strings.Fields(dsn) {
+		if name, ok := strings.CutPrefix(field, "dbname="); ok {
+			t.Database = strings.Trim(name, "'")
+		}
+	}
+
+	return t
+}
+
 type postgreSQL struct {
-	db     *sqlx.DB
-	schema string
+	db       *sqlx.DB
+	schema   string
+	readOnly bool
+	// cancel stops statements on the server, postgresCancel when unset.
+	cancel *serverCancel
+}
+
+func (e *postgreSQL) serverCancel() *serverCancel {
+	if e.cancel != nil {
+		return e.cancel
+	}
+	return postgresCancel
 }
 
 type postgreSQLTable struct {
@@ -19,13 +90,243 @@ type postgreSQLTable struct {
 	Schema string `db:"schemaname"`
 }
 
-func (e *postgreSQL) Execute(ctx context.Context, query string) error {
-	if _, err := e.db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("execute command %q: %w", query, err)
+func (e *postgreSQL) Execute(ctx context.Context, query string) (int64, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return 0, err
+	}
+
+	var affected int64
+	err := e.serverCancel().run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return within(ctx, conn, e.readOnly, func(q queryer) error {
+			var err error
+			affected, err = execute(ctx, q, query)
+			return err
+		})
+	})
+	return affected, err
+}
+
+// Explain analyzes within a transaction which is rolled back, so the
+// changes of a statement which writes aren't kept.
+func (e *postgreSQL) Explain(ctx context.Context, query string, analyze bool) ([]PlanNode, error) {
+	run := within
+	options := "FORMAT JSON"
+	if analyze {
+		if err := checkReadOnly(e.readOnly, query); err != nil {
+			return nil, err
+		}
+		run = rolledBack
+		options = "ANALYZE, " + options
+	}
+
+	var raw []byte
+	err := e.serverCancel().run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return run(ctx, conn, e.readOnly, func(q queryer) error {
+			return q.QueryRowxContext(ctx, fmt.Sprintf("EXPLAIN (%s) %s", options, query)).Scan(&raw)
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("explain query %q: %w", query, err)
+	}
+
+	return parsePostgresPlan(raw)
+}
+
+type postgreSQLSession struct {
+	ID        int64         `db:"pid"`
+	User      string        `db:"usename"`
+	Database  string        `db:"datname"`
+	Client    string        `db:"client"`
+	State     string        `db:"state"`
+	Query     string        `db:"query"`
+	Seconds   float64       `db:"seconds"`
+	Wait      string        `db:"wait"`
+	BlockedBy pq.Int64Array `db:"blocked_by"`
+}
+
+func (e *postgreSQL) GetActivity(ctx context.Context) ([]Session, error) {
+	const query = `
+		SELECT pid,
+			COALESCE(usename, '') AS usename,
+			COALESCE(datname, '') AS datname,
+			COALESCE(host(client_addr), '') AS client,
+			COALESCE(state, '') AS state,
+			query,
+			COALESCE(EXTRACT(EPOCH FROM now() - CASE
+				WHEN state = 'active' THEN query_start
+				ELSE state_change
+			END), 0)::float8 AS seconds,
+			COALESCE(wait_event_type || ': ' || wait_event, '') AS wait,
+			pg_blocking_pids(pid)::bigint[] AS blocked_by
+		FROM pg_stat_activity
+		WHERE pid <> pg_backend_pid() AND backend_type = 'client backend'
+	`
+
+	var sessions []postgreSQLSession
+	if err := e.db.SelectContext(ctx, &sessions, query); err != nil {
+		return nil, fmt.Errorf("get activity: %w", err)
+	}
+
+	result := make([]Session, 0, len(sessions))
+	for _, s := range sessions {
+		result = append(result, Session{
+			ID:        s.ID,
+			User:      s.User,
+			Database:  s.Database,
+			Client:    s.Client,
+			State:     s.State,
+			Query:     s.Query,
+			Duration:  time.Duration(s.Seconds * float64(time.Second)),
+			Wait:      s.Wait,
+			BlockedBy: s.BlockedBy,
+		})
+	}
+
+	return result, nil
+}
+
+type postgreSQLLock struct {
+	Session   int64         `db:"pid"`
+	User      string        `db:"usename"`
+	Query     string        `db:"query"`
+	Seconds   float64       `db:"seconds"`
+	Mode      string        `db:"mode"`
+	Object    string        `db:"object"`
+	Granted   bool          `db:"granted"`
+	BlockedBy pq.Int64Array `db:"blocked_by"`
+}
+
+func (e *postgreSQL) GetLocks(ctx context.Context) ([]Lock, error) {
+	const query = `
+		SELECT l.pid,
+			COALESCE(a.usename, '') AS usename,
+			COALESCE(a.query, '') AS query,
+			COALESCE(EXTRACT(EPOCH FROM now() - a.query_start), 0)::float8 AS seconds,
+			l.mode,
+			CASE l.locktype
+				WHEN 'relation' THEN l.relation::regclass::text
+				WHEN 'tuple' THEN 'row of ' || l.relation::regclass::text
+				WHEN 'transactionid' THEN 'transaction ' || l.transactionid
+				WHEN 'virtualxid' THEN 'virtual transaction ' || l.virtualxid
+				ELSE l.locktype
+			END AS object,
+			l.granted,
+			CASE WHEN l.granted THEN '{}' ELSE pg_blocking_pids(l.pid) END::bigint[] AS blocked_by
+		FROM pg_catalog.pg_locks l
+		JOIN pg_catalog.pg_stat_activity a ON a.pid = l.pid
+		WHERE l.pid <> pg_backend_pid() AND (
+			NOT l.granted OR EXISTS (
+				SELECT 1 FROM pg_catalog.pg_locks w
+				WHERE NOT w.granted
+					AND l.pid = ANY(pg_blocking_pids(w.pid))
+					AND w.locktype = l.locktype
+					AND w.database IS NOT DISTINCT FROM l.database
+					AND w.relation IS NOT DISTINCT FROM l.relation
+					AND w.page IS NOT DISTINCT FROM l.page
+					AND w.tuple IS NOT DISTINCT FROM l.tuple
+					AND w.virtualxid IS NOT DISTINCT FROM l.virtualxid
+					AND w.transactionid IS NOT DISTINCT FROM l.transactionid
+					AND w.classid IS NOT DISTINCT FROM l.classid
+					AND w.objid IS NOT DISTINCT FROM l.objid
+					AND w.objsubid IS NOT DISTINCT FROM l.objsubid
+			)
+		)
+	`
+
+	var rows []postgreSQLLock
+	if err := e.db.SelectContext(ctx, &rows, query); err != nil {
+		return nil, fmt.Errorf("get locks: %w", err)
+	}
+
+	locks := make([]Lock, 0, len(rows))
+	for _, l := range rows {
+		locks = append(locks, Lock{
+			Session:   l.Session,
+			User:      l.User,
+			Query:     l.Query,
+			Duration:  time.Duration(l.Seconds * float64(time.Second)),
+			Mode:      l.Mode,
+			Object:    l.Object,
+			Granted:   l.Granted,
+			BlockedBy: l.BlockedBy,
+		})
+	}
+
+	return locks, nil
+}
+
+func (e *postgreSQL) CancelQuery(ctx context.Context, id int64) error {
+	return e.signal(ctx, "pg_cancel_backend", id)
+}
+
+func (e *postgreSQL) TerminateSession(ctx context.Context, id int64) error {
+	return e.signal(ctx, "pg_terminate_backend", id)
+}
+
+// signal calls one of the functions signalling a backend, which tell
+// whether the backend was found.
+func (e *postgreSQL) signal(ctx context.Context, fn string, id int64) error {
+	var ok bool
+	if err := e.db.GetContext(ctx, &ok, fmt.Sprintf("SELECT %s($1)", fn), id); err != nil {
+		return fmt.Errorf("signal session %d: %w", id, err)
+	}
+	if !ok {
+		return fmt.Errorf("signal session %d: %w", id, errNoSession)
 	}
 	return nil
 }
 
+func (e *postgreSQL) GetPrimaryKey(ctx context.Context, table string) ([]Column, error) {
+	const query = `
+		SELECT a.attname
+		FROM pg_index i
+		JOIN pg_attribute a ON a.attrelid = i.indrelid AND a.attnum = ANY(i.indkey)
+		WHERE i.indrelid = $1::regclass AND i.indisprimary
+		ORDER BY array_position(i.indkey, a.attnum)
+		`
+	var key []Column
+	if err := e.db.SelectContext(ctx, &key, query, table); err != nil {
+		return nil, fmt.Errorf("get primary key of %q: %w", table, err)
+	}
+	return key, nil
+}
+
+func (e *postgreSQL) ReadOnly() Explorer {
+	ro := *e
+	ro.readOnly = true
+	return &ro
+}
+
+func (e *postgreSQL) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SHOW server_version"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "PostgreSQL", Version: version}, nil
+}
+
+func (e *postgreSQL) Query(ctx context.Context, query string) ([]Row, []Column, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		rows []Row
+		cols []Column
+	)
+	err := e.serverCancel().run(ctx, e.db, func(conn *sqlx.Conn) error {
+		return within(ctx, conn, e.readOnly, func(q queryer) error {
+			var err error
+			rows, cols, err = queryRows(ctx, q, query)
+			return err
+		})
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("run query %q: %w", query, err)
+	}
+	return rows, cols, nil
+}
+
 func (e *postgreSQL) GetColumns(ctx context.Context, table string) ([]Row, []Column, error) {
 	const queryFmt = `
 		SELECT *
@@ -83,11 +384,54 @@ func (e *postgreSQL) GetTables(ctx context.Context) ([]Table, error) {
 func (e *postgreSQL) toTables(tables []postgreSQLTable) []Table {
 	var t []Table
 	for _, table := range tables {
-		t = append(t, Table(table))
+		t = append(t, Table{Name: table.Name, Schema: table.Schema})
 	}
 	return t
 }
 
+type postgreSQLTableStats struct {
+	Name        string       `db:"name"`
+	Rows        int64        `db:"rows"`
+	Size        int64        `db:"size"`
+	IndexSize   int64        `db:"index_size"`
+	LastVacuum  sql.NullTime `db:"last_vacuum"`
+	LastAnalyze sql.NullTime `db:"last_analyze"`
+}
+
+func (e *postgreSQL) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	const query = `
+		SELECT c.relname AS name,
+			GREATEST(c.reltuples, 0)::bigint AS rows,
+			pg_table_size(c.oid) AS size,
+			pg_indexes_size(c.oid) AS index_size,
+			GREATEST(s.last_vacuum, s.last_autovacuum) AS last_vacuum,
+			GREATEST(s.last_analyze, s.last_autoanalyze) AS last_analyze
+		FROM pg_catalog.pg_class c
+		JOIN pg_catalog.pg_namespace n ON n.oid = c.relnamespace
+		LEFT JOIN pg_catalog.pg_stat_user_tables s ON s.relid = c.oid
+		WHERE c.relkind IN ('r', 'p')
+			AND n.nspname != 'pg_catalog' AND n.nspname != 'information_schema'
+	`
+
+	var tables []postgreSQLTableStats
+	if err := e.db.SelectContext(ctx, &tables, query); err != nil {
+		return nil, fmt.Errorf("get table stats: %w", err)
+	}
+
+	stats := make(map[string]TableStats, len(tables))
+	for _, t := range tables {
+		stats[t.Name] = TableStats{
+			Rows:        t.Rows,
+			Size:        t.Size,
+			IndexSize:   t.IndexSize,
+			LastVacuum:  t.LastVacuum.Time,
+			LastAnalyze: t.LastAnalyze.Time,
+		}
+	}
+
+	return stats, nil
+}
+
 type Column = string
 
 type Row = []string
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
@@ -55,7 +56,7 @@ func Test_postgreSQL_Execute(t *testing.T) {
 				schema: dbName,
 			}
 
-			err := e.Execute(tt.args.ctx, tt.args.query)
+			_, err := e.Execute(tt.args.ctx, tt.args.query)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -66,6 +67,162 @@ func Test_postgreSQL_Execute(t *testing.T) {
 	}
 }
 
+func Test_postgreSQL_Query_Cancel(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, postgresTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &postgreSQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), time.Millisecond*200)
+	defer cancel()
+
+	_, _, err := e.Query(ctx, "SELECT pg_sleep(30)")
+	require.Error(t, err)
+
+	require.Eventually(t, func() bool {
+		var running int
+		err := db.GetContext(
+			t.Context(),
+			&running,
+			"SELECT count(*) FROM pg_stat_activity WHERE state = 'active' AND query LIKE 'SELECT pg_sleep%'",
+		)
+		return err == nil && running == 0
+	}, time.Second*5, time.Millisecond*100)
+}
+
+func Test_postgreSQL_CancelQuery(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, postgresTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &postgreSQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := db.ExecContext(t.Context(), "SELECT pg_sleep(30)")
+		done <- err
+	}()
+
+	var sleeping Session
+	require.Eventually(t, func() bool {
+		sessions, err := e.GetActivity(t.Context())
+		if err != nil {
+			return false
+		}
+		for _, s := range sessions {
+			if s.Query == "SELECT pg_sleep(30)" {
+				sleeping = s
+				return true
+			}
+		}
+		return false
+	}, time.Second*5, time.Millisecond*100)
+
+	require.NoError(t, e.CancelQuery(t.Context(), sleeping.ID))
+
+	select {
+	case err := <-done:
+		require.Error(t, err)
+	case <-time.After(time.Second * 5):
+		t.Fatal("query was not cancelled")
+	}
+}
+
+func Test_postgreSQL_GetLocks(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, postgresTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &postgreSQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	tx, err := db.BeginTxx(t.Context(), nil)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = tx.Rollback() })
+
+	_, err = tx.ExecContext(t.Context(), "LOCK TABLE users IN ACCESS EXCLUSIVE MODE")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	t.Cleanup(cancel)
+	go func() {
+		_, _ = db.ExecContext(ctx, "SELECT * FROM users")
+	}()
+
+	var tree []LockNode
+	require.Eventually(t, func() bool {
+		locks, err := e.GetLocks(t.Context())
+		if err != nil {
+			return false
+		}
+		tree = BuildLockTree(locks)
+		return len(tree) == 1 && len(tree[0].Children) == 1
+	}, time.Second*5, time.Millisecond*100)
+
+	require.False(t, tree[0].Waiting())
+	require.True(t, tree[0].Children[0].Waiting())
+	require.Equal(t, "SELECT * FROM users", tree[0].Children[0].Query)
+}
+
+func Test_postgreSQL_Explain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, postgresTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &postgreSQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	got, err := e.Explain(t.Context(), "SELECT * FROM users", true)
+	require.NoError(t, err)
+	require.Len(t, got, 1)
+
+	scan := got[0]
+	require.Equal(t, "Seq Scan", scan.Operation)
+	require.Equal(t, "users", scan.Relation)
+	require.True(t, scan.FullScan)
+
+	_, err = e.Explain(t.Context(), "WITH d AS (DELETE FROM users RETURNING id) SELECT * FROM d", true)
+	require.NoError(t, err)
+
+	rows, _, err := e.Query(t.Context(), "SELECT * FROM users")
+	require.NoError(t, err)
+	require.NotEmpty(t, rows, "analyzing should not keep what the statement deletes")
+}
+
+func Test_postgreSQL_GetTableStats(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedPostgreSQL(t, postgresqlDB, postgresTestDSN)
+	t.Cleanup(cleanup)
+
+	e := &postgreSQL{
+		db:     db,
+		schema: dbName,
+	}
+
+	_, err := db.ExecContext(t.Context(), "ANALYZE "+tableName)
+	require.NoError(t, err)
+
+	got, err := e.GetTableStats(t.Context())
+	require.NoError(t, err)
+	require.Contains(t, got, tableName)
+
+	stats := got[tableName]
+	require.Positive(t, stats.Rows)
+	require.Positive(t, stats.Size)
+	require.False(t, stats.LastAnalyze.IsZero())
+}
+
 func Test_postgreSQL_GetTables(t *testing.T) {
 	xtest.SkipUnitIfRequired(t)
 	type args struct {
@@ -0,0 +1,472 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// LargeTableRows is the number of rows from which a full scan of a
+// table is worth pointing out.
+const LargeTableRows = 10_000
+
+// PlanNode is a step of a query plan, described the same way for every
+// engine.
+type PlanNode struct {
+	Operation string
+	Relation  string
+	Detail    string
+	// Cost and Rows are estimates of the planner, set only when
+	// Estimated is, as SQLite doesn't show them.
+	Cost      float64
+	Rows      float64
+	Estimated bool
+	// Time is how long the step took, set only when Analyzed is.
+	Time     time.Duration
+	Analyzed bool
+	FullScan bool
+	Children []PlanNode
+}
+
+// IsSlowScan tells whether the step reads a whole table which is large,
+// or whose size is not known.
+func (n PlanNode) IsSlowScan() bool {
+	return n.FullScan && (!n.Estimated || n.Rows >= LargeTableRows)
+}
+
+type postgresPlan struct {
+	NodeType     string         `json:"Node Type"`
+	RelationName string         `json:"Relation Name"`
+	IndexName    string         `json:"Index Name"`
+	JoinType     string         `json:"Join Type"`
+	Filter       string         `json:"Filter"`
+	TotalCost    float64        `json:"Total Cost"`
+	PlanRows     float64        `json:"Plan Rows"`
+	ActualTime   *float64       `json:"Actual Total Time"`
+	ActualLoops  float64        `json:"Actual Loops"`
+	Plans        []postgresPlan `json:"Plans"`
+}
+
+// parsePostgresPlan reads the output of EXPLAIN (FORMAT JSON).
+func parsePostgresPlan(raw []byte) ([]PlanNode, error) {
+	var out []struct {
+		Plan postgresPlan `json:"Plan"`
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+
+	nodes := make([]PlanNode, 0, len(out))
+	for _, o := range out {
+		nodes = append(nodes, o.Plan.node())
+	}
+
+	return nodes, nil
+}
+
+func (p postgresPlan) node() PlanNode {
+	details := make([]string, 0, 3)
+	if p.JoinType != "" {
+		details = append(details, strings.ToLower(p.JoinType)+" join")
+	}
+	if p.IndexName != "" {
+		details = append(details, "using "+p.IndexName)
+	}
+	if p.Filter != "" {
+		details = append(details, "filter "+p.Filter)
+	}
+
+	n := PlanNode{
+		Operation: p.NodeType,
+		Relation:  p.RelationName,
+		Detail:    strings.Join(details, ", "),
+		Cost:      p.TotalCost,
+		Rows:      p.PlanRows,
+		Estimated: true,
+		FullScan:  p.NodeType == "Seq Scan",
+	}
+
+	if p.ActualTime != nil {
+		// The time is per loop, in milliseconds.
+		ms := *p.ActualTime * max(p.ActualLoops, 1)
+		n.Time = time.Duration(ms * float64(time.Millisecond))
+		n.Analyzed = true
+	}
+
+	for _, child := range p.Plans {
+		n.Children = append(n.Children, child.node())
+	}
+
+	return n
+}
+
+// parseMySQLPlan reads the output of EXPLAIN FORMAT=JSON. Unlike the one
+// of PostgreSQL it has no fixed node shape: every operation is a key
+// holding an object, and tables are leaves under "table" keys.
+func parseMySQLPlan(raw []byte) ([]PlanNode, error) {
+	var out map[string]any
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("parse plan: %w", err)
+	}
+
+	return mysqlChildren(out), nil
+}
+
+var mysqlAccessTypes = map[string]string{
+	"ALL":             "Full scan",
+	"index":           "Index scan",
+	"range":           "Index range scan",
+	"ref":             "Index lookup",
+	"eq_ref":          "Unique index lookup",
+	"const":           "Constant lookup",
+	"system":          "Constant lookup",
+	"fulltext":        "Fulltext index lookup",
+	"index_merge":     "Index merge",
+	"ref_or_null":     "Index lookup with nulls",
+	"unique_subquery": "Unique subquery lookup",
+	"index_subquery":  "Subquery index lookup",
+}
+
+func mysqlNode(name string, obj map[string]any) PlanNode {
+	if _, ok := obj["table_name"]; ok {
+		return mysqlTable(obj)
+	}
+
+	n := PlanNode{Operation: mysqlOperation(name)}
+	if cost, ok := obj["cost_info"].(map[string]any); ok {
+		n.Cost, n.Estimated = jsonNumber(cost["query_cost"])
+	}
+	n.Children = mysqlChildren(obj)
+
+	return n
+}
+
+func mysqlTable(obj map[string]any) PlanNode {
+	access, _ := obj["access_type"].(string)
+	table, _ := obj["table_name"].(string)
+
+	op, ok := mysqlAccessTypes[access]
+	if !ok {
+		op = "Table access"
+	}
+
+	n := PlanNode{
+		Operation: op,
+		Relation:  table,
+		FullScan:  access == "ALL",
+	}
+
+	if key, ok := obj["key"].(string); ok {
+		n.Detail = "using " + key
+	}
+
+	rows, hasRows := jsonNumber(obj["rows_examined_per_scan"])
+	if cost, ok := obj["cost_info"].(map[string]any); ok {
+		n.Cost, _ = jsonNumber(cost["prefix_cost"])
+	}
+	n.Rows = rows
+	n.Estimated = hasRows
+
+	n.Children = mysqlChildren(obj)
+	return n
+}
+
+// mysqlChildren finds the operations nested in obj, in a stable order as
+// the order of object keys is lost when decoding.
+func mysqlChildren(obj map[string]any) []PlanNode {
+	var nodes []PlanNode
+
+	for _, k := range slices.Sorted(maps.Keys(obj)) {
+		if k == "cost_info" {
+			continue
+		}
+
+		switch v := obj[k].(type) {
+		case map[string]any:
+			nodes = append(nodes, mysqlNode(k, v))
+		case []any:
+			for _, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					nodes = append(nodes, mysqlItem(k, m))
+				}
+			}
+		}
+	}
+
+	return nodes
+}
+
+// mysqlItem reads an element of a list like nested_loop, which wraps the
+// node in a single key.
+func mysqlItem(list string, item map[string]any) PlanNode {
+	if len(item) == 1 {
+		for k, v := range item {
+			if m, ok := v.(map[string]any); ok {
+				return mysqlNode(k, m)
+			}
+		}
+	}
+
+	return mysqlNode(list, item)
+}
+
+func mysqlOperation(key string) string {
+	op := strings.ReplaceAll(key, "_", " ")
+	if op == "" {
+		return op
+	}
+	return strings.ToUpper(op[:1]) + op[1:]
+}
+
+// jsonNumber reads a number MySQL may give either as a number or as a
+// string.
+func jsonNumber(v any) (float64, bool) {
+	switch v := v.(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+type sqlitePlanRow struct {
+	ID      int    `db:"id"`
+	Parent  int    `db:"parent"`
+	NotUsed int    `db:"notused"`
+	Detail  string `db:"detail"`
+}
+
+// buildSQLitePlan turns rows of EXPLAIN QUERY PLAN, which point to their
+// parent by id, into a tree.
+func buildSQLitePlan(rows []sqlitePlanRow) []PlanNode {
+	children := make(map[int][]sqlitePlanRow, len(rows))
+	for _, r := range rows {
+		children[r.Parent] = append(children[r.Parent], r)
+	}
+
+	var build func(parent int) []PlanNode
+	build = func(parent int) []PlanNode {
+		var nodes []PlanNode
+		for _, r := range children[parent] {
+			n := sqliteNode(r.Detail)
+			if r.ID != parent {
+				n.Children = build(r.ID)
+			}
+			nodes = append(nodes, n)
+		}
+		return nodes
+	}
+
+	return build(0)
+}
+
+func sqliteNode(detail string) PlanNode {
+	op, rest, _ := strings.Cut(detail, " ")
+	if op != "SCAN" && op != "SEARCH" {
+		return PlanNode{Operation: detail}
+	}
+
+	// Older versions say "SCAN TABLE users".
+	rest = strings.TrimPrefix(rest, "TABLE ")
+	relation, extra, _ := strings.Cut(rest, " ")
+
+	return PlanNode{
+		Operation: strings.ToUpper(op[:1]) + strings.ToLower(op[1:]),
+		Relation:  relation,
+		Detail:    strings.ToLower(strings.TrimSpace(extra)),
+		FullScan:  op == "SCAN" && !strings.Contains(extra, "USING"),
+	}
+}
+
+type cockroachDBPlanNode struct {
+	PlanNode
+	column   int
+	details  []string
+	children []*cockroachDBPlanNode
+}
+
+// parseCockroachDBPlan reads the lines of EXPLAIN, which draw the plan
+// as a tree: operations follow a bullet indented by their depth, and
+// their attributes follow them as "key: value" lines.
+func parseCockroachDBPlan(lines []string) []PlanNode {
+	var roots, path []*cockroachDBPlanNode
+	for _, line := range lines {
+		indent, op, ok := strings.Cut(line, "• ")
+		if op = strings.TrimSpace(op); ok && op != "" {
+			n := &cockroachDBPlanNode{
+				PlanNode: PlanNode{Operation: strings.ToUpper(op[:1]) + op[1:]},
+				column:   utf8.RuneCountInString(indent),
+			}
+
+			for len(path) > 0 && path[len(path)-1].column >= n.column {
+				path = path[:len(path)-1]
+			}
+			if len(path) == 0 {
+				roots = append(roots, n)
+			} else {
+				parent := path[len(path)-1]
+				parent.children = append(parent.children, n)
+			}
+			path = append(path, n)
+			continue
+		}
+
+		// Lines before the first operation describe the whole plan.
+		if len(path) == 0 {
+			continue
+		}
+		key, value, ok := strings.Cut(strings.TrimLeft(line, "│├└─ "), ": ")
+		if ok {
+			path[len(path)-1].set(key, value)
+		}
+	}
+
+	nodes := make([]PlanNode, 0, len(roots))
+	for _, r := range roots {
+		nodes = append(nodes, r.node())
+	}
+	return nodes
+}
+
+func (n *cockroachDBPlanNode) set(key, value string) {
+	switch key {
+	case "table":
+		relation, index, _ := strings.Cut(value, "@")
+		n.Relation = relation
+		if index != "" {
+			n.details = append(n.details, "using "+index)
+		}
+	case "spans":
+		n.FullScan = strings.HasPrefix(value, "FULL SCAN")
+	case "filter":
+		n.details = append(n.details, "filter "+value)
+	case "estimated row count":
+		// Like "1,000 (100% of the table; stats collected 1 minute ago)".
+		count, _, _ := strings.Cut(value, " ")
+		if rows, err := strconv.ParseFloat(strings.ReplaceAll(count, ",", ""), 64); err == nil {
+			n.Rows = rows
+			n.Estimated = true
+		}
+	case "execution time":
+		if d, err := time.ParseDuration(value); err == nil {
+			n.Time = d
+			n.Analyzed = true
+		}
+	}
+}
+
+func (n *cockroachDBPlanNode) node() PlanNode {
+	node := n.PlanNode
+	node.Detail = strings.Join(n.details, ", ")
+	for _, child := range n.children {
+		node.Children = append(node.Children, child.node())
+	}
+	return node
+}
+
+type duckDBPlan struct {
+	Name      string         `json:"name"`
+	Operator  string         `json:"operator_type"`
+	Timing    *float64       `json:"operator_timing"`
+	ExtraInfo map[string]any `json:"extra_info"`
+	Children  []duckDBPlan   `json:"children"`
+}
+
+// parseDuckDBPlan reads the output of EXPLAIN (FORMAT JSON), a list of
+// operators, or the one of EXPLAIN (ANALYZE, FORMAT JSON), an object
+// holding totals of the query which wraps them.
+func parseDuckDBPlan(raw []byte) ([]PlanNode, error) {
+	var plans []duckDBPlan
+	if err := json.Unmarshal(raw, &plans); err != nil {
+		var root duckDBPlan
+		if err := json.Unmarshal(raw, &root); err != nil {
+			return nil, fmt.Errorf("parse plan: %w", err)
+		}
+		plans = []duckDBPlan{root}
+	}
+
+	return duckDBNodes(plans), nil
+}
+
+// duckDBNodes turns the operators into nodes, leaving out the wrappers
+// analyzing adds, which are no steps of the plan.
+func duckDBNodes(plans []duckDBPlan) []PlanNode {
+	var nodes []PlanNode
+	for _, p := range plans {
+		op := strings.TrimSpace(p.Name)
+		if op == "" {
+			op = strings.TrimSpace(p.Operator)
+		}
+
+		if op == "" || op == "EXPLAIN_ANALYZE" {
+			nodes = append(nodes, duckDBNodes(p.Children)...)
+			continue
+		}
+		nodes = append(nodes, p.node(op))
+	}
+	return nodes
+}
+
+func (p duckDBPlan) node(op string) PlanNode {
+	filter := duckDBInfo(p.ExtraInfo["Filters"])
+	n := PlanNode{
+		Operation: op[:1] + strings.ToLower(strings.ReplaceAll(op[1:], "_", " ")),
+		FullScan:  (op == "TABLE_SCAN" || op == "SEQ_SCAN") && filter == "",
+	}
+
+	if table := duckDBInfo(p.ExtraInfo["Table"]); table != "" {
+		n.Relation = table
+	} else if strings.HasSuffix(op, "SCAN") {
+		n.Relation = duckDBInfo(p.ExtraInfo["Text"])
+	}
+
+	details := make([]string, 0, 2)
+	if join := duckDBInfo(p.ExtraInfo["Join Type"]); join != "" {
+		details = append(details, strings.ToLower(join)+" join")
+	}
+	if expr := duckDBInfo(p.ExtraInfo["Expression"]); expr != "" {
+		filter = expr
+	}
+	if filter != "" {
+		details = append(details, "filter "+filter)
+	}
+	n.Detail = strings.Join(details, ", ")
+
+	n.Rows, n.Estimated = jsonNumber(p.ExtraInfo["Estimated Cardinality"])
+
+	if p.Timing != nil {
+		// The time is in seconds.
+		n.Time = time.Duration(*p.Timing * float64(time.Second))
+		n.Analyzed = true
+	}
+
+	n.Children = duckDBNodes(p.Children)
+	return n
+}
+
+// duckDBInfo reads an entry of extra_info, which is either a string or a
+// list of them.
+func duckDBInfo(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case []any:
+		parts := make([]string, 0, len(v))
+		for _, part := range v {
+			if s, ok := part.(string); ok {
+				parts = append(parts, s)
+			}
+		}
+		return strings.Join(parts, " AND ")
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,368 @@
+package engine
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func Test_parsePostgresPlan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		raw     string
+		want    []PlanNode
+		wantErr bool
+	}{
+		{
+			name: "Should parse nested plan",
+			raw: `[{"Plan": {
+				"Node Type": "Hash Join", "Join Type": "Inner",
+				"Total Cost": 35.5, "Plan Rows": 20,
+				"Plans": [
+					{"Node Type": "Seq Scan", "Relation Name": "orders", "Total Cost": 22, "Plan Rows": 120000},
+					{"Node Type": "Index Scan", "Relation Name": "users", "Index Name": "users_pkey", "Total Cost": 8.2, "Plan Rows": 1}
+				]
+			}}]`,
+			want: []PlanNode{
+				{
+					Operation: "Hash Join",
+					Detail:    "inner join",
+					Cost:      35.5,
+					Rows:      20,
+					Estimated: true,
+					Children: []PlanNode{
+						{Operation: "Seq Scan", Relation: "orders", Cost: 22, Rows: 120000, Estimated: true, FullScan: true},
+						{Operation: "Index Scan", Relation: "users", Detail: "using users_pkey", Cost: 8.2, Rows: 1, Estimated: true},
+					},
+				},
+			},
+		},
+		{
+			name: "Should multiply time by loops when analyzed",
+			raw: `[{"Plan": {
+				"Node Type": "Seq Scan", "Relation Name": "users", "Filter": "(id > 1)",
+				"Total Cost": 1, "Plan Rows": 2, "Actual Total Time": 0.5, "Actual Loops": 4
+			}}]`,
+			want: []PlanNode{
+				{
+					Operation: "Seq Scan",
+					Relation:  "users",
+					Detail:    "filter (id > 1)",
+					Cost:      1,
+					Rows:      2,
+					Estimated: true,
+					Time:      time.Millisecond * 2,
+					Analyzed:  true,
+					FullScan:  true,
+				},
+			},
+		},
+		{
+			name:    "Should return err for invalid JSON",
+			raw:     `{"Plan"`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parsePostgresPlan([]byte(tt.raw))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_parseMySQLPlan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		raw     string
+		want    []PlanNode
+		wantErr bool
+	}{
+		{
+			name: "Should parse single table",
+			raw: `{"query_block": {
+				"select_id": 1,
+				"cost_info": {"query_cost": "0.55"},
+				"table": {
+					"table_name": "users", "access_type": "ALL",
+					"rows_examined_per_scan": 3,
+					"cost_info": {"prefix_cost": "0.55"},
+					"used_columns": ["id", "name"]
+				}
+			}}`,
+			want: []PlanNode{
+				{
+					Operation: "Query block",
+					Cost:      0.55,
+					Estimated: true,
+					Children: []PlanNode{
+						{Operation: "Full scan", Relation: "users", Cost: 0.55, Rows: 3, Estimated: true, FullScan: true},
+					},
+				},
+			},
+		},
+		{
+			name: "Should keep order of nested loop",
+			raw: `{"query_block": {
+				"cost_info": {"query_cost": "4.10"},
+				"nested_loop": [
+					{"table": {"table_name": "u", "access_type": "index", "key": "name_idx", "rows_examined_per_scan": 10}},
+					{"table": {"table_name": "o", "access_type": "ref", "key": "user_id", "rows_examined_per_scan": 2}}
+				]
+			}}`,
+			want: []PlanNode{
+				{
+					Operation: "Query block",
+					Cost:      4.1,
+					Estimated: true,
+					Children: []PlanNode{
+						{Operation: "Index scan", Relation: "u", Detail: "using name_idx", Rows: 10, Estimated: true},
+						{Operation: "Index lookup", Relation: "o", Detail: "using user_id", Rows: 2, Estimated: true},
+					},
+				},
+			},
+		},
+		{
+			name:    "Should return err for invalid JSON",
+			raw:     `[`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseMySQLPlan([]byte(tt.raw))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_buildSQLitePlan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	rows := []sqlitePlanRow{
+		{ID: 2, Parent: 0, Detail: "SCAN users"},
+		{ID: 5, Parent: 0, Detail: "CORRELATED SCALAR SUBQUERY 1"},
+		{ID: 8, Parent: 5, Detail: "SEARCH orders USING INDEX orders_user (user_id=?)"},
+		{ID: 12, Parent: 0, Detail: "SCAN TABLE logs USING COVERING INDEX logs_at"},
+	}
+
+	want := []PlanNode{
+		{Operation: "Scan", Relation: "users", FullScan: true},
+		{
+			Operation: "CORRELATED SCALAR SUBQUERY 1",
+			Children: []PlanNode{
+				{Operation: "Search", Relation: "orders", Detail: "using index orders_user (user_id=?)"},
+			},
+		},
+		{Operation: "Scan", Relation: "logs", Detail: "using covering index logs_at"},
+	}
+
+	require.Equal(t, want, buildSQLitePlan(rows))
+}
+
+func Test_parseCockroachDBPlan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	lines := []string{
+		"distribution: local",
+		"vectorized: true",
+		"",
+		"• hash join",
+		"│ estimated row count: 1,500",
+		"│ equality: (id) = (user_id)",
+		"│ execution time: 2ms",
+		"│",
+		"├── • scan",
+		"│     estimated row count: 20,000 (100% of the table; stats collected 1 minute ago)",
+		"│     table: users@users_pkey",
+		"│     spans: FULL SCAN",
+		"│",
+		"└── • filter",
+		"    │ filter: total > 10",
+		"    │",
+		"    └── • scan",
+		"          table: orders@orders_user_id_idx",
+		"          spans: [/1 - /1]",
+	}
+
+	want := []PlanNode{
+		{
+			Operation: "Hash join",
+			Rows:      1500,
+			Estimated: true,
+			Time:      2 * time.Millisecond,
+			Analyzed:  true,
+			Children: []PlanNode{
+				{
+					Operation: "Scan",
+					Relation:  "users",
+					Detail:    "using users_pkey",
+					Rows:      20000,
+					Estimated: true,
+					FullScan:  true,
+				},
+				{
+					Operation: "Filter",
+					Detail:    "filter total > 10",
+					Children: []PlanNode{
+						{Operation: "Scan", Relation: "orders", Detail: "using orders_user_id_idx"},
+					},
+				},
+			},
+		},
+	}
+
+	require.Equal(t, want, parseCockroachDBPlan(lines))
+}
+
+func Test_parseDuckDBPlan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		raw     string
+		want    []PlanNode
+		wantErr bool
+	}{
+		{
+			name: "Should parse estimated plan",
+			raw: `[{
+				"name": "HASH_JOIN",
+				"extra_info": {"Join Type": "INNER", "Estimated Cardinality": "3"},
+				"children": [
+					{"name": "SEQ_SCAN ", "extra_info": {"Table": "users", "Estimated Cardinality": "20000"}, "children": []},
+					{"name": "SEQ_SCAN ", "extra_info": {"Table": "orders", "Filters": ["total>10"]}, "children": []}
+				]
+			}]`,
+			want: []PlanNode{
+				{
+					Operation: "Hash join",
+					Detail:    "inner join",
+					Rows:      3,
+					Estimated: true,
+					Children: []PlanNode{
+						{Operation: "Seq scan", Relation: "users", Rows: 20000, Estimated: true, FullScan: true},
+						{Operation: "Seq scan", Relation: "orders", Detail: "filter total>10"},
+					},
+				},
+			},
+		},
+		{
+			name: "Should parse analyzed plan",
+			raw: `{
+				"latency": 0.001,
+				"children": [{
+					"operator_type": "EXPLAIN_ANALYZE",
+					"children": [{
+						"operator_type": "FILTER",
+						"operator_timing": 0.002,
+						"extra_info": {"Expression": "(id > 1)"},
+						"children": [{
+							"operator_type": "TABLE_SCAN",
+							"operator_timing": 0.001,
+							"extra_info": {"Text": "users", "Estimated Cardinality": "2"},
+							"children": []
+						}]
+					}]
+				}]
+			}`,
+			want: []PlanNode{
+				{
+					Operation: "Filter",
+					Detail:    "filter (id > 1)",
+					Time:      2 * time.Millisecond,
+					Analyzed:  true,
+					Children: []PlanNode{
+						{
+							Operation: "Table scan",
+							Relation:  "users",
+							Rows:      2,
+							Estimated: true,
+							Time:      time.Millisecond,
+							Analyzed:  true,
+							FullScan:  true,
+						},
+					},
+				},
+			},
+		},
+		{
+			name:    "Should return an error when plan is not JSON",
+			raw:     "not json",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := parseDuckDBPlan([]byte(tt.raw))
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPlanNode_IsSlowScan(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name string
+		node PlanNode
+		want bool
+	}{
+		{
+			name: "Should flag full scan of large table",
+			node: PlanNode{FullScan: true, Estimated: true, Rows: LargeTableRows},
+			want: true,
+		},
+		{
+			name: "Should flag full scan of table of unknown size",
+			node: PlanNode{FullScan: true},
+			want: true,
+		},
+		{
+			name: "Should not flag full scan of small table",
+			node: PlanNode{FullScan: true, Estimated: true, Rows: 10},
+		},
+		{
+			name: "Should not flag index scan",
+			node: PlanNode{Estimated: true, Rows: LargeTableRows * 10},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, tt.node.IsSlowScan())
+		})
+	}
+}
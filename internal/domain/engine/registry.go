@@ -0,0 +1,133 @@
+package engine
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const schemeSeparator = "://"
+
+// Engine knows how to connect to one kind of database.
+type Engine interface {
+	// Driver is the name of the database/sql driver, which can be set
+	// as the driver of a connection too.
+	Driver() string
+	// Schemes are the URL schemes of DSNs meant for the engine, which
+	// can be set as the driver of a connection as well.
+	Schemes() []string
+	// Detect tells whether a DSN without a scheme is meant for the
+	// engine.
+	Detect(dsn string) bool
+	// Parse turns the DSN into the one the driver accepts.
+	Parse(dsn string) (Target, error)
+	// Explorer creates the explorer on top of the connection. Engines
+	// speaking for several servers, like MariaDB for MySQL, may ask the
+	// server which one it is.
+	Explorer(ctx context.Context, db *sqlx.DB, target Target) (Explorer, error)
+}
+
+// Target is where a parsed DSN points to.
+type Target struct {
+	DSN      string
+	Database string
+	// Files are data files the explorer exposes as tables, for engines
+	// able to read them.
+	Files []string
+}
+
+// NewRegistry returns a registry of the given engines. DSNs without a
+// scheme are detected in the order the engines are given.
+func NewRegistry(engines ...Engine) (*Registry, error) {
+	r := &Registry{}
+	for _, e := range engines {
+		if err := r.Register(e); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// DefaultRegistry returns the registry of the built-in engines.
+func DefaultRegistry() *Registry {
+	return &Registry{
+		engines: []Engine{
+			postgreSQLEngine{}, sqliteEngine{}, duckDBEngine{}, filesEngine{}, mySQLEngine{},
+		},
+	}
+}
+
+// Registry finds the engine a connection is meant for.
+type Registry struct {
+	engines []Engine
+}
+
+// Register adds the engine, unless its names are taken by another one.
+func (r *Registry) Register(e Engine) error {
+	for _, name := range names(e) {
+		if existing, ok := r.byName(name); ok {
+			return fmt.Errorf(
+				"%w: %q is registered by the %s engine already",
+				errs.ErrValidation, name, existing.Driver(),
+			)
+		}
+	}
+
+	r.engines = append(r.engines, e)
+	return nil
+}
+
+// Lookup returns the engine of the connection. The driver, when set,
+// wins over the scheme of the DSN, which wins over detection.
+func (r *Registry) Lookup(driver, dsn string) (Engine, error) {
+	if driver != "" {
+		e, ok := r.byName(driver)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown driver %q", errs.ErrValidation, driver)
+		}
+		return e, nil
+	}
+
+	if scheme, _, ok := strings.Cut(dsn, schemeSeparator); ok {
+		e, ok := r.byName(scheme)
+		if !ok {
+			return nil, fmt.Errorf("%w: unsupported scheme %q", errs.ErrValidation, scheme)
+		}
+		return e, nil
+	}
+
+	for _, e := range r.engines {
+		if e.Detect(dsn) {
+			return e, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unsupported database type", errs.ErrValidation)
+}
+
+func (r *Registry) byName(name string) (Engine, bool) {
+	name = strings.ToLower(name)
+	for _, e := range r.engines {
+		if slices.Contains(names(e), name) {
+			return e, true
+		}
+	}
+	return nil, false
+}
+
+func names(e Engine) []string {
+	return append([]string{e.Driver()}, e.Schemes()...)
+}
+
+// trimScheme returns the DSN without its URL scheme.
+func trimScheme(dsn string) string {
+	if _, rest, ok := strings.Cut(dsn, schemeSeparator); ok {
+		return rest
+	}
+	return dsn
+}
@@ -0,0 +1,112 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestRegistryLookup(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		driver  string
+		dsn     string
+		want    string
+		wantErr bool
+	}{
+		{
+			name: "Should find engine by scheme",
+			dsn:  "postgresql://localhost/db",
+			want: postgresqlDB,
+		},
+		{
+			name: "Should match scheme regardless of case",
+			dsn:  "MySQL://root@tcp(localhost)/db",
+			want: mysqlDB,
+		},
+		{
+			name: "Should detect sqlite file",
+			dsn:  "./data/app.sqlite3",
+			want: sqliteDB,
+		},
+		{
+			name: "Should detect duckdb file",
+			dsn:  "./data/analytics.duckdb",
+			want: duckdbDB,
+		},
+		{
+			name: "Should find duckdb by scheme",
+			dsn:  "duckdb://",
+			want: duckdbDB,
+		},
+		{
+			name: "Should detect flat files",
+			dsn:  "./exports/users.csv,./exports/events.jsonl",
+			want: filesDB,
+		},
+		{
+			name: "Should find flat files by scheme",
+			dsn:  "file://./exports/users.tsv",
+			want: filesDB,
+		},
+		{
+			name: "Should detect mysql database named like a file",
+			dsn:  "root@tcp(localhost)/app.db",
+			want: mysqlDB,
+		},
+		{
+			name: "Should detect postgres key value dsn",
+			dsn:  "host=localhost dbname=app",
+			want: postgresqlDB,
+		},
+		{
+			name:   "Should prefer driver over scheme",
+			driver: "postgres",
+			dsn:    "mysql://root@tcp(localhost)/db",
+			want:   postgresqlDB,
+		},
+		{
+			name:    "Should reject unknown scheme",
+			dsn:     "oracle://localhost/db",
+			wantErr: true,
+		},
+		{
+			name:    "Should reject unknown dsn",
+			dsn:     "unknown",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := DefaultRegistry().Lookup(tt.driver, tt.dsn)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got.Driver())
+		})
+	}
+}
+
+func TestRegistryRegister(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	_, err := NewRegistry(postgreSQLEngine{}, sqliteEngine{}, postgreSQLEngine{})
+	require.ErrorIs(t, err, errs.ErrValidation)
+
+	r, err := NewRegistry(sqliteEngine{})
+	require.NoError(t, err)
+
+	_, err = r.Lookup("", "postgres://localhost/db")
+	require.ErrorIs(t, err, errs.ErrValidation)
+}
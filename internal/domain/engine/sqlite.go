@@ -4,13 +4,57 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"github.com/jmoiron/sqlx"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
 )
 
+var sqliteExtensions = []string{".db", ".sqlite", ".sqlite3"}
+
+// sqliteEngine opens SQLite files, optionally prefixed with the sqlite://
+// scheme.
+type sqliteEngine struct{}
+
+func (sqliteEngine) Driver() string {
+	return sqliteDB
+}
+
+func (sqliteEngine) Schemes() []string {
+	return []string{"sqlite"}
+}
+
+// Detect looks at the extension of the file, leaving out MySQL DSNs whose
+// database name happens to have one.
+func (sqliteEngine) Detect(dsn string) bool {
+	if strings.Contains(dsn, "@") {
+		return false
+	}
+
+	path, _, _ := strings.Cut(strings.ToLower(dsn), "?")
+	return path == ":memory:" || slices.ContainsFunc(sqliteExtensions, func(ext string) bool {
+		return strings.HasSuffix(path, ext)
+	})
+}
+
+func (sqliteEngine) Parse(dsn string) (Target, error) {
+	path := trimScheme(dsn)
+	if path == "" {
+		return Target{}, fmt.Errorf("%w: file is required", errs.ErrValidation)
+	}
+	return Target{DSN: path, Database: path}, nil
+}
+
+func (sqliteEngine) Explorer(_ context.Context, db *sqlx.DB, target Target) (Explorer, error) {
+	return &sqlite{db: db, dbPath: target.DSN}, nil
+}
+
 type sqlite struct {
-	db     *sqlx.DB
-	dbPath string
+	db       *sqlx.DB
+	dbPath   string
+	readOnly bool
 }
 
 type sqliteTable struct {
@@ -18,11 +62,113 @@ type sqliteTable struct {
 	Type string `db:"type"`
 }
 
-func (e *sqlite) Execute(ctx context.Context, query string) error {
-	if _, err := e.db.ExecContext(ctx, query); err != nil {
-		return fmt.Errorf("execute command %q: %w", query, err)
+func (e *sqlite) Execute(ctx context.Context, query string) (int64, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return 0, err
+	}
+
+	var affected int64
+	err := e.run(ctx, func(q queryer) error {
+		var err error
+		affected, err = execute(ctx, q, query)
+		return err
+	})
+	return affected, err
+}
+
+// Explain ignores analyze, as SQLite can't tell how long a step took.
+func (e *sqlite) Explain(ctx context.Context, query string, _ bool) ([]PlanNode, error) {
+	var rows []sqlitePlanRow
+	if err := e.db.SelectContext(ctx, &rows, "EXPLAIN QUERY PLAN "+query); err != nil {
+		return nil, fmt.Errorf("explain query %q: %w", query, err)
+	}
+
+	return buildSQLitePlan(rows), nil
+}
+
+func (e *sqlite) GetActivity(context.Context) ([]Session, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *sqlite) GetLocks(context.Context) ([]Lock, error) {
+	return nil, ErrNotSupported
+}
+
+func (e *sqlite) CancelQuery(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *sqlite) TerminateSession(context.Context, int64) error {
+	return ErrNotSupported
+}
+
+func (e *sqlite) GetPrimaryKey(ctx context.Context, table string) ([]Column, error) {
+	const query = "SELECT name FROM pragma_table_info(?) WHERE pk > 0 ORDER BY pk"
+	var key []Column
+	if err := e.db.SelectContext(ctx, &key, query, table); err != nil {
+		return nil, fmt.Errorf("get primary key of %q: %w", table, err)
+	}
+	return key, nil
+}
+
+func (e *sqlite) ReadOnly() Explorer {
+	ro := *e
+	ro.readOnly = true
+	return &ro
+}
+
+// run calls fn with the database, or with a connection refusing to write
+// when the explorer is read-only, as SQLite ignores read-only
+// transactions.
+func (e *sqlite) run(ctx context.Context, fn func(q queryer) error) error {
+	if !e.readOnly {
+		return fn(e.db)
+	}
+
+	conn, err := e.db.Connx(ctx)
+	if err != nil {
+		return fmt.Errorf("get connection: %w", err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, "PRAGMA query_only = ON"); err != nil {
+		return fmt.Errorf("make connection read-only: %w", err)
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.Background(), "PRAGMA query_only = OFF"); err != nil {
+			slog.Error("Make connection writable", slog.Any("err", err))
+		}
+	}()
+
+	return fn(conn)
+}
+
+func (e *sqlite) ServerInfo(ctx context.Context) (ServerInfo, error) {
+	var version string
+	if err := e.db.GetContext(ctx, &version, "SELECT sqlite_version()"); err != nil {
+		return ServerInfo{}, fmt.Errorf("get server version: %w", err)
+	}
+	return ServerInfo{Engine: "SQLite", Version: version}, nil
+}
+
+func (e *sqlite) Query(ctx context.Context, query string) ([]Row, []Column, error) {
+	if err := checkReadOnly(e.readOnly, query); err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		rows []Row
+		cols []Column
+	)
+	err := e.run(ctx, func(q queryer) error {
+		var err error
+		rows, cols, err = queryRows(ctx, q, query)
+		return err
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("run query %q: %w", query, err)
 	}
-	return nil
+	return rows, cols, nil
 }
 
 func (e *sqlite) GetTables(ctx context.Context) ([]Table, error) {
@@ -51,6 +197,59 @@ func (e *sqlite) toTables(tables []sqliteTable) []Table {
 	return result
 }
 
+type sqliteTableSize struct {
+	Name      string `db:"name"`
+	Size      int64  `db:"size"`
+	IndexSize int64  `db:"index_size"`
+}
+
+// GetTableStats counts rows of every table, as SQLite keeps no estimate.
+// Sizes are read from the dbstat table, which is left out of most builds,
+// so they stay zero when it is missing.
+func (e *sqlite) GetTableStats(ctx context.Context) (map[string]TableStats, error) {
+	tables, err := e.GetTables(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get tables: %w", err)
+	}
+
+	stats := make(map[string]TableStats, len(tables))
+	for _, t := range tables {
+		var rows int64
+		query := fmt.Sprintf("SELECT COUNT(*) FROM %q", t.Name)
+		if err := e.db.GetContext(ctx, &rows, query); err != nil {
+			return nil, fmt.Errorf("count rows of %q: %w", t.Name, err)
+		}
+		stats[t.Name] = TableStats{Rows: rows}
+	}
+
+	const sizeQuery = `
+		SELECT m.tbl_name AS name,
+			SUM(CASE WHEN m.type = 'table' THEN s.pgsize ELSE 0 END) AS size,
+			SUM(CASE WHEN m.type = 'index' THEN s.pgsize ELSE 0 END) AS index_size
+		FROM dbstat s
+		JOIN sqlite_master m ON m.name = s.name
+		GROUP BY m.tbl_name
+	`
+
+	var sizes []sqliteTableSize
+	if err := e.db.SelectContext(ctx, &sizes, sizeQuery); err != nil {
+		slog.Debug("Table sizes are not available", slog.Any("err", err))
+		return stats, nil
+	}
+
+	for _, size := range sizes {
+		s, ok := stats[size.Name]
+		if !ok {
+			continue
+		}
+		s.Size = size.Size
+		s.IndexSize = size.IndexSize
+		stats[size.Name] = s
+	}
+
+	return stats, nil
+}
+
 func (e *sqlite) GetColumns(ctx context.Context, table string) ([]Row, []Column, error) {
 	query := fmt.Sprintf("PRAGMA table_info('%s')", table)
 	if _, _, err := e.GetRows(ctx, table); err != nil {
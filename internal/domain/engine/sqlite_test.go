@@ -21,9 +21,10 @@ func Test_sqlite_Execute(t *testing.T) {
 		query string
 	}
 	tests := []struct {
-		name    string
-		args    args
-		wantErr bool
+		name         string
+		args         args
+		wantAffected int64
+		wantErr      bool
 	}{
 		{
 			name: "Should execute query",
@@ -31,7 +32,8 @@ func Test_sqlite_Execute(t *testing.T) {
 				ctx:   t.Context(),
 				query: "DELETE FROM users",
 			},
-			wantErr: false,
+			wantAffected: 3,
+			wantErr:      false,
 		},
 		{
 			name: "Should return err if query is invalid",
@@ -53,13 +55,14 @@ func Test_sqlite_Execute(t *testing.T) {
 				dbPath: dbName,
 			}
 
-			err := e.Execute(tt.args.ctx, tt.args.query)
+			gotAffected, err := e.Execute(tt.args.ctx, tt.args.query)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
 			}
 
 			require.NoError(t, err)
+			require.Equal(t, tt.wantAffected, gotAffected)
 		})
 	}
 }
@@ -241,6 +244,281 @@ func Test_sqlite_GetRows(t *testing.T) {
 	}
 }
 
+func Test_sqlite_Query(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	type args struct {
+		ctx   context.Context
+		query string
+	}
+	tests := []struct {
+		name        string
+		args        args
+		wantRows    []Row
+		wantColumns []Column
+		wantErr     bool
+	}{
+		{
+			name: "Should return rows of the query",
+			args: args{
+				ctx:   t.Context(),
+				query: "SELECT id, name FROM users WHERE id > 1 ORDER BY id",
+			},
+			wantRows: []Row{
+				{"2", "Jane Smith"},
+				{"3", "Bob Wilson"},
+			},
+			wantColumns: []Column{"id", "name"},
+		},
+		{
+			name: "Should return columns when query matches nothing",
+			args: args{
+				ctx:   t.Context(),
+				query: "SELECT id FROM users WHERE id > 100",
+			},
+			wantRows:    []Row{},
+			wantColumns: []Column{"id"},
+		},
+		{
+			name: "Should not return rows for invalid query",
+			args: args{
+				ctx:   t.Context(),
+				query: "SELECT * FROM unknown",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := seedSQLite(t)
+			t.Cleanup(cleanup)
+
+			e := &sqlite{
+				db:     db,
+				dbPath: dbName,
+			}
+
+			gotRows, gotColumns, err := e.Query(tt.args.ctx, tt.args.query)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantRows, gotRows)
+			require.Equal(t, tt.wantColumns, gotColumns)
+		})
+	}
+}
+
+func Test_sqlite_GetPrimaryKey(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	tests := []struct {
+		name    string
+		table   string
+		want    []Column
+		wantErr bool
+	}{
+		{
+			name:  "Should get primary key",
+			table: tableName,
+			want:  []Column{"id"},
+		},
+		{
+			name:  "Should get no columns for unknown table",
+			table: "unknown",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := seedSQLite(t)
+			t.Cleanup(cleanup)
+
+			e := &sqlite{
+				db:     db,
+				dbPath: dbName,
+			}
+
+			got, err := e.GetPrimaryKey(t.Context(), tt.table)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func Test_sqlite_ServerInfo(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedSQLite(t)
+	t.Cleanup(cleanup)
+
+	e := &sqlite{
+		db:     db,
+		dbPath: dbName,
+	}
+
+	got, err := e.ServerInfo(t.Context())
+	require.NoError(t, err)
+	require.Equal(t, "SQLite", got.Engine)
+	require.NotEmpty(t, got.Version)
+}
+
+func Test_sqlite_Explain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	tests := []struct {
+		name     string
+		query    string
+		wantScan bool
+		wantErr  bool
+	}{
+		{
+			name:     "Should explain full scan",
+			query:    "SELECT * FROM users WHERE name = 'a'",
+			wantScan: true,
+		},
+		{
+			name:  "Should explain primary key lookup",
+			query: "SELECT * FROM users WHERE id = 1",
+		},
+		{
+			name:    "Should return err if query is invalid",
+			query:   "SELECT * FROM unknown",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := seedSQLite(t)
+			t.Cleanup(cleanup)
+
+			e := &sqlite{
+				db:     db,
+				dbPath: dbName,
+			}
+
+			got, err := e.Explain(t.Context(), tt.query, false)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, got, 1)
+			require.Equal(t, "users", got[0].Relation)
+			require.Equal(t, tt.wantScan, got[0].FullScan)
+		})
+	}
+}
+
+func Test_sqlite_GetTableStats(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedSQLite(t)
+	t.Cleanup(cleanup)
+
+	e := &sqlite{
+		db:     db,
+		dbPath: dbName,
+	}
+
+	got, err := e.GetTableStats(t.Context())
+	require.NoError(t, err)
+	require.Contains(t, got, "users")
+	require.Equal(t, int64(3), got["users"].Rows)
+	require.Zero(t, got["users"].AutoIncrement)
+}
+
+func Test_sqlite_GetActivity(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedSQLite(t)
+	t.Cleanup(cleanup)
+
+	e := &sqlite{
+		db:     db,
+		dbPath: dbName,
+	}
+
+	_, err := e.GetActivity(t.Context())
+	require.ErrorIs(t, err, ErrNotSupported)
+	require.ErrorIs(t, e.CancelQuery(t.Context(), 1), ErrNotSupported)
+	require.ErrorIs(t, e.TerminateSession(t.Context(), 1), ErrNotSupported)
+}
+
+func Test_sqlite_GetLocks(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	db, cleanup := seedSQLite(t)
+	t.Cleanup(cleanup)
+
+	e := &sqlite{
+		db:     db,
+		dbPath: dbName,
+	}
+
+	_, err := e.GetLocks(t.Context())
+	require.ErrorIs(t, err, ErrNotSupported)
+}
+
+func Test_sqlite_ReadOnly(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	tests := []struct {
+		name     string
+		query    string
+		wantRows int
+		wantErr  error
+	}{
+		{
+			name:     "Should run select",
+			query:    "SELECT * FROM users",
+			wantRows: 3,
+		},
+		{
+			name:     "Should run CTE",
+			query:    "WITH u AS (SELECT * FROM users WHERE id = 1) SELECT * FROM u",
+			wantRows: 1,
+		},
+		{
+			name:    "Should reject delete",
+			query:   "DELETE FROM users",
+			wantErr: ErrReadOnly,
+		},
+		{
+			name:    "Should reject write after select",
+			query:   "SELECT 1; DROP TABLE users",
+			wantErr: ErrReadOnly,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, cleanup := seedSQLite(t)
+			t.Cleanup(cleanup)
+
+			e := &sqlite{
+				db:     db,
+				dbPath: dbName,
+			}
+
+			rows, _, err := e.ReadOnly().Query(t.Context(), tt.query)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Len(t, rows, tt.wantRows)
+
+			affected, err := e.Execute(t.Context(), "DELETE FROM users")
+			require.NoError(t, err, "Connection should be writable again")
+			require.Equal(t, int64(3), affected)
+		})
+	}
+}
+
 func Test_sqlite_GetConstraints(t *testing.T) {
 	xtest.SkipUnitIfRequired(t)
 	type args struct {
@@ -0,0 +1,162 @@
+package engine
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var (
+	lineComment  = regexp.MustCompile(`--[^\n]*`)
+	blockComment = regexp.MustCompile(`(?s)/\*.*?\*/`)
+	returning    = regexp.MustCompile(`(?i)\bRETURNING\b`)
+)
+
+var rowsKeywords = []string{
+	"SELECT", "WITH", "SHOW", "EXPLAIN", "PRAGMA",
+	"DESCRIBE", "DESC", "VALUES", "TABLE",
+}
+
+// ReturnsRows guesses whether the statement produces a result set, so it
+// should be run with Query rather than Execute to keep its rows.
+func ReturnsRows(query string) bool {
+	keyword := firstKeyword(query)
+	if slices.Contains(rowsKeywords, keyword) {
+		return true
+	}
+
+	return returning.MatchString(stripComments(query))
+}
+
+func firstKeyword(query string) string {
+	query = strings.TrimLeft(stripComments(query), " \t\r\n(")
+	if i := strings.IndexAny(query, " \t\r\n(;"); i != -1 {
+		query = query[:i]
+	}
+	return strings.ToUpper(query)
+}
+
+func stripComments(query string) string {
+	query = blockComment.ReplaceAllString(query, " ")
+	return lineComment.ReplaceAllString(query, " ")
+}
+
+var (
+	readKeywords = []string{"SELECT", "WITH", "SHOW", "EXPLAIN", "DESCRIBE", "DESC", "VALUES", "TABLE"}
+	// clauseKeywords come before a DELETE or UPDATE which is no statement
+	// of its own: the update of an upsert, the lock of a select, or the
+	// action of a foreign key or a column, as in ON DELETE CASCADE.
+	clauseKeywords = []string{"FOR", "DO", "KEY", "ON"}
+
+	stringLiteral = regexp.MustCompile(`'(?:[^']|'')*'|"(?:[^"]|"")*"`)
+	where         = regexp.MustCompile(`(?i)\bWHERE\b`)
+	analyze       = regexp.MustCompile(`(?i)\bANALY[SZ]E\b`)
+	writeKeyword  = regexp.MustCompile(
+		`(?i)\b(INSERT|UPDATE|DELETE|MERGE|UPSERT|CREATE|ALTER|DROP|TRUNCATE|GRANT|REVOKE|RENAME)\b`,
+	)
+	dropKeyword  = regexp.MustCompile(`(?i)\b(DROP|TRUNCATE)\b`)
+	whereKeyword = regexp.MustCompile(`(?i)\b(DELETE|UPDATE)\b`)
+)
+
+// IsReadOnly tells whether none of the statements in the query write,
+// anywhere in them, CTEs and EXPLAIN ANALYZE included. It only looks at
+// the statements' keywords, so read-only contexts also run queries in
+// read-only transactions to catch what it lets through.
+func IsReadOnly(query string) bool {
+	for _, stmt := range splitStatements(query) {
+		if !slices.Contains(readKeywords, firstKeyword(stmt)) {
+			return false
+		}
+
+		if planOnly(stmt) {
+			continue
+		}
+
+		if returning.MatchString(stmt) || writeKeyword.MatchString(stmt) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsDangerous tells whether any of the statements in the query drops
+// data in bulk: DROP, TRUNCATE, or DELETE and UPDATE without WHERE of
+// their own, wherever they are in the statement.
+func IsDangerous(query string) bool {
+	for _, stmt := range splitStatements(query) {
+		if planOnly(stmt) {
+			continue
+		}
+
+		if dropKeyword.MatchString(stmt) {
+			return true
+		}
+
+		for _, loc := range whereKeyword.FindAllStringIndex(stmt, -1) {
+			if slices.Contains(clauseKeywords, lastKeyword(stmt[:loc[0]])) {
+				continue
+			}
+
+			if !where.MatchString(clause(stmt[loc[1]:])) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// planOnly tells whether the statement only explains another one,
+// without running it as EXPLAIN ANALYZE does.
+func planOnly(stmt string) bool {
+	return firstKeyword(stmt) == "EXPLAIN" && !analyze.MatchString(stmt)
+}
+
+func lastKeyword(query string) string {
+	fields := strings.Fields(query)
+	if len(fields) == 0 {
+		return ""
+	}
+	return strings.ToUpper(fields[len(fields)-1])
+}
+
+// clause returns the query up to the parenthesis closing the one it is
+// in, such as the end of a CTE, leaving out what is nested in it, such
+// as subqueries.
+func clause(query string) string {
+	var (
+		b     strings.Builder
+		depth int
+	)
+
+	for _, r := range query {
+		switch {
+		case r == '(':
+			depth++
+		case r == ')' && depth == 0:
+			return b.String()
+		case r == ')':
+			depth--
+		case depth == 0:
+			b.WriteRune(r)
+		}
+	}
+
+	return b.String()
+}
+
+// splitStatements splits the query on semicolons, leaving out comments,
+// string literals and empty statements.
+func splitStatements(query string) []string {
+	query = stringLiteral.ReplaceAllString(stripComments(query), "''")
+
+	var stmts []string
+	for _, stmt := range strings.Split(query, ";") {
+		if strings.TrimSpace(stmt) != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+
+	return stmts
+}
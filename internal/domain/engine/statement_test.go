@@ -0,0 +1,244 @@
+package engine
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestReturnsRows(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{
+			name:  "Should return rows for select",
+			query: "select * from users",
+			want:  true,
+		},
+		{
+			name:  "Should return rows for CTE",
+			query: "WITH u AS (SELECT 1) SELECT * FROM u",
+			want:  true,
+		},
+		{
+			name:  "Should return rows for parenthesised select",
+			query: "(SELECT 1) UNION (SELECT 2)",
+			want:  true,
+		},
+		{
+			name:  "Should skip leading comments",
+			query: "-- users\n/* all of them */ SELECT * FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should return rows for insert with returning",
+			query: "INSERT INTO users (name) VALUES ('a') RETURNING id",
+			want:  true,
+		},
+		{
+			name:  "Should not return rows for update",
+			query: "UPDATE users SET name = 'a'",
+		},
+		{
+			name:  "Should not treat commented out returning as one",
+			query: "DELETE FROM users -- RETURNING id",
+		},
+		{
+			name:  "Should not return rows for DDL",
+			query: "CREATE TABLE t (id int)",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, ReturnsRows(tt.query))
+		})
+	}
+}
+
+func TestIsReadOnly(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{
+			name:  "Should treat select as read-only",
+			query: "SELECT * FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should treat explain as read-only",
+			query: "EXPLAIN SELECT * FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should ignore semicolons in strings",
+			query: "SELECT * FROM users WHERE name = 'a; DROP TABLE users'",
+			want:  true,
+		},
+		{
+			name:  "Should not treat insert as read-only",
+			query: "INSERT INTO users (name) VALUES ('a')",
+		},
+		{
+			name:  "Should not treat DDL as read-only",
+			query: "CREATE TABLE t (id int)",
+		},
+		{
+			name:  "Should check every statement",
+			query: "SELECT 1; DELETE FROM users",
+		},
+		{
+			name:  "Should not treat pragma as read-only",
+			query: "PRAGMA user_version = 1",
+		},
+		{
+			name:  "Should not treat CTE which deletes as read-only",
+			query: "WITH d AS (DELETE FROM t) SELECT 1",
+		},
+		{
+			name:  "Should not treat explain analyze of delete as read-only",
+			query: "EXPLAIN ANALYZE DELETE FROM users",
+		},
+		{
+			name:  "Should not treat explain with analyze option of update as read-only",
+			query: "EXPLAIN (ANALYZE, FORMAT JSON) UPDATE users SET name = 'a' WHERE id = 1",
+		},
+		{
+			name:  "Should treat explain of delete as read-only",
+			query: "EXPLAIN DELETE FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should not be fooled by keywords in strings and names",
+			query: "SELECT created_at, 'DELETE' FROM users",
+			want:  true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, IsReadOnly(tt.query))
+		})
+	}
+}
+
+func TestIsDangerous(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name  string
+		query string
+		want  bool
+	}{
+		{
+			name:  "Should flag delete without where",
+			query: "DELETE FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should flag update without where",
+			query: "update users set name = 'a'",
+			want:  true,
+		},
+		{
+			name:  "Should flag drop",
+			query: "DROP TABLE users",
+			want:  true,
+		},
+		{
+			name:  "Should flag truncate on a new line",
+			query: "TRUNCATE\nusers",
+			want:  true,
+		},
+		{
+			name:  "Should flag any of the statements",
+			query: "SELECT 1; DELETE FROM users;",
+			want:  true,
+		},
+		{
+			name:  "Should not be fooled by where in a string",
+			query: "UPDATE users SET name = 'where'",
+			want:  true,
+		},
+		{
+			name:  "Should not be fooled by where in a comment",
+			query: "DELETE FROM users -- WHERE id = 1",
+			want:  true,
+		},
+		{
+			name:  "Should flag CTE which deletes without where",
+			query: "WITH d AS (DELETE FROM t) SELECT 1",
+			want:  true,
+		},
+		{
+			name:  "Should flag explain analyze of delete without where",
+			query: "EXPLAIN ANALYZE DELETE FROM users",
+			want:  true,
+		},
+		{
+			name:  "Should not take where of outer query for the CTE's",
+			query: "WITH d AS (DELETE FROM t RETURNING id) SELECT * FROM d WHERE id = 1",
+			want:  true,
+		},
+		{
+			name:  "Should not take where of subquery for the update's",
+			query: "UPDATE users SET name = (SELECT name FROM x WHERE x.id = 1)",
+			want:  true,
+		},
+		{
+			name:  "Should allow CTE which deletes with where",
+			query: "WITH d AS (DELETE FROM t WHERE id = 1) SELECT 1",
+		},
+		{
+			name:  "Should allow update with subquery and where",
+			query: "UPDATE users SET name = (SELECT name FROM x WHERE x.id = users.id) WHERE id IN (SELECT id FROM y)",
+		},
+		{
+			name:  "Should allow referential action",
+			query: "CREATE TABLE t (id int REFERENCES u(id) ON DELETE CASCADE ON UPDATE SET NULL)",
+		},
+		{
+			name:  "Should allow column update action",
+			query: "ALTER TABLE t MODIFY updated_at TIMESTAMP ON UPDATE CURRENT_TIMESTAMP",
+		},
+		{
+			name:  "Should allow explain of delete without where",
+			query: "EXPLAIN DELETE FROM users",
+		},
+		{
+			name:  "Should allow select for update",
+			query: "SELECT * FROM users WHERE id = 1 FOR UPDATE",
+		},
+		{
+			name:  "Should allow upsert",
+			query: "INSERT INTO users (id) VALUES (1) ON CONFLICT (id) DO UPDATE SET name = 'a'",
+		},
+		{
+			name:  "Should allow delete with where",
+			query: "DELETE FROM users WHERE id = 1",
+		},
+		{
+			name:  "Should allow select",
+			query: "SELECT * FROM users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, IsDangerous(tt.query))
+		})
+	}
+}
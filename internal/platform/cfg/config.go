@@ -2,24 +2,34 @@ package cfg
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"slices"
+	"sync"
 	"time"
 
 	"golang.org/x/exp/maps"
 	"gopkg.in/yaml.v3"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 )
 
 const (
-	filename = "config.yaml"
-	dir      = "gowatchsql"
-	filemode = 0o644
+	filename      = "config.yaml"
+	vaultFilename = "vault"
+	dir           = "gowatchsql"
+	filemode      = 0o644
+)
+
+const (
+	DefaultConnectTimeout = time.Second * 5
+	DefaultQueryTimeout   = time.Second * 30
 )
 
 func NewFromFile(base string) (*Config, error) {
@@ -36,14 +46,30 @@ func NewFromFile(base string) (*Config, error) {
 		return nil, fmt.Errorf("open config: %w", err)
 	}
 
-	cfg := Config{file: f, Connections: make(map[string]Connection)}
+	cfg := Config{
+		file:        f,
+		passwords:   make(map[string]string),
+		Connections: make(map[string]Connection),
+	}
 	if err := yaml.NewDecoder(f).Decode(&cfg); err != nil && !errors.Is(err, io.EOF) {
 		return nil, fmt.Errorf("decode config: %w", err)
 	}
 
+	for _, conn := range cfg.Connections {
+		if err := conn.Validate(); err != nil {
+			return nil, fmt.Errorf("connection %q: %w", conn.Name, err)
+		}
+	}
+
 	return &cfg, nil
 }
 
+// VaultPath returns where the vault of the config under the base dir is
+// kept.
+func VaultPath(base string) string {
+	return filepath.Join(base, dir, vaultFilename)
+}
+
 func createDir(base string) error {
 	dirPath := filepath.Join(base, dir)
 	if err := os.MkdirAll(dirPath, 0o755); err != nil {
@@ -52,15 +78,252 @@ func createDir(base string) error {
 	return nil
 }
 
+// Config is safe for concurrent use, as connections are saved from the
+// commands of the UI while it reads them.
 type Config struct {
-	file        *os.File
-	Connections map[string]Connection `yaml:"connections"`
+	mu   sync.RWMutex
+	file *os.File
+	// vault keeps the passwords, nil while locked.
+	vault *secret.Vault
+	// passwords are the ones given while the vault is locked, kept for
+	// the session only, by DSN.
+	passwords   map[string]string
+	Connections map[string]Connection        `yaml:"connections"`
+	Keys        map[string][]string          `yaml:"keys,omitempty"`
+	Theme       string                       `yaml:"theme,omitempty"`
+	Themes      map[string]map[string]string `yaml:"themes,omitempty"`
 }
 
 type Connection struct {
 	Name       string    `yaml:"name"`
 	LastUsedAt time.Time `yaml:"last_used_at"`
 	DSN        string    `yaml:"dsn"`
+	// Password is kept out of the DSN and refers to the secret, see
+	// secret.IsRef.
+	Password string `yaml:"password,omitempty"`
+	// Driver names the engine of the DSN, which is guessed when unset.
+	Driver      string      `yaml:"driver,omitempty"`
+	Timeouts    Timeouts    `yaml:"timeouts,omitempty"`
+	ReadOnly    bool        `yaml:"read_only,omitempty"`
+	Environment Environment `yaml:"environment,omitempty"`
+	Pool        Pool        `yaml:"pool,omitempty"`
+	Session     Session     `yaml:"session,omitempty"`
+	SSH         SSH         `yaml:"ssh,omitempty"`
+	TLS         TLS         `yaml:"tls,omitempty"`
+}
+
+// Validate checks the settings which can only be set in the file.
+func (c Connection) Validate() error {
+	if err := c.Environment.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Pool.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.Session.Validate(); err != nil {
+		return err
+	}
+
+	if err := c.SSH.Validate(); err != nil {
+		return err
+	}
+
+	return c.TLS.Validate()
+}
+
+// Pool limits the connections kept open to the database. Zero values
+// leave the limits of database/sql.
+type Pool struct {
+	MaxOpen     int           `yaml:"max_open,omitempty"`
+	MaxIdle     int           `yaml:"max_idle,omitempty"`
+	MaxLifetime time.Duration `yaml:"max_lifetime,omitempty"`
+}
+
+func (p Pool) Validate() error {
+	if p.MaxOpen < 0 || p.MaxIdle < 0 || p.MaxLifetime < 0 {
+		return fmt.Errorf("%w: pool limits can't be negative", errs.ErrValidation)
+	}
+
+	if p.MaxOpen > 0 && p.MaxIdle > p.MaxOpen {
+		return fmt.Errorf("%w: pool max_idle can't exceed max_open", errs.ErrValidation)
+	}
+
+	return nil
+}
+
+// Session is set up on every new connection to the database. Schema is
+// the search_path in PostgreSQL, a comma separated list of schemas, and
+// the default database in MySQL. Init statements run last, in order.
+type Session struct {
+	StatementTimeout time.Duration `yaml:"statement_timeout,omitempty"`
+	Schema           string        `yaml:"schema,omitempty"`
+	TimeZone         string        `yaml:"time_zone,omitempty"`
+	Init             []string      `yaml:"init,omitempty"`
+}
+
+func (s Session) Validate() error {
+	if s.StatementTimeout < 0 {
+		return fmt.Errorf("%w: session statement_timeout can't be negative", errs.ErrValidation)
+	}
+
+	return nil
+}
+
+// IsZero tells whether nothing has to be set up on new connections.
+func (s Session) IsZero() bool {
+	return s.StatementTimeout == 0 && s.Schema == "" && s.TimeZone == "" && len(s.Init) == 0
+}
+
+// SSH is the bastion the database is reached through. Jump hosts are
+// hopped through in order before reaching Host, and are written as
+// [user@]host[:port], the same as Host. Host keys are checked against
+// KnownHosts, ~/.ssh/known_hosts when unset.
+type SSH struct {
+	Host       string   `yaml:"host,omitempty"`
+	User       string   `yaml:"user,omitempty"`
+	KeyFile    string   `yaml:"key_file,omitempty"`
+	Agent      bool     `yaml:"agent,omitempty"`
+	KnownHosts string   `yaml:"known_hosts,omitempty"`
+	Jump       []string `yaml:"jump,omitempty"`
+	// InsecureIgnoreHostKey accepts any host key, which leaves the
+	// tunnel open to a man in the middle.
+	InsecureIgnoreHostKey bool `yaml:"insecure_ignore_host_key,omitempty"`
+}
+
+func (s SSH) Validate() error {
+	if s.IsZero() {
+		return nil
+	}
+
+	if s.Host == "" {
+		return fmt.Errorf("%w: ssh host is required", errs.ErrValidation)
+	}
+
+	if s.KeyFile == "" && !s.Agent {
+		return fmt.Errorf("%w: ssh needs a key_file or the agent", errs.ErrValidation)
+	}
+
+	if slices.Contains(s.Jump, "") {
+		return fmt.Errorf("%w: ssh jump host can't be empty", errs.ErrValidation)
+	}
+
+	return nil
+}
+
+// IsZero tells whether the database is reached directly.
+func (s SSH) IsZero() bool {
+	return s.Host == "" && s.User == "" && s.KeyFile == "" && !s.Agent &&
+		s.KnownHosts == "" && len(s.Jump) == 0 && !s.InsecureIgnoreHostKey
+}
+
+// TLS secures the connection to the database. Files are PEM encoded.
+// The server name is the one the certificate must be issued for, the
+// host of the DSN when unset.
+type TLS struct {
+	Mode       TLSMode `yaml:"mode,omitempty"`
+	CAFile     string  `yaml:"ca_file,omitempty"`
+	CertFile   string  `yaml:"cert_file,omitempty"`
+	KeyFile    string  `yaml:"key_file,omitempty"`
+	ServerName string  `yaml:"server_name,omitempty"`
+}
+
+func (t TLS) Validate() error {
+	if err := t.Mode.Validate(); err != nil {
+		return err
+	}
+
+	if t.Mode == "" && !t.IsZero() {
+		return fmt.Errorf("%w: tls mode is required", errs.ErrValidation)
+	}
+
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return fmt.Errorf("%w: tls cert_file and key_file go together", errs.ErrValidation)
+	}
+
+	return nil
+}
+
+// IsZero tells whether the driver's own TLS defaults apply.
+func (t TLS) IsZero() bool {
+	return t == TLS{}
+}
+
+// TLSMode tells how much of the server is trusted, named after the
+// sslmode of libpq. Prefer falls back to an unencrypted connection when
+// the server doesn't support TLS, require encrypts without checking the
+// certificate, verify-ca checks it is signed by the CA and verify-full
+// also checks it is issued for the server.
+type TLSMode string
+
+const (
+	TLSDisable    TLSMode = "disable"
+	TLSPrefer     TLSMode = "prefer"
+	TLSRequire    TLSMode = "require"
+	TLSVerifyCA   TLSMode = "verify-ca"
+	TLSVerifyFull TLSMode = "verify-full"
+)
+
+// TLSModes lists the modes from the least to the most secure.
+var TLSModes = []TLSMode{TLSDisable, TLSPrefer, TLSRequire, TLSVerifyCA, TLSVerifyFull}
+
+func (m TLSMode) Validate() error {
+	if m == "" || slices.Contains(TLSModes, m) {
+		return nil
+	}
+
+	return fmt.Errorf(
+		"%w: unknown tls mode %q, use %s, %s, %s, %s or %s",
+		errs.ErrValidation, m, TLSDisable, TLSPrefer, TLSRequire, TLSVerifyCA, TLSVerifyFull,
+	)
+}
+
+// Environment tags a connection with the kind of database behind it, so
+// the ones holding production data can be handled with more care.
+type Environment string
+
+const (
+	EnvironmentDev     Environment = "dev"
+	EnvironmentStaging Environment = "staging"
+	EnvironmentProd    Environment = "prod"
+)
+
+func (e Environment) Validate() error {
+	switch e {
+	case "", EnvironmentDev, EnvironmentStaging, EnvironmentProd:
+		return nil
+	default:
+		return fmt.Errorf(
+			"%w: unknown environment %q, use %s, %s or %s",
+			errs.ErrValidation, e, EnvironmentDev, EnvironmentStaging, EnvironmentProd,
+		)
+	}
+}
+
+func (e Environment) IsProd() bool {
+	return e == EnvironmentProd
+}
+
+// Timeouts limit how long to wait for the database of a connection.
+// Zero values fall back to the defaults.
+type Timeouts struct {
+	Connect time.Duration `yaml:"connect,omitempty"`
+	Query   time.Duration `yaml:"query,omitempty"`
+}
+
+// WithDefaults returns the timeouts with zero values replaced by the
+// defaults.
+func (t Timeouts) WithDefaults() Timeouts {
+	if t.Connect <= 0 {
+		t.Connect = DefaultConnectTimeout
+	}
+
+	if t.Query <= 0 {
+		t.Query = DefaultQueryTimeout
+	}
+
+	return t
 }
 
 func (c *Config) AddConnection(ctx context.Context, name, dsn string) error {
@@ -72,17 +335,230 @@ func (c *Config) AddConnection(ctx context.Context, name, dsn string) error {
 		return fmt.Errorf("%w: dsn is required", errs.ErrValidation)
 	}
 
-	c.Connections[dsn] = Connection{Name: name, DSN: dsn, LastUsedAt: time.Now()}
-	return c.Save()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Connections saved with the password in the DSN, before the vault
+	// was unlocked, are kept as they are.
+	password := ""
+	if _, ok := c.Connections[dsn]; !ok {
+		dsn, password = secret.SplitPassword(dsn)
+	}
+
+	// Settings which are only set in the file are kept.
+	conn := c.Connections[dsn]
+	conn.Name = name
+	conn.DSN = dsn
+	conn.LastUsedAt = time.Now()
+
+	if err := c.storePassword(&conn, password); err != nil {
+		return err
+	}
+
+	c.Connections[dsn] = conn
+	return c.save()
+}
+
+// SaveConnection saves the name, the password and the TLS settings of
+// the connection, which can be set in the app, keeping the ones only set
+// in the file. The password is taken out of the DSN, unless given on its
+// own. The saved connection is returned.
+func (c *Config) SaveConnection(_ context.Context, conn Connection) (Connection, error) {
+	return c.saveConnection(conn, c.storePassword)
+}
+
+// ImportConnection saves the connection found outside the config, see
+// SaveConnection. Its password is taken as it is written, even when it
+// looks like a reference, as the source may not be trusted.
+func (c *Config) ImportConnection(_ context.Context, conn Connection) (Connection, error) {
+	return c.saveConnection(conn, c.storeLiteral)
+}
+
+func (c *Config) saveConnection(
+	conn Connection,
+	store func(conn *Connection, password string) error,
+) (Connection, error) {
+	if conn.Name == "" {
+		return Connection{}, fmt.Errorf("%w: name is required", errs.ErrValidation)
+	}
+
+	if conn.DSN == "" {
+		return Connection{}, fmt.Errorf("%w: dsn is required", errs.ErrValidation)
+	}
+
+	if err := conn.TLS.Validate(); err != nil {
+		return Connection{}, err
+	}
+
+	dsn, password := secret.SplitPassword(conn.DSN)
+	if conn.Password != "" {
+		password = conn.Password
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	saved := c.Connections[dsn]
+	saved.Name = conn.Name
+	saved.DSN = dsn
+	saved.TLS = conn.TLS
+
+	if err := store(&saved, password); err != nil {
+		return Connection{}, err
+	}
+
+	c.Connections[dsn] = saved
+	return saved, c.save()
+}
+
+// Unlock keeps the passwords in the vault from now on, moving the ones
+// written in the file into it.
+func (c *Config) Unlock(vault *secret.Vault) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.vault = vault
+
+	migrated := false
+	for key, conn := range c.Connections {
+		dsn, password := secret.SplitPassword(conn.DSN)
+		if conn.Password != "" && !secret.IsRef(conn.Password) {
+			password = conn.Password
+		}
+
+		if password == "" {
+			continue
+		}
+
+		conn.DSN = dsn
+		if err := c.storePassword(&conn, password); err != nil {
+			return fmt.Errorf("connection %q: %w", conn.Name, err)
+		}
+
+		delete(c.Connections, key)
+		c.Connections[dsn] = conn
+		migrated = true
+	}
+
+	if !migrated {
+		return nil
+	}
+	return c.save()
+}
+
+// HasPlainPasswords tells whether passwords are written in the file,
+// waiting for the vault to be unlocked.
+func (c *Config) HasPlainPasswords() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	for _, conn := range c.Connections {
+		if _, password := secret.SplitPassword(conn.DSN); password != "" && !secret.IsRef(password) {
+			return true
+		}
+
+		if conn.Password != "" && !secret.IsRef(conn.Password) {
+			return true
+		}
+	}
+	return false
+}
+
+// storePassword keeps the password in the vault and refers to it from
+// the connection. References are kept as they are.
+func (c *Config) storePassword(conn *Connection, password string) error {
+	if secret.IsRef(password) {
+		conn.Password = password
+		return nil
+	}
+	return c.storeLiteral(conn, password)
+}
+
+// storeLiteral keeps the password in the vault and refers to it from the
+// connection. While the vault is locked, the password is kept until the
+// app exits.
+func (c *Config) storeLiteral(conn *Connection, password string) error {
+	switch {
+	case password == "":
+		return nil
+	case c.vault == nil:
+		c.passwords[conn.DSN] = password
+		conn.Password = ""
+		return nil
+	}
+
+	name := vaultName(conn.DSN)
+	if err := c.vault.Set(name, password); err != nil {
+		return fmt.Errorf("save password: %w", err)
+	}
+
+	conn.Password = secret.VaultRef(name)
+	return nil
+}
+
+// vaultName names the password of the DSN in the vault.
+func vaultName(dsn string) string {
+	sum := sha256.Sum256([]byte(dsn))
+	return "dsn-" + hex.EncodeToString(sum[:8])
+}
+
+// GetConnection returns the saved connection to the database.
+func (c *Config) GetConnection(_ context.Context, dsn string) (Connection, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.connection(dsn)
+}
+
+func (c *Config) connection(dsn string) (Connection, bool) {
+	if conn, ok := c.Connections[dsn]; ok {
+		return conn, ok
+	}
+
+	dsn, _ = secret.SplitPassword(dsn)
+	conn, ok := c.Connections[dsn]
+	return conn, ok
+}
+
+// GetPassword resolves the password of the connection to the database,
+// which is empty when it has none.
+func (c *Config) GetPassword(ctx context.Context, dsn string) (string, error) {
+	c.mu.RLock()
+	conn, ok := c.connection(dsn)
+	password, vault := c.passwords[dsn], c.vault
+	c.mu.RUnlock()
+
+	if !ok || conn.Password == "" {
+		return password, nil
+	}
+
+	// The password is resolved unlocked, as it may take a command.
+	password, err := secret.Resolve(ctx, conn.Password, vault)
+	if err != nil {
+		return "", fmt.Errorf("resolve password of %q: %w", conn.Name, err)
+	}
+	return password, nil
 }
 
 func (c *Config) DeleteConnection(ctx context.Context, dsn string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if name, ok := secret.VaultName(c.Connections[dsn].Password); ok && c.vault != nil {
+		if err := c.vault.Delete(name); err != nil {
+			return fmt.Errorf("delete password: %w", err)
+		}
+	}
+
+	delete(c.passwords, dsn)
 	delete(c.Connections, dsn)
-	return c.Save()
+	return c.save()
 }
 
 func (c *Config) GetConnections(context.Context) []Connection {
+	c.mu.RLock()
 	conns := maps.Values(c.Connections)
+	c.mu.RUnlock()
+
 	slices.SortStableFunc(conns, func(a, b Connection) int {
 		return b.LastUsedAt.Compare(a.LastUsedAt)
 	})
@@ -91,6 +567,12 @@ func (c *Config) GetConnections(context.Context) []Connection {
 }
 
 func (c *Config) Save() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.save()
+}
+
+func (c *Config) save() error {
 	if err := c.file.Truncate(0); err != nil {
 		return fmt.Errorf("truncate config: %w", err)
 	}
@@ -0,0 +1,748 @@
+package cfg
+
+import (
+	"bufio"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
+)
+
+const (
+	postgresPort = "5432"
+	mysqlPort    = "3306"
+	localhost    = "localhost"
+)
+
+// composeFiles are the names Docker Compose looks for, searched in the
+// dir and in its build dir.
+var composeFiles = []string{"compose.yaml", "compose.yml", "docker-compose.yaml", "docker-compose.yml"}
+
+// Found is a connection discovered outside the config, ready to be
+// saved. Source tells where it was found.
+type Found struct {
+	Connection Connection
+	Source     string
+}
+
+// Sources tells where connections are looked for.
+type Sources struct {
+	// Home holds .pgpass, .pg_service.conf and .my.cnf.
+	Home string
+	// Dir holds .env and Docker Compose files.
+	Dir string
+	// Env is the environment as KEY=value pairs, which may also point
+	// to the files of PostgreSQL.
+	Env []string
+}
+
+// DefaultSources looks in the home dir, the working dir and the
+// environment of the app.
+func DefaultSources() (Sources, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return Sources{}, fmt.Errorf("get home dir: %w", err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return Sources{}, fmt.Errorf("get working dir: %w", err)
+	}
+
+	return Sources{Home: home, Dir: wd, Env: os.Environ()}, nil
+}
+
+func (s Sources) lookup(name string) (string, bool) {
+	for _, kv := range slices.Backward(s.Env) {
+		if k, v, ok := strings.Cut(kv, "="); ok && k == name {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+type importer func(Sources) ([]Found, error)
+
+var importers = []importer{
+	importPGPass,
+	importPGServices,
+	importMyCnf,
+	importEnv,
+	importDotEnv,
+	importCompose,
+}
+
+// Discover finds connections in the default sources, see DiscoverIn.
+func (c *Config) Discover(ctx context.Context) ([]Found, error) {
+	sources, err := DefaultSources()
+	if err != nil {
+		return nil, err
+	}
+	return c.DiscoverIn(ctx, sources)
+}
+
+// DiscoverIn finds connections in the sources, leaving out the saved
+// ones and the ones found twice. Sources which fail to be read are
+// reported along with what was found in the others.
+func (c *Config) DiscoverIn(ctx context.Context, sources Sources) ([]Found, error) {
+	var (
+		found []Found
+		errs  error
+		seen  = make(map[string]bool)
+	)
+
+	for _, importer := range importers {
+		conns, err := importer(sources)
+		errs = errors.Join(errs, err)
+
+		for _, f := range conns {
+			if _, saved := c.GetConnection(ctx, f.Connection.DSN); saved || seen[f.Connection.DSN] {
+				continue
+			}
+			seen[f.Connection.DSN] = true
+			found = append(found, f)
+		}
+	}
+
+	return found, errs
+}
+
+// importPGPass reads the password file of PostgreSQL, where each line is
+// hostname:port:database:username:password. Wildcards are replaced by
+// the defaults of libpq.
+func importPGPass(s Sources) ([]Found, error) {
+	file, ok := s.lookup("PGPASSFILE")
+	if !ok {
+		file = filepath.Join(s.Home, ".pgpass")
+	}
+
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var found []Found
+	for _, line := range lines {
+		fields := splitPGPass(line)
+		if len(fields) != 5 {
+			continue
+		}
+
+		host, port, database, user, password := fields[0], fields[1], fields[2], fields[3], fields[4]
+		if host == "*" {
+			host = localhost
+		}
+		if port == "*" {
+			port = postgresPort
+		}
+		if database == "*" {
+			database = ""
+		}
+		if user == "*" {
+			user = ""
+		}
+
+		dsn := postgreSQLURL(map[string]string{"host": host, "port": port, "dbname": database, "user": user})
+		found = append(found, Found{
+			Connection: Connection{Name: nameOf(user, host, database), DSN: dsn, Password: password},
+			Source:     file,
+		})
+	}
+
+	return found, nil
+}
+
+// splitPGPass splits the line on colons, which are escaped with a
+// backslash along with backslashes themselves.
+func splitPGPass(line string) []string {
+	var (
+		fields  []string
+		field   strings.Builder
+		escaped bool
+	)
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			field.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ':':
+			fields = append(fields, field.String())
+			field.Reset()
+		default:
+			field.WriteRune(r)
+		}
+	}
+
+	return append(fields, field.String())
+}
+
+// importPGServices reads the connection service files of PostgreSQL, the
+// one of the user first, then the one of the system.
+func importPGServices(s Sources) ([]Found, error) {
+	files := []string{filepath.Join(s.Home, ".pg_service.conf")}
+	if file, ok := s.lookup("PGSERVICEFILE"); ok {
+		files[0] = file
+	}
+	if dir, ok := s.lookup("PGSYSCONFDIR"); ok {
+		files = append(files, filepath.Join(dir, "pg_service.conf"))
+	}
+
+	var (
+		found []Found
+		errs  error
+	)
+	for _, file := range files {
+		sections, err := readINI(file)
+		if err != nil {
+			errs = errors.Join(errs, err)
+			continue
+		}
+
+		for _, section := range sections {
+			params := section.values
+			password := params["password"]
+			delete(params, "password")
+
+			found = append(found, Found{
+				Connection: Connection{Name: section.name, DSN: postgreSQLURL(params), Password: password},
+				Source:     file + " [" + section.name + "]",
+			})
+		}
+	}
+
+	return found, errs
+}
+
+// postgreSQLURL builds the URL of the libpq parameters. Parameters
+// other than the address, the database and the user are kept in the
+// query, as is the host when it is the dir of a Unix socket.
+func postgreSQLURL(params map[string]string) string {
+	host := cmp.Or(params["host"], localhost)
+	port := cmp.Or(params["port"], postgresPort)
+
+	u := url.URL{Scheme: "postgres", Host: net.JoinHostPort(host, port), Path: "/" + params["dbname"]}
+	query := url.Values{}
+	if strings.HasPrefix(host, "/") {
+		u.Host = ""
+		query.Set("host", host)
+		query.Set("port", port)
+	}
+
+	if params["user"] != "" {
+		u.User = url.User(params["user"])
+	}
+
+	for key, value := range params {
+		switch key {
+		case "host", "port", "dbname", "user":
+		default:
+			query.Set(key, value)
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// importMyCnf reads the options of the MySQL client, the ones of the
+// mysql group overriding the ones of the client group.
+func importMyCnf(s Sources) ([]Found, error) {
+	file := filepath.Join(s.Home, ".my.cnf")
+	sections, err := readINI(file)
+	if err != nil || len(sections) == 0 {
+		return nil, err
+	}
+
+	options := make(map[string]string)
+	for _, group := range []string{"client", "mysql"} {
+		for _, section := range sections {
+			if section.name != group {
+				continue
+			}
+			for key, value := range section.values {
+				options[strings.ReplaceAll(key, "-", "_")] = value
+			}
+		}
+	}
+
+	if options["host"] == "" && options["user"] == "" && options["socket"] == "" {
+		return nil, nil
+	}
+
+	addr := "tcp(" + net.JoinHostPort(cmp.Or(options["host"], localhost), cmp.Or(options["port"], mysqlPort)) + ")"
+	if options["socket"] != "" {
+		addr = "unix(" + options["socket"] + ")"
+	}
+
+	database := cmp.Or(options["database"], options["db"])
+	return []Found{{
+		Connection: Connection{
+			Name:     nameOf(options["user"], cmp.Or(options["host"], localhost), database),
+			DSN:      mysqlDSN(options["user"], addr, database, ""),
+			Password: options["password"],
+		},
+		Source: file,
+	}}, nil
+}
+
+func mysqlDSN(user, addr, database, query string) string {
+	dsn := "mysql://" + user + "@" + addr + "/" + database
+	if query != "" {
+		dsn += "?" + query
+	}
+	return dsn
+}
+
+// importEnv reads the DSNs of the environment, see isDSNVar.
+func importEnv(s Sources) ([]Found, error) {
+	return foundInVars(s.Env, "environment"), nil
+}
+
+// importDotEnv reads the DSNs of the .env file of the dir.
+func importDotEnv(s Sources) ([]Found, error) {
+	file := filepath.Join(s.Dir, ".env")
+	vars, err := readDotEnv(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return foundInVars(vars, file), nil
+}
+
+// readDotEnv returns the variables of the .env file as KEY=value pairs.
+// Missing files have none.
+func readDotEnv(file string) ([]string, error) {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	vars := make([]string, 0, len(lines))
+	for _, line := range lines {
+		key, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if ok {
+			vars = append(vars, strings.TrimSpace(key)+"="+unquote(stripComment(strings.TrimSpace(value))))
+		}
+	}
+	return vars, nil
+}
+
+// isDSNVar tells whether the variable holds a DSN, as DATABASE_URL does
+// for many frameworks.
+func isDSNVar(name string) bool {
+	return name == "DATABASE_URL" || name == "DSN" ||
+		strings.HasSuffix(name, "_DATABASE_URL") ||
+		strings.HasSuffix(name, "_DB_URL") ||
+		strings.HasSuffix(name, "_DSN")
+}
+
+func foundInVars(vars []string, source string) []Found {
+	var found []Found
+	for _, kv := range vars {
+		name, value, ok := strings.Cut(kv, "=")
+		if !ok || !isDSNVar(name) || !strings.Contains(value, "://") {
+			continue
+		}
+
+		dsn, password := secret.SplitPassword(toGoDSN(value))
+		found = append(found, Found{
+			Connection: Connection{Name: name, DSN: dsn, Password: password},
+			Source:     source + " " + name,
+		})
+	}
+	return found
+}
+
+// toGoDSN rewrites MySQL URLs of the mysql://user@host:port/db form,
+// used outside of Go, into the one of the Go driver.
+func toGoDSN(dsn string) string {
+	rest, ok := strings.CutPrefix(dsn, "mysql://")
+	if !ok || strings.Contains(rest, "(") {
+		return dsn
+	}
+
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return dsn
+	}
+
+	host := u.Hostname()
+	port := cmp.Or(u.Port(), mysqlPort)
+	userinfo := u.User.Username()
+	if password, ok := u.User.Password(); ok {
+		userinfo += ":" + password
+	}
+
+	return mysqlDSN(userinfo, "tcp("+net.JoinHostPort(host, port)+")", strings.TrimPrefix(u.Path, "/"), u.RawQuery)
+}
+
+// importCompose reads the services of the Docker Compose files running
+// PostgreSQL, MySQL or MariaDB with a published port, connecting with
+// the credentials their images are set up with. Variables in the files
+// are taken from the environment, then from the .env file next to them.
+func importCompose(s Sources) ([]Found, error) {
+	var (
+		found []Found
+		errs  error
+	)
+
+	for _, dir := range []string{s.Dir, filepath.Join(s.Dir, "build")} {
+		vars, err := readDotEnv(filepath.Join(dir, ".env"))
+		errs = errors.Join(errs, err)
+
+		lookup := func(name string) (string, bool) {
+			if value, ok := s.lookup(name); ok {
+				return value, true
+			}
+			return Sources{Env: vars}.lookup(name)
+		}
+
+		for _, name := range composeFiles {
+			file := filepath.Join(dir, name)
+			services, err := readCompose(file, lookup)
+			if err != nil {
+				errs = errors.Join(errs, err)
+				continue
+			}
+
+			for _, name := range slices.Sorted(maps.Keys(services)) {
+				conn, ok := services[name].connection(name)
+				if ok {
+					found = append(found, Found{Connection: conn, Source: file})
+				}
+			}
+		}
+	}
+
+	return found, errs
+}
+
+type composeFile struct {
+	Services map[string]composeService `yaml:"services"`
+}
+
+type composeService struct {
+	Image       string        `yaml:"image"`
+	Environment composeEnv    `yaml:"environment"`
+	Ports       []composePort `yaml:"ports"`
+}
+
+// composeEnv is written either as a mapping or as a list of KEY=value.
+type composeEnv map[string]string
+
+func (e *composeEnv) UnmarshalYAML(node *yaml.Node) error {
+	*e = make(composeEnv)
+	if node.Kind == yaml.MappingNode {
+		var values map[string]string
+		if err := node.Decode(&values); err != nil {
+			return err
+		}
+		*e = values
+		return nil
+	}
+
+	var list []string
+	if err := node.Decode(&list); err != nil {
+		return err
+	}
+
+	for _, kv := range list {
+		key, value, _ := strings.Cut(kv, "=")
+		(*e)[key] = value
+	}
+	return nil
+}
+
+// composePort is written either as [host_ip:][published:]target[/protocol]
+// or as a mapping.
+type composePort struct {
+	HostIP    string `yaml:"host_ip"`
+	Published string `yaml:"published"`
+	Target    string `yaml:"target"`
+}
+
+func (p *composePort) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		type plain composePort
+		return node.Decode((*plain)(p))
+	}
+
+	var short string
+	if err := node.Decode(&short); err != nil {
+		return err
+	}
+
+	short, _, _ = strings.Cut(short, "/")
+	parts := strings.Split(short, ":")
+	p.Target = parts[len(parts)-1]
+	if len(parts) > 1 {
+		p.Published = parts[len(parts)-2]
+	}
+	if len(parts) > 2 {
+		p.HostIP = strings.Trim(strings.Join(parts[:len(parts)-2], ":"), "[]")
+	}
+	return nil
+}
+
+func (s composeService) connection(name string) (Connection, bool) {
+	image := path.Base(s.Image)
+	image, _, _ = strings.Cut(image, ":")
+	image, _, _ = strings.Cut(image, "@")
+
+	switch {
+	case strings.Contains(image, "postgres") || strings.Contains(image, "postgis"):
+		host, ok := s.published(postgresPort)
+		if !ok {
+			return Connection{}, false
+		}
+
+		host, port, _ := net.SplitHostPort(host)
+		user := cmp.Or(s.Environment["POSTGRES_USER"], "postgres")
+		dsn := postgreSQLURL(map[string]string{
+			"host":   host,
+			"port":   port,
+			"dbname": cmp.Or(s.Environment["POSTGRES_DB"], user),
+			"user":   user,
+		})
+		return Connection{Name: name, DSN: dsn, Password: s.Environment["POSTGRES_PASSWORD"]}, true
+	case strings.Contains(image, "mysql") || strings.Contains(image, "mariadb"):
+		addr, ok := s.published(mysqlPort)
+		if !ok {
+			return Connection{}, false
+		}
+
+		user, password := s.mysqlEnv("USER"), s.mysqlEnv("PASSWORD")
+		if user == "" || password == "" {
+			user, password = "root", s.mysqlEnv("ROOT_PASSWORD")
+		}
+
+		dsn := mysqlDSN(user, "tcp("+addr+")", s.mysqlEnv("DATABASE"), "")
+		return Connection{Name: name, DSN: dsn, Password: password}, true
+	default:
+		return Connection{}, false
+	}
+}
+
+// mysqlEnv reads the variable of the MariaDB image, falling back to the
+// one of the MySQL image, which it takes too.
+func (s composeService) mysqlEnv(name string) string {
+	return cmp.Or(s.Environment["MARIADB_"+name], s.Environment["MYSQL_"+name])
+}
+
+// published returns the address of the host the port of the container
+// is published on.
+func (s composeService) published(target string) (string, bool) {
+	for _, p := range s.Ports {
+		if p.Target != target || p.Published == "" || strings.Contains(p.Published, "-") {
+			continue
+		}
+
+		host := p.HostIP
+		if host == "" || host == "0.0.0.0" || host == "::" {
+			host = localhost
+		}
+		return net.JoinHostPort(host, p.Published), true
+	}
+	return "", false
+}
+
+func readCompose(file string, lookup func(string) (string, bool)) (map[string]composeService, error) {
+	raw, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(raw, &node); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", file, err)
+	}
+	if node.Kind == 0 {
+		return nil, nil
+	}
+
+	interpolateNode(&node, lookup)
+
+	var compose composeFile
+	if err := node.Decode(&compose); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", file, err)
+	}
+	return compose.Services, nil
+}
+
+// interpolateNode substitutes the variables in the values of the node,
+// leaving the keys of mappings as they are, see interpolate.
+func interpolateNode(node *yaml.Node, lookup func(string) (string, bool)) {
+	if node.Kind == yaml.ScalarNode {
+		node.Value = interpolate(node.Value, lookup)
+		return
+	}
+
+	for i, child := range node.Content {
+		if node.Kind == yaml.MappingNode && i%2 == 0 {
+			continue
+		}
+		interpolateNode(child, lookup)
+	}
+}
+
+// interpolate substitutes the variables of the value the way Docker
+// Compose does: $NAME, ${NAME}, ${NAME:-default}, ${NAME-default},
+// ${NAME:+other}, ${NAME+other}, ${NAME:?err} and $$ for a dollar.
+// Anything else is substituted with nothing, so no value of the files
+// ever ends up as a reference to a secret.
+func interpolate(value string, lookup func(string) (string, bool)) string {
+	return os.Expand(value, func(expr string) string {
+		if expr == "$" {
+			return "$"
+		}
+
+		end := strings.IndexFunc(expr, func(r rune) bool {
+			return r != '_' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+		})
+		if end < 0 {
+			end = len(expr)
+		}
+
+		name, op := expr[:end], expr[end:]
+		if name == "" {
+			return ""
+		}
+
+		value, set := lookup(name)
+		switch {
+		case op == "", strings.HasPrefix(op, ":?"), strings.HasPrefix(op, "?"):
+			return value
+		case strings.HasPrefix(op, ":-"):
+			return cmp.Or(value, op[2:])
+		case strings.HasPrefix(op, "-"):
+			if set {
+				return value
+			}
+			return op[1:]
+		case strings.HasPrefix(op, ":+"):
+			if value != "" {
+				return op[2:]
+			}
+			return ""
+		case strings.HasPrefix(op, "+"):
+			if set {
+				return op[1:]
+			}
+			return ""
+		default:
+			return ""
+		}
+	})
+}
+
+type iniSection struct {
+	name   string
+	values map[string]string
+}
+
+// readINI reads the sections of the file in order, the values of each
+// unquoted. Missing files have none.
+func readINI(file string) ([]iniSection, error) {
+	lines, err := readLines(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var sections []iniSection
+	for _, line := range lines {
+		if name, ok := strings.CutPrefix(line, "["); ok {
+			name = strings.TrimSpace(strings.TrimSuffix(name, "]"))
+			sections = append(sections, iniSection{name: name, values: make(map[string]string)})
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok || len(sections) == 0 {
+			continue
+		}
+
+		sections[len(sections)-1].values[strings.TrimSpace(key)] = unquote(stripComment(strings.TrimSpace(value)))
+	}
+
+	return sections, nil
+}
+
+// readLines returns the trimmed lines of the file, leaving out empty
+// ones and comments. Missing files have none.
+func readLines(file string) ([]string, error) {
+	f, err := os.Open(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open %s: %w", file, err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	if err := scanner.Err(); err != nil && !errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("read %s: %w", file, err)
+	}
+	return lines, nil
+}
+
+// stripComment drops the comment after an unquoted value.
+func stripComment(value string) string {
+	if strings.HasPrefix(value, `"`) || strings.HasPrefix(value, "'") {
+		return value
+	}
+
+	if i := strings.Index(value, " #"); i >= 0 {
+		return strings.TrimSpace(value[:i])
+	}
+	return value
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
+
+// nameOf names the connection after the user, the host and the
+// database, leaving out the missing ones.
+func nameOf(user, host, database string) string {
+	name := host
+	if user != "" {
+		name = user + "@" + name
+	}
+	if database != "" {
+		name += "/" + database
+	}
+	return name
+}
@@ -0,0 +1,362 @@
+package db
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	"github.com/go-sql-driver/mysql"
+	"github.com/lib/pq"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+)
+
+const mysqlScheme = "mysql://"
+
+var (
+	// tunnelNetworks numbers the networks registered with the MySQL
+	// driver, which looks dial functions up by the network of the DSN.
+	tunnelNetworks atomic.Int64
+	// tlsConfigs numbers the TLS configs registered with the MySQL
+	// driver, which looks them up by the tls parameter of the DSN.
+	tlsConfigs atomic.Int64
+)
+
+// newConnector returns the connector of the driver, set up with the TLS
+// and SSH settings of the connection.
+func newConnector(driverName, dsn string, conn cfg.Connection) (driver.Connector, error) {
+	var err error
+	if conn.TLS, err = expandTLSFiles(conn.TLS); err != nil {
+		return nil, err
+	}
+
+	switch driverName {
+	case postgresDriver:
+		return newPostgreSQLConnector(dsn, conn)
+	case mysqlDriver:
+		return newMySQLConnector(dsn, conn)
+	}
+
+	if !conn.TLS.IsZero() || !conn.SSH.IsZero() {
+		return nil, fmt.Errorf("%w: %s supports neither tls nor ssh settings", errs.ErrValidation, driverName)
+	}
+
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+	drv := db.Driver()
+	_ = db.Close()
+
+	dc, ok := drv.(driver.DriverContext)
+	if !ok {
+		return dsnConnector{driver: drv, dsn: dsn}, nil
+	}
+
+	return dc.OpenConnector(dsn)
+}
+
+// withPassword puts the password, kept out of the DSN, back into it.
+func withPassword(driverName, dsn, password string) (string, error) {
+	if password == "" {
+		return dsn, nil
+	}
+
+	switch driverName {
+	case postgresDriver:
+		if !strings.Contains(dsn, "://") {
+			return dsn + " password=" + postgreSQLValue(password), nil
+		}
+
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", err
+		}
+		u.User = url.UserPassword(u.User.Username(), password)
+		return u.String(), nil
+	case mysqlDriver:
+		config, err := mysql.ParseDSN(strings.TrimPrefix(dsn, mysqlScheme))
+		if err != nil {
+			return "", err
+		}
+		config.Passwd = password
+		return config.FormatDSN(), nil
+	default:
+		return "", fmt.Errorf("%w: %s takes no password", errs.ErrValidation, driverName)
+	}
+}
+
+func expandTLSFiles(settings cfg.TLS) (cfg.TLS, error) {
+	for _, path := range []*string{&settings.CAFile, &settings.CertFile, &settings.KeyFile} {
+		var err error
+		if *path, err = expandHome(*path); err != nil {
+			return cfg.TLS{}, err
+		}
+	}
+	return settings, nil
+}
+
+// newPostgreSQLConnector translates the TLS settings into the ssl
+// parameters of the DSN, which override the ones written in it. Without
+// either, TLS is preferred as libpq does, rather than required as the
+// driver does.
+func newPostgreSQLConnector(dsn string, conn cfg.Connection) (driver.Connector, error) {
+	if conn.TLS.ServerName != "" {
+		return nil, fmt.Errorf(
+			"%w: postgres checks the certificate against the host, tls server_name is not supported",
+			errs.ErrValidation,
+		)
+	}
+
+	opts := dsn
+	if strings.Contains(dsn, "://") {
+		var err error
+		if opts, err = pq.ParseURL(dsn); err != nil {
+			return nil, err
+		}
+	}
+
+	mode := conn.TLS.Mode
+	if mode == "" && !strings.Contains(opts, "sslmode=") {
+		mode = cfg.TLSPrefer
+	}
+
+	for _, param := range [][2]string{
+		{"sslrootcert", conn.TLS.CAFile},
+		{"sslcert", conn.TLS.CertFile},
+		{"sslkey", conn.TLS.KeyFile},
+	} {
+		if param[1] != "" {
+			opts += " " + param[0] + "=" + postgreSQLValue(param[1])
+		}
+	}
+
+	var closers []func() error
+	var t *tunnel
+	if !conn.SSH.IsZero() {
+		var err error
+		if t, err = newTunnel(conn.SSH); err != nil {
+			return nil, err
+		}
+		closers = append(closers, t.Close)
+	}
+
+	connector := func(mode cfg.TLSMode) (*pq.Connector, error) {
+		withMode := opts
+		if mode != "" {
+			withMode += " sslmode=" + string(mode)
+		}
+
+		c, err := pq.NewConnector(withMode)
+		if err != nil {
+			return nil, err
+		}
+		if t != nil {
+			c.Dialer(t)
+		}
+		return c, nil
+	}
+
+	if mode != cfg.TLSPrefer {
+		c, err := connector(mode)
+		if err != nil {
+			return nil, err
+		}
+		return &closingConnector{Connector: c, closers: closers}, nil
+	}
+
+	secure, err := connector(cfg.TLSRequire)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := connector(cfg.TLSDisable)
+	if err != nil {
+		return nil, err
+	}
+
+	return &closingConnector{Connector: preferConnector{secure: secure, plain: plain}, closers: closers}, nil
+}
+
+// postgreSQLValue quotes the value of a key=value DSN.
+func postgreSQLValue(v string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(v) + "'"
+}
+
+// preferConnector connects with TLS, falling back to a plain connection
+// when the server doesn't support it.
+type preferConnector struct {
+	secure driver.Connector
+	plain  driver.Connector
+}
+
+func (c preferConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.secure.Connect(ctx)
+	if errors.Is(err, pq.ErrSSLNotSupported) {
+		return c.plain.Connect(ctx)
+	}
+	return conn, err
+}
+
+func (c preferConnector) Driver() driver.Driver {
+	return c.secure.Driver()
+}
+
+// newMySQLConnector registers the TLS config and the tunnel with the
+// driver under names of their own, and points the DSN at them. The DSN
+// may start with the mysql scheme, which the driver doesn't take.
+func newMySQLConnector(dsn string, conn cfg.Connection) (driver.Connector, error) {
+	config, err := mysql.ParseDSN(strings.TrimPrefix(dsn, mysqlScheme))
+	if err != nil {
+		return nil, err
+	}
+
+	var tlsConfig *tls.Config
+	if !conn.TLS.IsZero() && conn.TLS.Mode != cfg.TLSDisable {
+		if tlsConfig, err = newTLSConfig(conn.TLS); err != nil {
+			return nil, err
+		}
+	}
+
+	var closers []func() error
+	if !conn.SSH.IsZero() {
+		t, err := newTunnel(conn.SSH)
+		if err != nil {
+			return nil, err
+		}
+
+		network := config.Net
+		config.Net = fmt.Sprintf("ssh-tunnel-%d", tunnelNetworks.Add(1))
+		mysql.RegisterDialContext(config.Net, func(ctx context.Context, addr string) (net.Conn, error) {
+			return t.DialContext(ctx, network, addr)
+		})
+
+		closers = append(closers, func() error {
+			mysql.DeregisterDialContext(config.Net)
+			return t.Close()
+		})
+	}
+
+	connector, err := newMySQLTLSConnector(config, conn.TLS.Mode, tlsConfig)
+	if err != nil {
+		return nil, errors.Join(err, closeAll(closers))
+	}
+
+	return &closingConnector{Connector: connector, closers: closers}, nil
+}
+
+// newMySQLTLSConnector registers the TLS config for as long as the
+// connector takes to copy it.
+func newMySQLTLSConnector(config *mysql.Config, mode cfg.TLSMode, tlsConfig *tls.Config) (driver.Connector, error) {
+	switch {
+	case mode == cfg.TLSDisable:
+		config.TLS, config.TLSConfig = nil, "false"
+	case tlsConfig != nil:
+		name := fmt.Sprintf("gowatchsql-%d", tlsConfigs.Add(1))
+		if err := mysql.RegisterTLSConfig(name, tlsConfig); err != nil {
+			return nil, err
+		}
+		defer mysql.DeregisterTLSConfig(name)
+
+		config.TLS, config.TLSConfig = nil, name
+		config.AllowFallbackToPlaintext = mode == cfg.TLSPrefer
+	}
+
+	return mysql.NewConnector(config)
+}
+
+// newTLSConfig reads the files of the settings and checks as much of the
+// server's certificate as the mode asks for.
+func newTLSConfig(settings cfg.TLS) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName: settings.ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if settings.CAFile != "" {
+		pem, err := os.ReadFile(settings.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls ca: %w", err)
+		}
+
+		config.RootCAs = x509.NewCertPool()
+		if !config.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("%w: no certificates in tls ca %q", errs.ErrValidation, settings.CAFile)
+		}
+	}
+
+	if settings.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(settings.CertFile, settings.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("read tls client certificate: %w", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	switch settings.Mode {
+	case cfg.TLSPrefer, cfg.TLSRequire:
+		config.InsecureSkipVerify = true //nolint:gosec // Encrypting only is what the mode asks for.
+	case cfg.TLSVerifyCA:
+		// Verifying the chain alone isn't offered by crypto/tls.
+		config.InsecureSkipVerify = true //nolint:gosec // The chain is verified below.
+		config.VerifyPeerCertificate = verifyChain(config.RootCAs)
+	}
+
+	return config, nil
+}
+
+// verifyChain checks the certificate of the server is signed by one of
+// the roots, whichever host it is issued for.
+func verifyChain(roots *x509.CertPool) func([][]byte, [][]*x509.Certificate) error {
+	return func(raw [][]byte, _ [][]*x509.Certificate) error {
+		if len(raw) == 0 {
+			return errors.New("server sent no certificate")
+		}
+
+		certs := make([]*x509.Certificate, 0, len(raw))
+		for _, der := range raw {
+			cert, err := x509.ParseCertificate(der)
+			if err != nil {
+				return fmt.Errorf("parse server certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		}
+
+		intermediates := x509.NewCertPool()
+		for _, cert := range certs[1:] {
+			intermediates.AddCert(cert)
+		}
+
+		_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+		return err
+	}
+}
+
+// closingConnector closes what the connector holds on to, such as the
+// tunnel, when database/sql closes the pool.
+type closingConnector struct {
+	driver.Connector
+	closers []func() error
+}
+
+func (c *closingConnector) Close() error {
+	return closeAll(c.closers)
+}
+
+func closeAll(closers []func() error) error {
+	var err error
+	for _, close := range closers {
+		err = errors.Join(err, close())
+	}
+	return err
+}
@@ -2,20 +2,61 @@ package db
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
 	_ "github.com/lib/pq"
+	_ "github.com/marcboeker/go-duckdb"
 	_ "github.com/mattn/go-sqlite3"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
 )
 
+const (
+	reconnectAttempts = 4
+	reconnectDelay    = time.Millisecond * 200
+)
+
+// ErrUnreachable is returned when a cached connection was lost and could
+// not be restored.
+var ErrUnreachable = errors.New("database is unreachable")
+
 //go:generate mockgen -destination=mocks/mock_config_repository.go -package=mocks . ConfigRepository
 type ConfigRepository interface {
 	AddConnection(ctx context.Context, name, dsn string) error
+	GetConnection(ctx context.Context, dsn string) (cfg.Connection, bool)
+	GetPassword(ctx context.Context, dsn string) (string, error)
+}
+
+// State is what happened to a cached connection.
+type State int
+
+const (
+	// Reconnecting means the connection stopped answering pings.
+	Reconnecting State = iota
+	// Reconnected means the connection answers pings again.
+	Reconnected
+	// Disconnected means the connection was given up on and dropped from
+	// the pool, so the next Get opens a new one.
+	Disconnected
+	// Interrupted means the caller stopped waiting for the connection to
+	// answer. It is kept, as others may still use it, and pinged again by
+	// the next Get.
+	Interrupted
+)
+
+// Event tells an observer about a lost connection. Err is the error
+// which caused it, unset for Reconnected.
+type Event struct {
+	Name  string
+	State State
+	Err   error
 }
 
 func NewPool(cfg ConfigRepository) *Pool {
@@ -26,11 +67,24 @@ func NewPool(cfg ConfigRepository) *Pool {
 }
 
 type Pool struct {
-	opened map[string]*sqlx.DB
-	cfg    ConfigRepository
+	mu      sync.Mutex
+	opened  map[string]*sqlx.DB
+	cfg     ConfigRepository
+	observe func(Event)
 }
 
-func (p *Pool) Get(ctx context.Context, name, driver, dsn string) (*sqlx.DB, error) {
+// Observe sets the function told about lost and restored connections.
+// It is called from the goroutine asking for the connection, so it must
+// not block.
+func (p *Pool) Observe(fn func(Event)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.observe = fn
+}
+
+// Get returns the connection of the context saved with the DSN, which
+// keys the connection and its settings, opening it with driverDSN.
+func (p *Pool) Get(ctx context.Context, name, driver, dsn, driverDSN string) (*sqlx.DB, error) {
 	if name == "" {
 		return nil, fmt.Errorf("%w: name is required", errs.ErrValidation)
 	}
@@ -39,24 +93,29 @@ func (p *Pool) Get(ctx context.Context, name, driver, dsn string) (*sqlx.DB, err
 		return nil, fmt.Errorf("%w: driver is required", errs.ErrValidation)
 	}
 
-	if dsn == "" {
+	if dsn == "" || driverDSN == "" {
 		return nil, fmt.Errorf("%w: dsn is required", errs.ErrValidation)
 	}
 
-	if conn, opened := p.opened[dsn]; opened {
+	if conn, opened := p.cached(dsn); opened {
+		if err := conn.PingContext(ctx); err != nil {
+			return p.reconnect(ctx, name, dsn, conn, err)
+		}
 		return conn, nil
 	}
 
-	conn, err := sqlx.ConnectContext(ctx, driver, dsn)
+	settings, _ := p.cfg.GetConnection(ctx, dsn)
+	password, err := p.cfg.GetPassword(ctx, dsn)
 	if err != nil {
 		return nil, err
 	}
 
-	//	if err := conn.Ping(); err != nil {
-	//		return nil, fmt.Errorf("ping: %w", err)
-	//	}
+	conn, err := open(ctx, driver, driverDSN, password, settings)
+	if err != nil {
+		return nil, err
+	}
 
-	p.opened[dsn] = conn
+	conn = p.store(dsn, conn)
 	if err := p.cfg.AddConnection(ctx, name, dsn); err != nil {
 		return nil, fmt.Errorf("save config: %w", err)
 	}
@@ -65,6 +124,9 @@ func (p *Pool) Get(ctx context.Context, name, driver, dsn string) (*sqlx.DB, err
 }
 
 func (p *Pool) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
 	var g errgroup.Group
 	for _, conn := range p.opened {
 		g.Go(conn.Close)
@@ -76,3 +138,92 @@ func (p *Pool) Close() error {
 
 	return nil
 }
+
+// reconnect pings the lost connection until it answers again. The same
+// connection is kept, since database/sql replaces broken sessions on
+// its own and explorers created earlier hold on to it. It is only given
+// up on after every attempt failed, not when the caller stops waiting.
+func (p *Pool) reconnect(
+	ctx context.Context,
+	name, dsn string,
+	conn *sqlx.DB,
+	cause error,
+) (*sqlx.DB, error) {
+	p.notify(Event{Name: name, State: Reconnecting, Err: cause})
+
+	delay := reconnectDelay
+	for range reconnectAttempts {
+		select {
+		case <-ctx.Done():
+			return nil, p.interrupt(ctx, name)
+		case <-time.After(delay):
+		}
+
+		err := conn.PingContext(ctx)
+		if err == nil {
+			p.notify(Event{Name: name, State: Reconnected})
+			return conn, nil
+		}
+
+		if ctx.Err() != nil {
+			return nil, p.interrupt(ctx, name)
+		}
+
+		cause = err
+		delay *= 2
+	}
+
+	return nil, p.disconnect(name, dsn, conn, cause)
+}
+
+func (p *Pool) interrupt(ctx context.Context, name string) error {
+	p.notify(Event{Name: name, State: Interrupted, Err: ctx.Err()})
+	return fmt.Errorf("reconnect: %w", ctx.Err())
+}
+
+func (p *Pool) disconnect(name, dsn string, conn *sqlx.DB, cause error) error {
+	p.mu.Lock()
+	if p.opened[dsn] == conn {
+		delete(p.opened, dsn)
+	}
+	p.mu.Unlock()
+
+	if err := conn.Close(); err != nil {
+		cause = errors.Join(cause, err)
+	}
+
+	p.notify(Event{Name: name, State: Disconnected, Err: cause})
+	return fmt.Errorf("%w: %w", ErrUnreachable, cause)
+}
+
+func (p *Pool) cached(dsn string) (*sqlx.DB, bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	conn, ok := p.opened[dsn]
+	return conn, ok
+}
+
+// store caches the connection unless another one to the same database
+// was opened meanwhile, in which case that one is returned instead.
+func (p *Pool) store(dsn string, conn *sqlx.DB) *sqlx.DB {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if existing, ok := p.opened[dsn]; ok {
+		_ = conn.Close()
+		return existing
+	}
+
+	p.opened[dsn] = conn
+	return conn
+}
+
+func (p *Pool) notify(e Event) {
+	p.mu.Lock()
+	observe := p.observe
+	p.mu.Unlock()
+
+	if observe != nil {
+		observe(e)
+	}
+}
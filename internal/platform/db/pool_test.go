@@ -2,7 +2,15 @@ package db
 
 import (
 	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	_ "github.com/go-sql-driver/mysql"
 	"github.com/jmoiron/sqlx"
@@ -84,6 +92,9 @@ func TestPool_Close(t *testing.T) {
 func TestPool_Get(t *testing.T) {
 	xtest.SkipUnitIfRequired(t)
 	t.Parallel()
+	cached := sqlx.MustOpen("sqlite3", ":memory:")
+	t.Cleanup(func() { cached.Close() })
+
 	type fields struct {
 		opened map[string]*sqlx.DB
 		cfg    ConfigRepository
@@ -105,7 +116,7 @@ func TestPool_Get(t *testing.T) {
 			name: "Should return cached connection",
 			fields: fields{
 				opened: map[string]*sqlx.DB{
-					"dsn": {},
+					"dsn": cached,
 				},
 			},
 			args: args{
@@ -114,7 +125,7 @@ func TestPool_Get(t *testing.T) {
 				dsn:    "dsn",
 				driver: "driver",
 			},
-			want: &sqlx.DB{},
+			want: cached,
 		},
 		{
 			name:   "Should return an error when name is empty",
@@ -158,7 +169,7 @@ func TestPool_Get(t *testing.T) {
 				opened: tt.fields.opened,
 			}
 
-			got, err := p.Get(tt.args.ctx, tt.args.name, tt.args.driver, tt.args.dsn)
+			got, err := p.Get(tt.args.ctx, tt.args.name, tt.args.driver, tt.args.dsn, tt.args.dsn)
 			if tt.wantErr {
 				require.Error(t, err)
 				return
@@ -169,3 +180,112 @@ func TestPool_Get(t *testing.T) {
 		})
 	}
 }
+
+func TestPool_GetReconnects(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name      string
+		fails     int32
+		timeout   time.Duration
+		want      []State
+		wantErr   error
+		wantCache bool
+	}{
+		{
+			name:      "Should return connection once it answers again",
+			fails:     2,
+			timeout:   time.Second * 5,
+			want:      []State{Reconnecting, Reconnected},
+			wantCache: true,
+		},
+		{
+			name:    "Should drop connection which doesn't answer to any attempt",
+			fails:   math.MaxInt32,
+			timeout: time.Second * 10,
+			want:    []State{Reconnecting, Disconnected},
+			wantErr: ErrUnreachable,
+		},
+		{
+			name:      "Should keep connection when caller stops waiting",
+			fails:     math.MaxInt32,
+			timeout:   time.Millisecond * 300,
+			want:      []State{Reconnecting, Interrupted},
+			wantErr:   context.DeadlineExceeded,
+			wantCache: true,
+		},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			drv := &flakyDriver{}
+			driverName := fmt.Sprintf("flaky-%d", i)
+			sql.Register(driverName, drv)
+
+			conn := sqlx.MustOpen(driverName, "dsn")
+			conn.SetMaxIdleConns(0)
+			drv.fails.Store(tt.fails)
+
+			var (
+				mu     sync.Mutex
+				states []State
+			)
+			p := &Pool{opened: map[string]*sqlx.DB{"dsn": conn}}
+			p.Observe(func(e Event) {
+				mu.Lock()
+				defer mu.Unlock()
+				require.Equal(t, "name", e.Name)
+				states = append(states, e.State)
+			})
+
+			ctx, cancel := context.WithTimeout(t.Context(), tt.timeout)
+			defer cancel()
+
+			got, err := p.Get(ctx, "name", driverName, "dsn", "dsn")
+			require.Equal(t, tt.want, states)
+
+			_, cached := p.cached("dsn")
+			require.Equal(t, tt.wantCache, cached)
+
+			if tt.wantCache {
+				drv.fails.Store(0)
+				require.NoError(t, conn.PingContext(t.Context()), "kept connection should stay open")
+			}
+
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Same(t, conn, got)
+		})
+	}
+}
+
+// flakyDriver refuses to connect while fails is positive.
+type flakyDriver struct {
+	fails atomic.Int32
+}
+
+func (d *flakyDriver) Open(string) (driver.Conn, error) {
+	if d.fails.Add(-1) >= 0 {
+		return nil, errors.New("connection refused")
+	}
+	return flakyConn{}, nil
+}
+
+type flakyConn struct{}
+
+func (flakyConn) Prepare(string) (driver.Stmt, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (flakyConn) Close() error {
+	return nil
+}
+
+func (flakyConn) Begin() (driver.Tx, error) {
+	return nil, errors.New("not implemented")
+}
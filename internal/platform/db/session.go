@@ -0,0 +1,214 @@
+package db
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/mattn/go-sqlite3"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+)
+
+const (
+	mysqlDriver    = "mysql"
+	postgresDriver = "postgres"
+	// filesDriver opens the in-memory SQLite databases flat files are
+	// loaded into. It is SQLite under a name of its own, so the engine
+	// of such contexts is told apart from the one of SQLite files.
+	filesDriver = "files"
+)
+
+func init() {
+	sql.Register(filesDriver, &sqlite3.SQLiteDriver{})
+	sqlx.BindDriver(filesDriver, sqlx.QUESTION)
+}
+
+// open connects to the database with the password, and the pool limits,
+// the session, TLS and SSH settings of the connection applied.
+func open(ctx context.Context, driverName, dsn, password string, conn cfg.Connection) (*sqlx.DB, error) {
+	// Running queries are cancelled on the server from a connection of
+	// their own, which a single connection would leave waiting.
+	if conn.Pool.MaxOpen == 1 && (driverName == postgresDriver || driverName == mysqlDriver) {
+		return nil, fmt.Errorf(
+			"%w: pool max_open of %s must be at least 2 to cancel queries",
+			errs.ErrValidation, driverName,
+		)
+	}
+
+	setup, err := sessionStatements(driverName, conn.Session)
+	if err != nil {
+		return nil, err
+	}
+
+	if dsn, err = withPassword(driverName, dsn, password); err != nil {
+		return nil, err
+	}
+
+	connector, err := newConnector(driverName, dsn, conn)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(setup) > 0 {
+		connector = &sessionConnector{base: connector, setup: setup}
+	}
+
+	pool := conn.Pool
+	if driverName == filesDriver {
+		// The tables loaded from the files are gone once the last
+		// connection to the in-memory database closes, so a single one
+		// is kept open for as long as the pool.
+		pool = cfg.Pool{MaxOpen: 1, MaxIdle: 1}
+	}
+
+	db := sql.OpenDB(connector)
+	db.SetMaxOpenConns(pool.MaxOpen)
+	if pool.MaxIdle > 0 {
+		db.SetMaxIdleConns(pool.MaxIdle)
+	}
+	db.SetConnMaxLifetime(pool.MaxLifetime)
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return sqlx.NewDb(db, driverName), nil
+}
+
+// sessionStatements returns what to run on every new connection for the
+// settings to take effect.
+func sessionStatements(driverName string, s cfg.Session) ([]string, error) {
+	var stmts []string
+	switch driverName {
+	case postgresDriver:
+		if s.StatementTimeout > 0 {
+			stmts = append(stmts, fmt.Sprintf("SET statement_timeout = %d", s.StatementTimeout.Milliseconds()))
+		}
+		if s.Schema != "" {
+			stmts = append(stmts, "SET search_path TO "+searchPath(s.Schema))
+		}
+		if s.TimeZone != "" {
+			stmts = append(stmts, "SET TIME ZONE "+pq.QuoteLiteral(s.TimeZone))
+		}
+	case mysqlDriver:
+		if s.StatementTimeout > 0 {
+			stmts = append(stmts, fmt.Sprintf("SET SESSION max_execution_time = %d", s.StatementTimeout.Milliseconds()))
+		}
+		if s.Schema != "" {
+			stmts = append(stmts, "USE `"+strings.ReplaceAll(s.Schema, "`", "``")+"`")
+		}
+		if s.TimeZone != "" {
+			stmts = append(stmts, "SET time_zone = '"+strings.ReplaceAll(s.TimeZone, "'", "''")+"'")
+		}
+	default:
+		if s.StatementTimeout > 0 || s.Schema != "" || s.TimeZone != "" {
+			return nil, fmt.Errorf(
+				"%w: %s supports only init statements in session settings",
+				errs.ErrValidation, driverName,
+			)
+		}
+	}
+
+	return append(stmts, s.Init...), nil
+}
+
+// searchPath quotes each of the comma separated schemas. Schemas already
+// written in double quotes, such as "$user", are taken as they are.
+func searchPath(schemas string) string {
+	var quoted []string
+	for _, schema := range strings.Split(schemas, ",") {
+		schema = strings.TrimSpace(schema)
+		if len(schema) >= 2 && schema[0] == '"' && schema[len(schema)-1] == '"' {
+			schema = strings.ReplaceAll(schema[1:len(schema)-1], `""`, `"`)
+		}
+
+		if schema != "" {
+			quoted = append(quoted, pq.QuoteIdentifier(schema))
+		}
+	}
+
+	return strings.Join(quoted, ", ")
+}
+
+// sessionConnector runs the setup statements on every connection the
+// driver opens, so they hold whichever connection database/sql picks.
+type sessionConnector struct {
+	base  driver.Connector
+	setup []string
+}
+
+func (c *sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.base.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, stmt := range c.setup {
+		if err := exec(ctx, conn, stmt); err != nil {
+			_ = conn.Close()
+			return nil, fmt.Errorf("set up session with %q: %w", stmt, err)
+		}
+	}
+
+	return conn, nil
+}
+
+func (c *sessionConnector) Driver() driver.Driver {
+	return c.base.Driver()
+}
+
+// Close closes the base connector when it holds on to something, such
+// as an SSH tunnel.
+func (c *sessionConnector) Close() error {
+	if closer, ok := c.base.(io.Closer); ok {
+		return closer.Close()
+	}
+	return nil
+}
+
+func exec(ctx context.Context, conn driver.Conn, query string) error {
+	if execer, ok := conn.(driver.ExecerContext); ok {
+		_, err := execer.ExecContext(ctx, query, nil)
+		if !errors.Is(err, driver.ErrSkip) {
+			return err
+		}
+	}
+
+	stmt, err := conn.Prepare(query)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	if execer, ok := stmt.(driver.StmtExecContext); ok {
+		_, err = execer.ExecContext(ctx, nil)
+		return err
+	}
+
+	_, err = stmt.Exec(nil) //nolint:staticcheck // For drivers without contexts.
+	return err
+}
+
+// dsnConnector is the connector database/sql uses for drivers which
+// don't provide one.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(context.Context) (driver.Conn, error) {
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
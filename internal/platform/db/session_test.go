@@ -0,0 +1,209 @@
+package db
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/db/mocks"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestSessionStatements(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	session := cfg.Session{
+		StatementTimeout: time.Second * 5,
+		Schema:           "app",
+		TimeZone:         "Europe/Kyiv",
+		Init:             []string{"SELECT 1"},
+	}
+
+	tests := []struct {
+		name    string
+		driver  string
+		session cfg.Session
+		want    []string
+		wantErr error
+	}{
+		{
+			name:    "Should set up postgres session",
+			driver:  postgresDriver,
+			session: session,
+			want: []string{
+				"SET statement_timeout = 5000",
+				`SET search_path TO "app"`,
+				"SET TIME ZONE 'Europe/Kyiv'",
+				"SELECT 1",
+			},
+		},
+		{
+			name:    "Should set up mysql session",
+			driver:  mysqlDriver,
+			session: session,
+			want: []string{
+				"SET SESSION max_execution_time = 5000",
+				"USE `app`",
+				"SET time_zone = 'Europe/Kyiv'",
+				"SELECT 1",
+			},
+		},
+		{
+			name:    "Should quote mysql schema and time zone",
+			driver:  mysqlDriver,
+			session: cfg.Session{Schema: "a`b", TimeZone: "it's"},
+			want:    []string{"USE `a``b`", "SET time_zone = 'it''s'"},
+		},
+		{
+			name:    "Should quote every schema of postgres search path",
+			driver:  postgresDriver,
+			session: cfg.Session{Schema: `app, "$user",my schema, x"; DROP TABLE users; --`},
+			want: []string{
+				`SET search_path TO "app", "$user", "my schema", "x""; DROP TABLE users; --"`,
+			},
+		},
+		{
+			name:    "Should run only init statements on sqlite",
+			driver:  "sqlite3",
+			session: cfg.Session{Init: []string{"PRAGMA foreign_keys = ON"}},
+			want:    []string{"PRAGMA foreign_keys = ON"},
+		},
+		{
+			name:    "Should reject time zone on sqlite",
+			driver:  "sqlite3",
+			session: cfg.Session{TimeZone: "UTC"},
+			wantErr: errs.ErrValidation,
+		},
+		{
+			name:   "Should return nothing without settings",
+			driver: postgresDriver,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := sessionStatements(tt.driver, tt.session)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestPool_GetAppliesSettings(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	const (
+		// The settings are saved by the DSN of the context, not the one
+		// of the driver.
+		dsn       = "sqlite://settings"
+		driverDSN = "file:settings?mode=memory"
+	)
+	repo := mocks.NewMockConfigRepository(gomock.NewController(t))
+	repo.EXPECT().GetConnection(gomock.Any(), dsn).Return(cfg.Connection{
+		Pool: cfg.Pool{MaxOpen: 2, MaxIdle: 1},
+		Session: cfg.Session{
+			Init: []string{"PRAGMA user_version = 7"},
+		},
+	}, true)
+	repo.EXPECT().GetPassword(gomock.Any(), dsn).Return("", nil)
+	repo.EXPECT().AddConnection(gomock.Any(), "name", dsn).Return(nil)
+
+	p := NewPool(repo)
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+
+	conn, err := p.Get(t.Context(), "name", "sqlite3", dsn, driverDSN)
+	require.NoError(t, err)
+	require.Equal(t, 2, conn.Stats().MaxOpenConnections)
+
+	var version int
+	require.NoError(t, conn.GetContext(t.Context(), &version, "PRAGMA user_version"))
+	require.Equal(t, 7, version)
+}
+
+func TestPool_GetRejectsSingleConnection(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		driver string
+		dsn    string
+	}{
+		{
+			name:   "Should reject single connection of PostgreSQL",
+			driver: postgresDriver,
+			dsn:    "postgres://localhost:5432/db",
+		},
+		{
+			name:   "Should reject single connection of MySQL",
+			driver: mysqlDriver,
+			dsn:    "root@tcp(localhost:3306)/db",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			repo := mocks.NewMockConfigRepository(gomock.NewController(t))
+			repo.EXPECT().GetConnection(gomock.Any(), tt.dsn).Return(cfg.Connection{
+				Pool: cfg.Pool{MaxOpen: 1},
+			}, true)
+			repo.EXPECT().GetPassword(gomock.Any(), tt.dsn).Return("", nil)
+
+			p := NewPool(repo)
+			t.Cleanup(func() {
+				require.NoError(t, p.Close())
+			})
+
+			_, err := p.Get(t.Context(), "name", tt.driver, tt.dsn, tt.dsn)
+			require.ErrorIs(t, err, errs.ErrValidation)
+		})
+	}
+}
+
+func TestPool_GetKeepsFilesLoaded(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	const (
+		dsn       = "users.csv"
+		driverDSN = "file:files-keep?mode=memory&cache=shared"
+	)
+	repo := mocks.NewMockConfigRepository(gomock.NewController(t))
+	repo.EXPECT().GetConnection(gomock.Any(), dsn).Return(cfg.Connection{
+		Pool: cfg.Pool{MaxOpen: 4, MaxLifetime: time.Millisecond},
+	}, true)
+	repo.EXPECT().GetPassword(gomock.Any(), dsn).Return("", nil)
+	repo.EXPECT().AddConnection(gomock.Any(), "name", dsn).Return(nil)
+
+	p := NewPool(repo)
+	t.Cleanup(func() {
+		require.NoError(t, p.Close())
+	})
+
+	conn, err := p.Get(t.Context(), "name", filesDriver, dsn, driverDSN)
+	require.NoError(t, err)
+	require.Equal(t, 1, conn.Stats().MaxOpenConnections)
+
+	_, err = conn.ExecContext(t.Context(), "CREATE TABLE users (id INTEGER)")
+	require.NoError(t, err)
+
+	time.Sleep(time.Millisecond * 10)
+
+	var count int
+	require.NoError(t, conn.GetContext(t.Context(), &count, "SELECT COUNT(*) FROM users"))
+}
@@ -0,0 +1,303 @@
+package db
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+)
+
+const sshPort = "22"
+
+// tunnel dials through the bastion, hopping through the jump hosts
+// first. The bastion is connected to on the first dial, and again once
+// the connection to it drops.
+type tunnel struct {
+	hops   []sshHop
+	config ssh.ClientConfig
+	agent  bool
+
+	mu    sync.Mutex
+	chain *sshChain
+}
+
+type sshHop struct {
+	user string
+	addr string
+}
+
+// sshChain is the clients of the hops, the last one reaching the bastion.
+// done is closed once the connection to the bastion is lost.
+type sshChain struct {
+	clients []*ssh.Client
+	done    chan struct{}
+}
+
+func newTunnel(s cfg.SSH) (*tunnel, error) {
+	defaultUser := s.User
+	if defaultUser == "" {
+		current, err := user.Current()
+		if err != nil {
+			return nil, fmt.Errorf("get ssh user: %w", err)
+		}
+		defaultUser = current.Username
+	}
+
+	t := &tunnel{agent: s.Agent}
+	for _, host := range slices.Concat(s.Jump, []string{s.Host}) {
+		t.hops = append(t.hops, parseSSHHost(host, defaultUser))
+	}
+
+	hostKeys, err := hostKeyCallback(s)
+	if err != nil {
+		return nil, err
+	}
+	t.config.HostKeyCallback = hostKeys
+
+	if s.KeyFile != "" {
+		signer, err := readKeyFile(s.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		t.config.Auth = append(t.config.Auth, ssh.PublicKeys(signer))
+	}
+
+	return t, nil
+}
+
+// parseSSHHost splits [user@]host[:port] into the user and the address.
+func parseSSHHost(host, defaultUser string) sshHop {
+	hop := sshHop{user: defaultUser, addr: host}
+	if name, addr, ok := strings.Cut(host, "@"); ok {
+		hop.user, hop.addr = name, addr
+	}
+
+	if _, _, err := net.SplitHostPort(hop.addr); err != nil {
+		hop.addr = net.JoinHostPort(strings.Trim(hop.addr, "[]"), sshPort)
+	}
+	return hop
+}
+
+func hostKeyCallback(s cfg.SSH) (ssh.HostKeyCallback, error) {
+	if s.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil //nolint:gosec // Asked for explicitly.
+	}
+
+	path := s.KnownHosts
+	if path == "" {
+		path = "~/.ssh/known_hosts"
+	}
+
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	callback, err := knownhosts.New(path)
+	if err != nil {
+		return nil, fmt.Errorf("read known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+func readKeyFile(path string) (ssh.Signer, error) {
+	path, err := expandHome(path)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read ssh key: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) {
+		return nil, fmt.Errorf("%w: ssh key %q is encrypted, add it to the agent", errs.ErrValidation, path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh key: %w", err)
+	}
+	return signer, nil
+}
+
+func expandHome(path string) (string, error) {
+	rest, ok := strings.CutPrefix(path, "~/")
+	if !ok {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home dir: %w", err)
+	}
+	return filepath.Join(home, rest), nil
+}
+
+// DialContext dials the address from the bastion. A lost connection to
+// the bastion is restored once before giving up.
+func (t *tunnel) DialContext(ctx context.Context, network, addr string) (net.Conn, error) {
+	chain, err := t.connected(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := chain.last().DialContext(ctx, network, addr)
+	if err == nil || !chain.lost() {
+		return conn, err
+	}
+
+	if chain, err = t.connected(ctx); err != nil {
+		return nil, err
+	}
+	return chain.last().DialContext(ctx, network, addr)
+}
+
+// Dial and DialTimeout make the tunnel a dialer of PostgreSQL, which
+// prefers DialContext when given one.
+func (t *tunnel) Dial(network, addr string) (net.Conn, error) {
+	return t.DialContext(context.Background(), network, addr)
+}
+
+func (t *tunnel) DialTimeout(network, addr string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return t.DialContext(ctx, network, addr)
+}
+
+func (t *tunnel) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.chain == nil {
+		return nil
+	}
+
+	err := t.chain.close()
+	t.chain = nil
+	return err
+}
+
+// connected returns the chain to the bastion, connecting it when there
+// is none or the connection was lost.
+func (t *tunnel) connected(ctx context.Context) (*sshChain, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.chain != nil && !t.chain.lost() {
+		return t.chain, nil
+	}
+
+	if t.chain != nil {
+		_ = t.chain.close()
+		t.chain = nil
+	}
+
+	chain, err := t.connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t.chain = chain
+	return chain, nil
+}
+
+func (t *tunnel) connect(ctx context.Context) (*sshChain, error) {
+	config := t.config
+	if t.agent {
+		conn, err := new(net.Dialer).DialContext(ctx, "unix", os.Getenv("SSH_AUTH_SOCK"))
+		if err != nil {
+			return nil, fmt.Errorf("connect to ssh agent: %w", err)
+		}
+		defer conn.Close()
+
+		config.Auth = append(slices.Clip(config.Auth), ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+	}
+
+	chain := &sshChain{done: make(chan struct{})}
+	for _, hop := range t.hops {
+		client, err := chain.dial(ctx, hop, config)
+		if err != nil {
+			_ = chain.close()
+			return nil, fmt.Errorf("ssh to %s: %w", hop.addr, err)
+		}
+		chain.clients = append(chain.clients, client)
+	}
+
+	go func() {
+		_ = chain.last().Wait()
+		close(chain.done)
+	}()
+
+	return chain, nil
+}
+
+// dial connects to the hop from the last client of the chain, or
+// directly for the first one.
+func (c *sshChain) dial(ctx context.Context, hop sshHop, config ssh.ClientConfig) (*ssh.Client, error) {
+	var conn net.Conn
+	var err error
+	if len(c.clients) == 0 {
+		conn, err = new(net.Dialer).DialContext(ctx, "tcp", hop.addr)
+	} else {
+		conn, err = c.last().DialContext(ctx, "tcp", hop.addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// The handshake doesn't take a context, so it is bound by the
+	// deadline of the connection instead.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	config.User = hop.user
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, hop.addr, &config)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+func (c *sshChain) last() *ssh.Client {
+	return c.clients[len(c.clients)-1]
+}
+
+func (c *sshChain) lost() bool {
+	select {
+	case <-c.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// close closes the clients from the bastion back to the first hop.
+func (c *sshChain) close() error {
+	var err error
+	for i := len(c.clients) - 1; i >= 0; i-- {
+		if cerr := c.clients[i].Close(); cerr != nil && !errors.Is(cerr, net.ErrClosed) {
+			err = errors.Join(err, cerr)
+		}
+	}
+	return err
+}
@@ -0,0 +1,435 @@
+package db
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+const sshUser = "deploy"
+
+func TestTunnel_DialContext(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	key := newSSHKey(t)
+	bastion := newSSHServer(t, newSigner(t, key).PublicKey())
+	gateway := newSSHServer(t, newSigner(t, key).PublicKey())
+	echo := newEchoServer(t)
+
+	dir := t.TempDir()
+	keyFile := writeSSHKey(t, dir, key)
+	knownHosts := writeKnownHosts(t, dir, bastion, gateway)
+	strangers := writeKnownHosts(t, t.TempDir(), newSSHServer(t, newSigner(t, key).PublicKey()))
+
+	tests := []struct {
+		name    string
+		ssh     func(t *testing.T) cfg.SSH
+		wantErr bool
+	}{
+		{
+			name: "Should dial through bastion with key file",
+			ssh: func(*testing.T) cfg.SSH {
+				return cfg.SSH{Host: bastion.addr, User: sshUser, KeyFile: keyFile, KnownHosts: knownHosts}
+			},
+		},
+		{
+			name: "Should dial through jump hosts",
+			ssh: func(*testing.T) cfg.SSH {
+				return cfg.SSH{
+					Host:       bastion.addr,
+					User:       sshUser,
+					KeyFile:    keyFile,
+					KnownHosts: knownHosts,
+					Jump:       []string{sshUser + "@" + gateway.addr},
+				}
+			},
+		},
+		{
+			name: "Should dial through bastion with agent",
+			ssh: func(t *testing.T) cfg.SSH {
+				serveAgent(t, key)
+				return cfg.SSH{Host: bastion.addr, User: sshUser, Agent: true, KnownHosts: knownHosts}
+			},
+		},
+		{
+			name: "Should dial through unknown bastion when asked to",
+			ssh: func(*testing.T) cfg.SSH {
+				return cfg.SSH{Host: bastion.addr, User: sshUser, KeyFile: keyFile, InsecureIgnoreHostKey: true}
+			},
+		},
+		{
+			name: "Should return an error when bastion is unknown",
+			ssh: func(*testing.T) cfg.SSH {
+				return cfg.SSH{Host: bastion.addr, User: sshUser, KeyFile: keyFile, KnownHosts: strangers}
+			},
+			wantErr: true,
+		},
+		{
+			name: "Should return an error when user is not authorized",
+			ssh: func(*testing.T) cfg.SSH {
+				return cfg.SSH{Host: bastion.addr, User: "root", KeyFile: keyFile, KnownHosts: knownHosts}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tunnel, err := newTunnel(tt.ssh(t))
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, tunnel.Close()) })
+
+			conn, err := tunnel.DialContext(t.Context(), "tcp", echo)
+			if tt.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			t.Cleanup(func() { conn.Close() })
+			requireEcho(t, conn)
+		})
+	}
+}
+
+func TestTunnel_DialContextReconnects(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	key := newSSHKey(t)
+	bastion := newSSHServer(t, newSigner(t, key).PublicKey())
+	echo := newEchoServer(t)
+
+	tunnel, err := newTunnel(cfg.SSH{
+		Host:                  bastion.addr,
+		User:                  sshUser,
+		KeyFile:               writeSSHKey(t, t.TempDir(), key),
+		InsecureIgnoreHostKey: true,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, tunnel.Close()) })
+
+	conn, err := tunnel.DialContext(t.Context(), "tcp", echo)
+	require.NoError(t, err)
+	requireEcho(t, conn)
+	lost := tunnel.chain
+
+	bastion.drop()
+	require.Eventually(t, lost.lost, time.Second, time.Millisecond*10)
+
+	conn, err = tunnel.DialContext(t.Context(), "tcp", echo)
+	require.NoError(t, err)
+	requireEcho(t, conn)
+	require.NotSame(t, lost, tunnel.chain)
+}
+
+func TestNewConnectorThroughTunnel(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	key := newSSHKey(t)
+	bastion := newSSHServer(t, newSigner(t, key).PublicKey())
+	tunnel := cfg.SSH{
+		Host:                  bastion.addr,
+		User:                  sshUser,
+		KeyFile:               writeSSHKey(t, t.TempDir(), key),
+		InsecureIgnoreHostKey: true,
+	}
+
+	tests := []struct {
+		name    string
+		driver  string
+		dsn     func(addr string) string
+		wantErr error
+	}{
+		{
+			name:   "Should dial postgres through tunnel",
+			driver: postgresDriver,
+			dsn: func(addr string) string {
+				return "postgres://user@" + addr + "/db?sslmode=disable"
+			},
+		},
+		{
+			name:   "Should dial mysql through tunnel",
+			driver: mysqlDriver,
+			dsn: func(addr string) string {
+				return "user:pass@tcp(" + addr + ")/db"
+			},
+		},
+		{
+			name:    "Should reject driver which can't be tunneled",
+			driver:  "sqlite3",
+			dsn:     func(string) string { return "db.db" },
+			wantErr: errs.ErrValidation,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var accepted atomic.Int64
+			addr := newServer(t, func(conn net.Conn) {
+				accepted.Add(1)
+				conn.Close()
+			})
+
+			connector, err := newConnector(tt.driver, tt.dsn(addr), cfg.Connection{SSH: tunnel})
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			t.Cleanup(func() { require.NoError(t, connector.(io.Closer).Close()) })
+
+			// The server hangs up, so only reaching it is of interest.
+			ctx, cancel := context.WithTimeout(t.Context(), time.Second)
+			defer cancel()
+			_, _ = connector.Connect(ctx)
+			require.Positive(t, accepted.Load())
+		})
+	}
+}
+
+func Test_parseSSHHost(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name string
+		host string
+		want sshHop
+	}{
+		{
+			name: "Should default user and port",
+			host: "bastion.example.com",
+			want: sshHop{user: sshUser, addr: "bastion.example.com:22"},
+		},
+		{
+			name: "Should read user and port",
+			host: "admin@bastion.example.com:2222",
+			want: sshHop{user: "admin", addr: "bastion.example.com:2222"},
+		},
+		{
+			name: "Should default port of ipv6 address",
+			host: "[::1]",
+			want: sshHop{user: sshUser, addr: "[::1]:22"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, parseSSHHost(tt.host, sshUser))
+		})
+	}
+}
+
+// sshServer forwards the connections its clients ask for, like a bastion.
+type sshServer struct {
+	addr    string
+	hostKey ssh.Signer
+
+	mu    sync.Mutex
+	conns []net.Conn
+}
+
+func newSSHServer(t *testing.T, authorized ssh.PublicKey) *sshServer {
+	t.Helper()
+	s := &sshServer{hostKey: newSigner(t, newSSHKey(t))}
+
+	config := &ssh.ServerConfig{
+		PublicKeyCallback: func(meta ssh.ConnMetadata, key ssh.PublicKey) (*ssh.Permissions, error) {
+			if meta.User() != sshUser || string(key.Marshal()) != string(authorized.Marshal()) {
+				return nil, errors.New("unauthorized")
+			}
+			return nil, nil
+		},
+	}
+	config.AddHostKey(s.hostKey)
+
+	s.addr = newServer(t, func(conn net.Conn) {
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+
+		_, chans, reqs, err := ssh.NewServerConn(conn, config)
+		if err != nil {
+			return
+		}
+		go ssh.DiscardRequests(reqs)
+
+		for ch := range chans {
+			go forward(ch)
+		}
+	})
+	return s
+}
+
+// drop hangs up on the clients.
+func (s *sshServer) drop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+	s.conns = nil
+}
+
+func forward(ch ssh.NewChannel) {
+	if ch.ChannelType() != "direct-tcpip" {
+		_ = ch.Reject(ssh.UnknownChannelType, "only forwarding is supported")
+		return
+	}
+
+	var req struct {
+		Host     string
+		Port     uint32
+		OrigHost string
+		OrigPort uint32
+	}
+	if err := ssh.Unmarshal(ch.ExtraData(), &req); err != nil {
+		_ = ch.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	target, err := net.Dial("tcp", net.JoinHostPort(req.Host, strconv.Itoa(int(req.Port))))
+	if err != nil {
+		_ = ch.Reject(ssh.ConnectionFailed, err.Error())
+		return
+	}
+
+	channel, reqs, err := ch.Accept()
+	if err != nil {
+		target.Close()
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+
+	go func() {
+		_, _ = io.Copy(target, channel)
+		target.Close()
+	}()
+	_, _ = io.Copy(channel, target)
+	channel.Close()
+}
+
+func newEchoServer(t *testing.T) string {
+	return newServer(t, func(conn net.Conn) {
+		_, _ = io.Copy(conn, conn)
+		conn.Close()
+	})
+}
+
+// newServer serves the connections to a local address until the end of
+// the test.
+func newServer(t *testing.T, serve func(net.Conn)) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go serve(conn)
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func requireEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	_, err := conn.Write([]byte("ping"))
+	require.NoError(t, err)
+
+	got := make([]byte, 4)
+	_, err = io.ReadFull(conn, got)
+	require.NoError(t, err)
+	require.Equal(t, "ping", string(got))
+}
+
+func newSSHKey(t *testing.T) ed25519.PrivateKey {
+	t.Helper()
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	return key
+}
+
+func newSigner(t *testing.T, key ed25519.PrivateKey) ssh.Signer {
+	t.Helper()
+	signer, err := ssh.NewSignerFromKey(key)
+	require.NoError(t, err)
+	return signer
+}
+
+func writeSSHKey(t *testing.T, dir string, key ed25519.PrivateKey) string {
+	t.Helper()
+	path := filepath.Join(dir, "id_ed25519")
+
+	block, err := ssh.MarshalPrivateKey(key, "")
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(block), 0o600))
+	return path
+}
+
+func writeKnownHosts(t *testing.T, dir string, servers ...*sshServer) string {
+	t.Helper()
+	path := filepath.Join(dir, "known_hosts")
+
+	var lines string
+	for _, s := range servers {
+		lines += knownhosts.Line([]string{s.addr}, s.hostKey.PublicKey()) + "\n"
+	}
+	require.NoError(t, os.WriteFile(path, []byte(lines), 0o600))
+	return path
+}
+
+// serveAgent serves an agent holding the key for the rest of the test.
+func serveAgent(t *testing.T, key ed25519.PrivateKey) {
+	t.Helper()
+	// Paths of sockets are short, which the test's directory may not be.
+	dir, err := os.MkdirTemp("", "agent")
+	require.NoError(t, err)
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	l, err := net.Listen("unix", filepath.Join(dir, "agent.sock"))
+	require.NoError(t, err)
+	t.Cleanup(func() { l.Close() })
+	t.Setenv("SSH_AUTH_SOCK", l.Addr().String())
+
+	keyring := agent.NewKeyring()
+	require.NoError(t, keyring.Add(agent.AddedKey{PrivateKey: key}))
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_ = agent.ServeAgent(keyring, conn)
+				conn.Close()
+			}()
+		}
+	}()
+}
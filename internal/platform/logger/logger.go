@@ -1,10 +1,61 @@
 package logger
 
 import (
+	"context"
+	"fmt"
 	"io"
 	"log/slog"
+
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 )
 
-func New(to io.Writer) *slog.Logger {
-	return slog.New(slog.NewTextHandler(to, nil))
+func New(to io.Writer, level slog.Level) *slog.Logger {
+	return slog.New(maskingHandler{slog.NewTextHandler(to, &slog.HandlerOptions{Level: level})})
+}
+
+// maskingHandler masks passwords in the messages and the attributes
+// before they are written.
+type maskingHandler struct {
+	slog.Handler
+}
+
+func (h maskingHandler) Handle(ctx context.Context, r slog.Record) error {
+	masked := slog.NewRecord(r.Time, r.Level, secret.Mask(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		masked.AddAttrs(maskAttr(a))
+		return true
+	})
+	return h.Handler.Handle(ctx, masked)
+}
+
+func (h maskingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, 0, len(attrs))
+	for _, a := range attrs {
+		masked = append(masked, maskAttr(a))
+	}
+	return maskingHandler{h.Handler.WithAttrs(masked)}
+}
+
+func (h maskingHandler) WithGroup(name string) slog.Handler {
+	return maskingHandler{h.Handler.WithGroup(name)}
+}
+
+// maskAttr masks strings, and values which aren't, once formatted.
+func maskAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindString:
+		return slog.String(a.Key, secret.Mask(v.String()))
+	case slog.KindAny:
+		return slog.String(a.Key, secret.Mask(fmt.Sprintf("%+v", v.Any())))
+	case slog.KindGroup:
+		group := v.Group()
+		masked := make([]slog.Attr, 0, len(group))
+		for _, a := range group {
+			masked = append(masked, maskAttr(a))
+		}
+		return slog.Attr{Key: a.Key, Value: slog.GroupValue(masked...)}
+	default:
+		return slog.Attr{Key: a.Key, Value: v}
+	}
 }
@@ -0,0 +1,145 @@
+package secret
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+const (
+	masked = "*****"
+	// minHidden is the length of the shortest resolved secret masked
+	// wherever it shows up, so a one letter password doesn't mask every
+	// word with that letter.
+	minHidden = 4
+)
+
+var (
+	urlPassword      = regexp.MustCompile(`([a-zA-Z][a-zA-Z0-9+.-]*://[^\s:/@]*):(\$\{[^}]*\}|[^\s/@]*)@`)
+	keyValuePassword = regexp.MustCompile(`(?i)((?:^|[\s?&])password\s*=\s*)('(?:\\.|[^'\\])*'|[^\s&]*)`)
+	mysqlPassword    = regexp.MustCompile(`(^|[\s"'(=])([^\s:@/"'()=]+):(\$\{[^}]*\}|[^\s@/][^\s@]*|)@(tcp|unix|udp|/)`)
+	keyValuePair     = regexp.MustCompile(`(?:^|\s+)password\s*=\s*('(?:\\.|[^'\\])*'|\S*)`)
+	mysqlNet         = regexp.MustCompile(`^[a-z0-9]*(\(.*\))?$`)
+)
+
+// hidden holds the secrets resolved so far, which are masked wherever
+// they show up.
+var hidden struct {
+	mu      sync.RWMutex
+	secrets map[string]struct{}
+}
+
+func hide(secret string) {
+	if len(secret) < minHidden {
+		return
+	}
+
+	hidden.mu.Lock()
+	defer hidden.mu.Unlock()
+	if hidden.secrets == nil {
+		hidden.secrets = make(map[string]struct{})
+	}
+	hidden.secrets[secret] = struct{}{}
+}
+
+// Mask replaces the passwords of the DSNs in the text, and the secrets
+// resolved so far, with asterisks.
+func Mask(text string) string {
+	text = urlPassword.ReplaceAllString(text, "${1}:"+masked+"@")
+	text = keyValuePassword.ReplaceAllString(text, "${1}"+masked)
+	text = mysqlPassword.ReplaceAllString(text, "${1}${2}:"+masked+"@${4}")
+
+	hidden.mu.RLock()
+	defer hidden.mu.RUnlock()
+	for secret := range hidden.secrets {
+		text = strings.ReplaceAll(text, secret, masked)
+	}
+	return text
+}
+
+// SplitPassword takes the password out of the DSN, written either as a
+// URL, as key=value pairs or as user:password@net(addr)/db of MySQL.
+// The password is empty when the DSN has none.
+func SplitPassword(dsn string) (string, string) {
+	if scheme, rest, ok := strings.Cut(dsn, "://"); ok {
+		rest, password := splitURLPassword(rest)
+		return scheme + "://" + rest, password
+	}
+
+	if loc := keyValuePair.FindStringSubmatchIndex(dsn); loc != nil {
+		password := unquoteKeyValue(dsn[loc[2]:loc[3]])
+		return strings.TrimSpace(dsn[:loc[0]] + dsn[loc[1]:]), password
+	}
+
+	return splitMySQLPassword(dsn)
+}
+
+// splitURLPassword takes the password out of the part of a URL after the
+// scheme. References are kept as written, while other passwords are
+// unescaped.
+func splitURLPassword(rest string) (string, string) {
+	colon := strings.IndexAny(rest, ":@/?#")
+	if colon < 0 || rest[colon] != ':' {
+		return rest, ""
+	}
+
+	start := colon + 1
+	var at int
+	if strings.HasPrefix(rest[start:], "${") {
+		end := strings.Index(rest[start:], "}")
+		if end < 0 || !strings.HasPrefix(rest[start+end+1:], "@") {
+			return rest, ""
+		}
+		at = start + end + 1
+	} else {
+		end := strings.IndexAny(rest[start:], "/?#")
+		if end < 0 {
+			end = len(rest) - start
+		}
+
+		at = strings.LastIndex(rest[start:start+end], "@")
+		if at < 0 {
+			// The colon separates the host from the port.
+			return rest, ""
+		}
+		at += start
+	}
+
+	password := rest[start:at]
+	if !IsRef(password) {
+		if unescaped, err := url.PathUnescape(password); err == nil {
+			password = unescaped
+		}
+	}
+
+	return rest[:colon] + rest[at:], password
+}
+
+// splitMySQLPassword looks for the password the way the MySQL driver
+// does, before the last @ in front of the last /.
+func splitMySQLPassword(dsn string) (string, string) {
+	slash := strings.LastIndex(dsn, "/")
+	if slash < 0 {
+		return dsn, ""
+	}
+
+	at := strings.LastIndex(dsn[:slash], "@")
+	if at < 0 {
+		return dsn, ""
+	}
+
+	user, password, ok := strings.Cut(dsn[:at], ":")
+	if !ok || strings.HasPrefix(password, "/") || !mysqlNet.MatchString(dsn[at+1:slash]) {
+		return dsn, ""
+	}
+
+	return user + dsn[at:], password
+}
+
+func unquoteKeyValue(value string) string {
+	if len(value) < 2 || value[0] != '\'' || value[len(value)-1] != '\'' {
+		return value
+	}
+	return strings.NewReplacer(`\\`, `\`, `\'`, `'`).Replace(value[1 : len(value)-1])
+}
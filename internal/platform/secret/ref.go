@@ -0,0 +1,95 @@
+package secret
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+const (
+	vaultPrefix   = "vault:"
+	commandPrefix = "cmd:"
+)
+
+// ErrNotFound is returned when a reference points to nothing.
+var ErrNotFound = errors.New("secret not found")
+
+// IsRef tells whether the value points to a secret rather than being one.
+// References are written as ${vault:name} for a secret of the vault,
+// ${cmd:command} for the output of a shell command and ${NAME} for an
+// environment variable.
+func IsRef(value string) bool {
+	return strings.HasPrefix(value, "${") && strings.HasSuffix(value, "}")
+}
+
+// VaultRef points to the secret kept in the vault under the name.
+func VaultRef(name string) string {
+	return "${" + vaultPrefix + name + "}"
+}
+
+// VaultName returns the name of the secret the reference points to, if
+// it is kept in the vault.
+func VaultName(ref string) (string, bool) {
+	if !IsRef(ref) {
+		return "", false
+	}
+	return strings.CutPrefix(ref[2:len(ref)-1], vaultPrefix)
+}
+
+// Resolve returns the secret the reference points to, or the value
+// itself when it is no reference. The vault is nil while locked.
+func Resolve(ctx context.Context, ref string, vault *Vault) (string, error) {
+	if !IsRef(ref) {
+		return ref, nil
+	}
+
+	secret, err := resolve(ctx, ref[2:len(ref)-1], vault)
+	if err != nil {
+		return "", err
+	}
+
+	hide(secret)
+	return secret, nil
+}
+
+func resolve(ctx context.Context, ref string, vault *Vault) (string, error) {
+	if name, ok := strings.CutPrefix(ref, vaultPrefix); ok {
+		if vault == nil {
+			return "", fmt.Errorf("%w: unlock it to use %q", ErrLocked, name)
+		}
+
+		secret, ok := vault.Get(name)
+		if !ok {
+			return "", fmt.Errorf("%w: no %q in vault", ErrNotFound, name)
+		}
+		return secret, nil
+	}
+
+	if command, ok := strings.CutPrefix(ref, commandPrefix); ok {
+		return run(ctx, command)
+	}
+
+	secret, ok := os.LookupEnv(ref)
+	if !ok {
+		return "", fmt.Errorf("%w: environment variable %s is not set", ErrNotFound, ref)
+	}
+	return secret, nil
+}
+
+// run returns what the command prints, without the trailing newline.
+func run(ctx context.Context, command string) (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("run secret command: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
@@ -0,0 +1,96 @@
+package secret
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestResolve(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Setenv("GOWATCHSQL_TEST_PASSWORD", "from-env")
+
+	vault, err := OpenVault(filepath.Join(t.TempDir(), "vault"), "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, vault.Set("pg", "from-vault"))
+
+	tests := []struct {
+		name    string
+		ref     string
+		vault   *Vault
+		want    string
+		wantErr error
+	}{
+		{
+			name: "Should keep plain value",
+			ref:  "plain",
+			want: "plain",
+		},
+		{
+			name: "Should read environment variable",
+			ref:  "${GOWATCHSQL_TEST_PASSWORD}",
+			want: "from-env",
+		},
+		{
+			name: "Should run command",
+			ref:  "${cmd:printf 'from-cmd\\n'}",
+			want: "from-cmd",
+		},
+		{
+			name:  "Should read vault",
+			ref:   VaultRef("pg"),
+			vault: vault,
+			want:  "from-vault",
+		},
+		{
+			name:    "Should return an error when vault is locked",
+			ref:     VaultRef("pg"),
+			wantErr: ErrLocked,
+		},
+		{
+			name:    "Should return an error when vault has no secret",
+			ref:     VaultRef("mysql"),
+			vault:   vault,
+			wantErr: ErrNotFound,
+		},
+		{
+			name:    "Should return an error when environment variable is not set",
+			ref:     "${GOWATCHSQL_TEST_MISSING}",
+			wantErr: ErrNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(t.Context(), tt.ref, tt.vault)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+
+	_, err = Resolve(t.Context(), "${cmd:exit 1}", nil)
+	require.Error(t, err)
+}
+
+func TestVaultName(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	name, ok := VaultName(VaultRef("pg"))
+	require.True(t, ok)
+	require.Equal(t, "pg", name)
+
+	_, ok = VaultName("${PGPASSWORD}")
+	require.False(t, ok)
+
+	_, ok = VaultName("vault:pg")
+	require.False(t, ok)
+}
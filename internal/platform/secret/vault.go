@@ -0,0 +1,219 @@
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const (
+	vaultVersion = 1
+	saltSize     = 16
+	keySize      = 32
+
+	// scrypt parameters recommended for interactive logins.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+)
+
+var (
+	// ErrWrongPassphrase is returned when the vault can't be decrypted
+	// with the passphrase.
+	ErrWrongPassphrase = errors.New("wrong vault passphrase")
+	// ErrLocked is returned when a secret of the vault is asked for
+	// without unlocking it.
+	ErrLocked = errors.New("vault is locked")
+)
+
+// Vault keeps secrets by name in a file, encrypted with AES-256-GCM under
+// a key derived from the passphrase by scrypt. The file is rewritten on
+// every change.
+type Vault struct {
+	path string
+	salt []byte
+	key  []byte
+
+	mu      sync.Mutex
+	secrets map[string]string
+}
+
+// vaultFile is the layout of the file. Data is the secrets encoded as
+// JSON, then sealed.
+type vaultFile struct {
+	Version int    `json:"version"`
+	Salt    []byte `json:"salt"`
+	Nonce   []byte `json:"nonce"`
+	Data    []byte `json:"data"`
+}
+
+// OpenVault unlocks the vault at the path with the passphrase. A missing
+// vault is created with it on the first change.
+func OpenVault(path, passphrase string) (*Vault, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("%w: vault passphrase is required", errs.ErrValidation)
+	}
+
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return newVault(path, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read vault: %w", err)
+	}
+
+	var file vaultFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("decode vault: %w", err)
+	}
+
+	if file.Version != vaultVersion {
+		return nil, fmt.Errorf("%w: unknown vault version %d", errs.ErrValidation, file.Version)
+	}
+
+	key, err := deriveKey(passphrase, file.Salt)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	plain, err := aead.Open(nil, file.Nonce, file.Data, nil)
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+
+	v := &Vault{path: path, salt: file.Salt, key: key}
+	if err := json.Unmarshal(plain, &v.secrets); err != nil {
+		return nil, fmt.Errorf("decode vault secrets: %w", err)
+	}
+
+	if v.secrets == nil {
+		v.secrets = make(map[string]string)
+	}
+	return v, nil
+}
+
+func newVault(path, passphrase string) (*Vault, error) {
+	salt := make([]byte, saltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate vault salt: %w", err)
+	}
+
+	key, err := deriveKey(passphrase, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Vault{path: path, salt: salt, key: key, secrets: make(map[string]string)}, nil
+}
+
+func deriveKey(passphrase string, salt []byte) ([]byte, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, keySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive vault key: %w", err)
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("create vault cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func (v *Vault) Get(name string) (string, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	secret, ok := v.secrets[name]
+	return secret, ok
+}
+
+func (v *Vault) Set(name, secret string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.secrets[name] = secret
+	return v.save()
+}
+
+func (v *Vault) Delete(name string) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	if _, ok := v.secrets[name]; !ok {
+		return nil
+	}
+
+	delete(v.secrets, name)
+	return v.save()
+}
+
+// save seals the secrets under a new nonce and replaces the file with
+// them, so a failed write leaves the previous vault in place.
+func (v *Vault) save() error {
+	plain, err := json.Marshal(v.secrets)
+	if err != nil {
+		return fmt.Errorf("encode vault secrets: %w", err)
+	}
+
+	aead, err := newAEAD(v.key)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("generate vault nonce: %w", err)
+	}
+
+	raw, err := json.Marshal(vaultFile{
+		Version: vaultVersion,
+		Salt:    v.salt,
+		Nonce:   nonce,
+		Data:    aead.Seal(nil, nonce, plain, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("encode vault: %w", err)
+	}
+
+	return writeFile(v.path, raw)
+}
+
+// writeFile writes the file next to the path, readable by the owner
+// only, and moves it over the path.
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+	if err != nil {
+		return fmt.Errorf("create vault: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("write vault: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("close vault: %w", err)
+	}
+
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("replace vault: %w", err)
+	}
+	return nil
+}
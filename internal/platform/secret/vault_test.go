@@ -0,0 +1,104 @@
+package secret
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestVault(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	path := filepath.Join(t.TempDir(), "vault")
+
+	v, err := OpenVault(path, "passphrase")
+	require.NoError(t, err)
+	_, err = os.Stat(path)
+	require.ErrorIs(t, err, os.ErrNotExist, "Vault must not be written before a change")
+
+	require.NoError(t, v.Set("pg", "s3cr3t"))
+	require.NoError(t, v.Set("mysql", "hunter2"))
+	require.NoError(t, v.Delete("mysql"))
+	require.NoError(t, v.Delete("missing"))
+
+	info, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.NotContains(t, string(raw), "s3cr3t")
+
+	reopened, err := OpenVault(path, "passphrase")
+	require.NoError(t, err)
+
+	got, ok := reopened.Get("pg")
+	require.True(t, ok)
+	require.Equal(t, "s3cr3t", got)
+
+	_, ok = reopened.Get("mysql")
+	require.False(t, ok)
+}
+
+func TestOpenVault(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	dir := t.TempDir()
+	path := filepath.Join(dir, "vault")
+
+	v, err := OpenVault(path, "passphrase")
+	require.NoError(t, err)
+	require.NoError(t, v.Set("pg", "s3cr3t"))
+
+	corrupted := filepath.Join(dir, "corrupted")
+	require.NoError(t, os.WriteFile(corrupted, []byte("not json"), 0o600))
+
+	tests := []struct {
+		name       string
+		path       string
+		passphrase string
+		wantErr    error
+	}{
+		{
+			name:       "Should open vault with passphrase",
+			path:       path,
+			passphrase: "passphrase",
+		},
+		{
+			name:       "Should return an error with wrong passphrase",
+			path:       path,
+			passphrase: "wrong",
+			wantErr:    ErrWrongPassphrase,
+		},
+		{
+			name:    "Should return an error without passphrase",
+			path:    path,
+			wantErr: errs.ErrValidation,
+		},
+		{
+			name:       "Should return an error when vault is corrupted",
+			path:       corrupted,
+			passphrase: "passphrase",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := OpenVault(tt.path, tt.passphrase)
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.path == corrupted:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,12 +2,38 @@ package color
 
 import "github.com/charmbracelet/lipgloss"
 
-const (
-	MainAccent      = lipgloss.Color("#5E81AC")
-	SecondaryAccent = lipgloss.Color("#81A1C1")
-	Border          = lipgloss.Color("#4C566A")
-	Text            = lipgloss.Color("#ECEFF4")
-	Error           = lipgloss.Color("#E8003E")
-	SecondaryText   = lipgloss.Color("#D8DEE9")
-	Placeholder     = lipgloss.Color("240")
+// Colors of the active theme. They are set once on startup with Apply,
+// before any model is created, and read by styles of every model.
+var (
+	MainAccent      lipgloss.TerminalColor = Dark.MainAccent
+	SecondaryAccent lipgloss.TerminalColor = Dark.SecondaryAccent
+	Border          lipgloss.TerminalColor = Dark.Border
+	Text            lipgloss.TerminalColor = Dark.Text
+	Error           lipgloss.TerminalColor = Dark.Error
+	SecondaryText   lipgloss.TerminalColor = Dark.SecondaryText
+	Placeholder     lipgloss.TerminalColor = Dark.Placeholder
+	Inserted        lipgloss.TerminalColor = Dark.Inserted
+	Changed         lipgloss.TerminalColor = Dark.Changed
 )
+
+var disabled bool
+
+// Disabled reports whether colors are turned off, so styles that rely
+// on a background color alone can fall back to reverse video.
+func Disabled() bool {
+	return disabled
+}
+
+// Apply makes the palette the active one.
+func Apply(p Palette) {
+	MainAccent = p.MainAccent
+	SecondaryAccent = p.SecondaryAccent
+	Border = p.Border
+	Text = p.Text
+	Error = p.Error
+	SecondaryText = p.SecondaryText
+	Placeholder = p.Placeholder
+	Inserted = p.Inserted
+	Changed = p.Changed
+	disabled = p == NoColor
+}
@@ -0,0 +1,184 @@
+package color
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const (
+	ThemeAuto         = "auto"
+	ThemeDark         = "dark"
+	ThemeLight        = "light"
+	ThemeHighContrast = "high-contrast"
+
+	baseKey = "base"
+)
+
+type Palette struct {
+	MainAccent      lipgloss.TerminalColor
+	SecondaryAccent lipgloss.TerminalColor
+	Border          lipgloss.TerminalColor
+	Text            lipgloss.TerminalColor
+	Error           lipgloss.TerminalColor
+	SecondaryText   lipgloss.TerminalColor
+	Placeholder     lipgloss.TerminalColor
+	Inserted        lipgloss.TerminalColor
+	Changed         lipgloss.TerminalColor
+}
+
+var (
+	Dark = Palette{
+		MainAccent:      lipgloss.Color("#5E81AC"),
+		SecondaryAccent: lipgloss.Color("#81A1C1"),
+		Border:          lipgloss.Color("#4C566A"),
+		Text:            lipgloss.Color("#ECEFF4"),
+		Error:           lipgloss.Color("#E8003E"),
+		SecondaryText:   lipgloss.Color("#D8DEE9"),
+		Placeholder:     lipgloss.Color("240"),
+		Inserted:        lipgloss.Color("#A3BE8C"),
+		Changed:         lipgloss.Color("#EBCB8B"),
+	}
+
+	Light = Palette{
+		MainAccent:      lipgloss.Color("#5E81AC"),
+		SecondaryAccent: lipgloss.Color("#4C6A92"),
+		Border:          lipgloss.Color("#A5ABB6"),
+		Text:            lipgloss.Color("#2E3440"),
+		Error:           lipgloss.Color("#BF1F3E"),
+		SecondaryText:   lipgloss.Color("#4C566A"),
+		Placeholder:     lipgloss.Color("246"),
+		Inserted:        lipgloss.Color("#4F7A35"),
+		Changed:         lipgloss.Color("#9A6A00"),
+	}
+
+	HighContrast = Palette{
+		MainAccent:      lipgloss.Color("11"),
+		SecondaryAccent: lipgloss.Color("14"),
+		Border:          lipgloss.Color("15"),
+		Text:            lipgloss.Color("15"),
+		Error:           lipgloss.Color("9"),
+		SecondaryText:   lipgloss.Color("15"),
+		Placeholder:     lipgloss.Color("7"),
+		Inserted:        lipgloss.Color("10"),
+		Changed:         lipgloss.Color("13"),
+	}
+
+	NoColor = Palette{
+		MainAccent:      lipgloss.NoColor{},
+		SecondaryAccent: lipgloss.NoColor{},
+		Border:          lipgloss.NoColor{},
+		Text:            lipgloss.NoColor{},
+		Error:           lipgloss.NoColor{},
+		SecondaryText:   lipgloss.NoColor{},
+		Placeholder:     lipgloss.NoColor{},
+		Inserted:        lipgloss.NoColor{},
+		Changed:         lipgloss.NoColor{},
+	}
+)
+
+var builtin = map[string]Palette{
+	ThemeDark:         Dark,
+	ThemeLight:        Light,
+	ThemeHighContrast: HighContrast,
+}
+
+var hexColor = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{6})$`)
+
+// Resolve picks the palette for the theme name. The name is either a
+// built-in theme, "auto" (or empty) to follow the terminal background,
+// or one of the custom themes. A custom theme maps palette keys such
+// as "main_accent" to colors and may extend another theme with "base".
+// NO_COLOR always wins over the configured theme.
+func Resolve(name string, custom map[string]map[string]string) (Palette, error) {
+	if termenv.EnvNoColor() {
+		return NoColor, nil
+	}
+
+	return resolve(name, custom, make(map[string]bool))
+}
+
+func resolve(name string, custom map[string]map[string]string, seen map[string]bool) (Palette, error) {
+	if name == "" || name == ThemeAuto {
+		return detect(), nil
+	}
+
+	if seen[name] {
+		return Palette{}, fmt.Errorf("%w: theme %q extends itself", errs.ErrValidation, name)
+	}
+	seen[name] = true
+
+	if spec, ok := custom[name]; ok {
+		return resolveCustom(name, spec, custom, seen)
+	}
+
+	if p, ok := builtin[name]; ok {
+		return p, nil
+	}
+
+	return Palette{}, fmt.Errorf("%w: unknown theme %q", errs.ErrValidation, name)
+}
+
+func resolveCustom(
+	name string,
+	spec map[string]string,
+	custom map[string]map[string]string,
+	seen map[string]bool,
+) (Palette, error) {
+	p, err := resolve(spec[baseKey], custom, seen)
+	if err != nil {
+		return Palette{}, fmt.Errorf("resolve base of theme %q: %w", name, err)
+	}
+
+	fields := map[string]*lipgloss.TerminalColor{
+		"main_accent":      &p.MainAccent,
+		"secondary_accent": &p.SecondaryAccent,
+		"border":           &p.Border,
+		"text":             &p.Text,
+		"error":            &p.Error,
+		"secondary_text":   &p.SecondaryText,
+		"placeholder":      &p.Placeholder,
+		"inserted":         &p.Inserted,
+		"changed":          &p.Changed,
+	}
+
+	for key, value := range spec {
+		if key == baseKey {
+			continue
+		}
+
+		field, ok := fields[key]
+		if !ok {
+			return Palette{}, fmt.Errorf("%w: theme %q: unknown color %q", errs.ErrValidation, name, key)
+		}
+
+		if !isValidColor(value) {
+			return Palette{}, fmt.Errorf("%w: theme %q: invalid color %q for %q", errs.ErrValidation, name, value, key)
+		}
+
+		*field = lipgloss.Color(value)
+	}
+
+	return p, nil
+}
+
+func detect() Palette {
+	if lipgloss.HasDarkBackground() {
+		return Dark
+	}
+	return Light
+}
+
+func isValidColor(value string) bool {
+	if hexColor.MatchString(value) {
+		return true
+	}
+
+	ansi, err := strconv.Atoi(value)
+	return err == nil && ansi >= 0 && ansi <= 255
+}
@@ -0,0 +1,98 @@
+package color
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestResolve(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Setenv("NO_COLOR", "")
+
+	custom := map[string]map[string]string{
+		"mine": {
+			"base":        ThemeLight,
+			"main_accent": "#FF00FF",
+		},
+		"nested":  {"base": "mine", "error": "196"},
+		"unknown": {"accent": "#FFFFFF"},
+		"invalid": {"text": "pink"},
+		"loop":    {"base": "loop"},
+	}
+
+	withAccent := Light
+	withAccent.MainAccent = lipgloss.Color("#FF00FF")
+
+	withError := withAccent
+	withError.Error = lipgloss.Color("196")
+
+	tests := []struct {
+		name    string
+		theme   string
+		want    Palette
+		wantErr bool
+	}{
+		{
+			name:  "Should return built-in theme",
+			theme: ThemeHighContrast,
+			want:  HighContrast,
+		},
+		{
+			name:  "Should override colors of the base theme",
+			theme: "mine",
+			want:  withAccent,
+		},
+		{
+			name:  "Should extend another custom theme",
+			theme: "nested",
+			want:  withError,
+		},
+		{
+			name:    "Should return an error for unknown theme",
+			theme:   "solarized",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for unknown color key",
+			theme:   "unknown",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for invalid color",
+			theme:   "invalid",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for theme extending itself",
+			theme:   "loop",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Resolve(tt.theme, custom)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestResolveRespectsNoColor(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Setenv("NO_COLOR", "1")
+
+	got, err := Resolve(ThemeDark, nil)
+	require.NoError(t, err)
+	require.Equal(t, NoColor, got)
+}
@@ -3,8 +3,64 @@ package command
 type Command string
 
 const (
-	Context Command = "contexts"
-	Query   Command = "query"
-	Tables  Command = "tables"
-	Exit    Command = "exit"
+	Context  Command = "contexts"
+	Query    Command = "query"
+	Tables   Command = "tables"
+	Exit     Command = "exit"
+	Help     Command = "help"
+	Watch    Command = "watch"
+	Activity Command = "activity"
+	Locks    Command = "locks"
 )
+
+func NewDefaultRegistry() Registry {
+	return NewRegistry(
+		Spec{
+			Name:        Context,
+			Aliases:     []string{"ctx", "context"},
+			Args:        []string{"name"},
+			Rest:        true,
+			Description: "show contexts or switch to the named one",
+		},
+		Spec{
+			Name:        Tables,
+			Aliases:     []string{"t", "table"},
+			Args:        []string{"name"},
+			Description: "show tables or open the named one",
+		},
+		Spec{
+			Name:        Query,
+			Aliases:     []string{"sql"},
+			Args:        []string{"statement"},
+			Rest:        true,
+			Description: "open query prompt or run the statement",
+		},
+		Spec{
+			Name:        Watch,
+			Aliases:     []string{"w"},
+			Args:        []string{"interval"},
+			Description: "re-run the shown query every interval, or stop with off",
+		},
+		Spec{
+			Name:        Activity,
+			Aliases:     []string{"ps", "top"},
+			Description: "show sessions running on the server",
+		},
+		Spec{
+			Name:        Locks,
+			Aliases:     []string{"lock"},
+			Description: "show which sessions block which",
+		},
+		Spec{
+			Name:        Help,
+			Aliases:     []string{"h"},
+			Args:        []string{"command"},
+			Description: "show usage of a command",
+		},
+		Spec{
+			Name:        Exit,
+			Aliases:     []string{"q", "quit"},
+			Description: "quit the program",
+		},
+	)
+}
@@ -0,0 +1,133 @@
+package command
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+const prefix = ":"
+
+// Spec describes a single command the command prompt understands.
+type Spec struct {
+	Name        Command
+	Aliases     []string
+	Args        []string
+	Rest        bool // the last argument takes the rest of the input as is
+	Description string
+}
+
+func (s Spec) Usage() string {
+	var b strings.Builder
+	b.WriteString(string(s.Name))
+	for _, alias := range s.Aliases {
+		b.WriteString("|" + alias)
+	}
+
+	for _, arg := range s.Args {
+		b.WriteString(" [" + arg + "]")
+	}
+
+	if s.Description != "" {
+		b.WriteString(" - " + s.Description)
+	}
+
+	return b.String()
+}
+
+func (s Spec) matches(name string) bool {
+	return string(s.Name) == name || slices.Contains(s.Aliases, name)
+}
+
+type Parsed struct {
+	Name Command
+	Args []string
+}
+
+func (p Parsed) Arg(i int) string {
+	if i >= len(p.Args) {
+		return ""
+	}
+	return p.Args[i]
+}
+
+func NewRegistry(specs ...Spec) Registry {
+	return Registry{specs: specs}
+}
+
+type Registry struct {
+	specs []Spec
+}
+
+func (r Registry) Specs() []Spec {
+	return slices.Clone(r.specs)
+}
+
+func (r Registry) Lookup(name string) (Spec, bool) {
+	for _, s := range r.specs {
+		if s.matches(name) {
+			return s, true
+		}
+	}
+	return Spec{}, false
+}
+
+func (r Registry) Parse(input string) (Parsed, error) {
+	input = strings.TrimPrefix(strings.TrimSpace(input), prefix)
+	name, rest, _ := strings.Cut(input, " ")
+	if name == "" {
+		return Parsed{}, fmt.Errorf("%w: command is required", errs.ErrValidation)
+	}
+
+	spec, ok := r.Lookup(name)
+	if !ok {
+		return Parsed{}, fmt.Errorf("%w: unknown command %q, try %q", errs.ErrValidation, name, Help)
+	}
+
+	args, err := spec.parseArgs(strings.TrimSpace(rest))
+	if err != nil {
+		return Parsed{}, err
+	}
+
+	return Parsed{Name: spec.Name, Args: args}, nil
+}
+
+func (s Spec) parseArgs(rest string) ([]string, error) {
+	if rest == "" {
+		return nil, nil
+	}
+
+	if s.Rest && len(s.Args) > 0 {
+		fields := strings.SplitN(rest, " ", len(s.Args))
+		for i := range fields {
+			fields[i] = strings.TrimSpace(fields[i])
+		}
+		return fields, nil
+	}
+
+	fields := strings.Fields(rest)
+	if len(fields) > len(s.Args) {
+		return nil, fmt.Errorf("%w: too many arguments, usage: %s", errs.ErrValidation, s.Usage())
+	}
+
+	return fields, nil
+}
+
+// Suggestions returns every command line the prompt can complete to:
+// each command name and alias, optionally followed by one of the known
+// argument values for that command.
+func (r Registry) Suggestions(args map[Command][]string) []string {
+	suggestions := make([]string, 0, len(r.specs))
+	for _, s := range r.specs {
+		names := append([]string{string(s.Name)}, s.Aliases...)
+		for _, name := range names {
+			suggestions = append(suggestions, name)
+			for _, arg := range args[s.Name] {
+				suggestions = append(suggestions, name+" "+arg)
+			}
+		}
+	}
+	return suggestions
+}
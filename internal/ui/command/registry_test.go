@@ -0,0 +1,90 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestRegistry_Parse(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    Parsed
+		wantErr bool
+	}{
+		{
+			name:  "Should parse command without arguments",
+			input: "tables",
+			want:  Parsed{Name: Tables},
+		},
+		{
+			name:  "Should resolve alias",
+			input: "q",
+			want:  Parsed{Name: Exit},
+		},
+		{
+			name:  "Should strip leading colon",
+			input: ":ctx",
+			want:  Parsed{Name: Context},
+		},
+		{
+			name:  "Should parse single argument",
+			input: "tables users",
+			want:  Parsed{Name: Tables, Args: []string{"users"}},
+		},
+		{
+			name:  "Should keep the rest of the input as the last argument",
+			input: "query SELECT * FROM users",
+			want:  Parsed{Name: Query, Args: []string{"SELECT * FROM users"}},
+		},
+		{
+			name:  "Should keep spaces in context name",
+			input: "ctx AWS Prod RDS",
+			want:  Parsed{Name: Context, Args: []string{"AWS Prod RDS"}},
+		},
+		{
+			name:    "Should return an error for unknown command",
+			input:   "tabels",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for empty input",
+			input:   "  ",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for too many arguments",
+			input:   "exit now please",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := NewDefaultRegistry().Parse(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestRegistry_Suggestions(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	r := NewRegistry(Spec{Name: Tables, Aliases: []string{"t"}, Args: []string{"name"}})
+	got := r.Suggestions(map[Command][]string{Tables: {"users"}})
+	require.Equal(t, []string{"tables", "tables users", "t", "t users"}, got)
+}
@@ -0,0 +1,290 @@
+package keymap
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/evertras/bubble-table/table"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+)
+
+// Action is a name of something the user can trigger with a key, in
+// the "scope.action" form used by the keys section of the config.
+type Action string
+
+const (
+	GlobalHelp    Action = "global.help"
+	GlobalCommand Action = "global.command"
+	GlobalClose   Action = "global.close"
+	GlobalQuit    Action = "global.quit"
+
+	CommandRun            Action = "command.run"
+	CommandComplete       Action = "command.complete"
+	CommandNextSuggestion Action = "command.next_suggestion"
+	CommandPrevSuggestion Action = "command.prev_suggestion"
+	CommandForward        Action = "command.forward"
+	CommandBackwards      Action = "command.backwards"
+
+	ContextsSelect     Action = "contexts.select"
+	ContextsCreate     Action = "contexts.create"
+	ContextsImport     Action = "contexts.import"
+	ContextsDelete     Action = "contexts.delete"
+	ContextsCancelForm Action = "contexts.cancel_form"
+	ContextsCancel     Action = "contexts.cancel_connect"
+	ContextsRetry      Action = "contexts.retry"
+
+	ObjectsNextPanel Action = "objects.next_panel"
+	ObjectsPrevPanel Action = "objects.prev_panel"
+
+	TablesSelect Action = "tables.select"
+	TablesSort   Action = "tables.sort"
+
+	DetailsPrevTab Action = "details.prev_tab"
+	DetailsNextTab Action = "details.next_tab"
+
+	QueryExecute     Action = "query.execute"
+	QuerySwitchFocus Action = "query.switch_focus"
+	QueryLeave       Action = "query.leave"
+	QueryCancel      Action = "query.cancel"
+	QueryNextTab     Action = "query.next_tab"
+	QueryPrevTab     Action = "query.prev_tab"
+	QueryCloseTab    Action = "query.close_tab"
+	QueryPinTab      Action = "query.pin_tab"
+	QueryRenameTab   Action = "query.rename_tab"
+	QueryExplain     Action = "query.explain"
+	QueryAnalyze     Action = "query.analyze"
+
+	PlanToggle Action = "plan.toggle"
+
+	ActivityRefresh   Action = "activity.refresh"
+	ActivitySwitch    Action = "activity.switch_view"
+	ActivityCancel    Action = "activity.cancel"
+	ActivityTerminate Action = "activity.terminate"
+	ActivityConfirm   Action = "activity.confirm"
+	ActivityAbort     Action = "activity.abort"
+
+	TableRowDown     Action = "table.row_down"
+	TableRowUp       Action = "table.row_up"
+	TablePageDown    Action = "table.page_down"
+	TablePageUp      Action = "table.page_up"
+	TablePageFirst   Action = "table.page_first"
+	TablePageLast    Action = "table.page_last"
+	TableScrollLeft  Action = "table.scroll_left"
+	TableScrollRight Action = "table.scroll_right"
+
+	ListCursorUp   Action = "list.cursor_up"
+	ListCursorDown Action = "list.cursor_down"
+	ListNextPage   Action = "list.next_page"
+	ListPrevPage   Action = "list.prev_page"
+	ListFilter     Action = "list.filter"
+)
+
+type binding struct {
+	keys []string
+	desc string
+}
+
+var defaults = map[Action]binding{
+	GlobalHelp:    {keys: []string{"?"}, desc: "help"},
+	GlobalCommand: {keys: []string{":"}, desc: "command prompt"},
+	GlobalClose:   {keys: []string{"esc"}, desc: "close help"},
+	GlobalQuit:    {keys: []string{"ctrl+c"}, desc: "quit"},
+
+	CommandRun:            {keys: []string{"enter"}, desc: "run command"},
+	CommandComplete:       {keys: []string{"tab"}, desc: "complete"},
+	CommandNextSuggestion: {keys: []string{"down", "ctrl+n"}, desc: "next suggestion"},
+	CommandPrevSuggestion: {keys: []string{"up", "ctrl+p"}, desc: "previous suggestion"},
+	CommandForward:        {keys: []string{"esc"}, desc: "focus main panel"},
+	CommandBackwards:      {keys: []string{"shift+tab"}, desc: "focus main panel"},
+
+	ContextsSelect:     {keys: []string{"enter"}, desc: "connect"},
+	ContextsCreate:     {keys: []string{"N"}, desc: "new context"},
+	ContextsImport:     {keys: []string{"I"}, desc: "import contexts"},
+	ContextsDelete:     {keys: []string{"d"}, desc: "delete context"},
+	ContextsCancelForm: {keys: []string{"esc"}, desc: "cancel"},
+	ContextsCancel:     {keys: []string{"ctrl+x"}, desc: "cancel connecting"},
+	ContextsRetry:      {keys: []string{"r"}, desc: "retry connecting"},
+
+	ObjectsNextPanel: {keys: []string{"tab"}, desc: "next panel"},
+	ObjectsPrevPanel: {keys: []string{"shift+tab"}, desc: "previous panel"},
+
+	TablesSelect: {keys: []string{"enter"}, desc: "open table"},
+	TablesSort:   {keys: []string{"s"}, desc: "sort by name/size/rows"},
+
+	DetailsPrevTab: {keys: []string{"H"}, desc: "previous tab"},
+	DetailsNextTab: {keys: []string{"L"}, desc: "next tab"},
+
+	QueryExecute:     {keys: []string{"enter"}, desc: "run query"},
+	QuerySwitchFocus: {keys: []string{"tab", "shift+tab"}, desc: "switch prompt/results"},
+	QueryLeave:       {keys: []string{"esc"}, desc: "leave panel"},
+	QueryCancel:      {keys: []string{"ctrl+x"}, desc: "cancel query"},
+	QueryNextTab:     {keys: []string{"L"}, desc: "next result"},
+	QueryPrevTab:     {keys: []string{"H"}, desc: "previous result"},
+	QueryCloseTab:    {keys: []string{"x"}, desc: "close result"},
+	QueryPinTab:      {keys: []string{"p"}, desc: "pin/unpin result"},
+	QueryRenameTab:   {keys: []string{"r"}, desc: "rename result"},
+	QueryExplain:     {keys: []string{"ctrl+o"}, desc: "explain query"},
+	QueryAnalyze:     {keys: []string{"ctrl+t"}, desc: "explain analyze query"},
+
+	PlanToggle: {keys: []string{" "}, desc: "expand/collapse"},
+
+	ActivityRefresh:   {keys: []string{"r"}, desc: "refresh"},
+	ActivitySwitch:    {keys: []string{"tab"}, desc: "sessions/locks"},
+	ActivityCancel:    {keys: []string{"c"}, desc: "cancel query"},
+	ActivityTerminate: {keys: []string{"t"}, desc: "terminate session"},
+	ActivityConfirm:   {keys: []string{"y", "enter"}, desc: "confirm"},
+	ActivityAbort:     {keys: []string{"n", "esc"}, desc: "abort"},
+
+	TableRowDown:     {keys: []string{"down", "j"}, desc: "move down"},
+	TableRowUp:       {keys: []string{"up", "k"}, desc: "move up"},
+	TablePageDown:    {keys: []string{"right", "pgdown"}, desc: "next page"},
+	TablePageUp:      {keys: []string{"left", "pgup"}, desc: "previous page"},
+	TablePageFirst:   {keys: []string{"home", "g"}, desc: "first page"},
+	TablePageLast:    {keys: []string{"end", "G"}, desc: "last page"},
+	TableScrollLeft:  {keys: []string{"h"}, desc: "scroll left"},
+	TableScrollRight: {keys: []string{"l"}, desc: "scroll right"},
+
+	ListCursorUp:   {keys: []string{"up", "k"}, desc: "up"},
+	ListCursorDown: {keys: []string{"down", "j"}, desc: "down"},
+	ListNextPage:   {keys: []string{"right", "l", "pgdown", "f"}, desc: "next page"},
+	ListPrevPage:   {keys: []string{"left", "h", "pgup", "b", "u"}, desc: "prev page"},
+	ListFilter:     {keys: []string{"/"}, desc: "filter"},
+}
+
+// layers lists the scopes whose bindings are active at the same time,
+// so the same key must not trigger two different actions inside one of
+// them. GlobalClose is left out, since it only works while the help
+// popup is shown and nothing else handles keys then.
+var layers = [][]string{
+	{"global", "command"},
+	{"global", "contexts", "list"},
+	{"global", "objects", "tables", "list"},
+	{"global", "objects", "details", "table"},
+	{"global", "query", "table", "plan"},
+	{"global", "activity", "table"},
+}
+
+type Map struct {
+	bindings map[Action]key.Binding
+}
+
+// Default returns the built-in bindings.
+func Default() Map {
+	m, _ := New(nil)
+	return m
+}
+
+// New builds the bindings from defaults, replacing the keys of every
+// action found in overrides. An action mapped to no keys is disabled.
+func New(overrides map[string][]string) (Map, error) {
+	keys := make(map[Action][]string, len(defaults))
+	for action, b := range defaults {
+		keys[action] = b.keys
+	}
+
+	for name, override := range overrides {
+		action := Action(name)
+		if _, ok := defaults[action]; !ok {
+			return Map{}, fmt.Errorf("%w: unknown key action %q", errs.ErrValidation, name)
+		}
+		keys[action] = override
+	}
+
+	if err := validate(keys); err != nil {
+		return Map{}, err
+	}
+
+	m := Map{bindings: make(map[Action]key.Binding, len(keys))}
+	for action, k := range keys {
+		m.bindings[action] = newBinding(k, defaults[action].desc)
+	}
+
+	return m, nil
+}
+
+func (m Map) Get(action Action) key.Binding {
+	if b, ok := m.bindings[action]; ok {
+		return b
+	}
+
+	b := defaults[action]
+	return newBinding(b.keys, b.desc)
+}
+
+// Table returns the bindings for bubble-table based on the defaults
+// of the library.
+func (m Map) Table() table.KeyMap {
+	km := table.DefaultKeyMap()
+	km.RowDown = m.Get(TableRowDown)
+	km.RowUp = m.Get(TableRowUp)
+	km.PageDown = m.Get(TablePageDown)
+	km.PageUp = m.Get(TablePageUp)
+	km.PageFirst = m.Get(TablePageFirst)
+	km.PageLast = m.Get(TablePageLast)
+	km.ScrollLeft = m.Get(TableScrollLeft)
+	km.ScrollRight = m.Get(TableScrollRight)
+	return km
+}
+
+// List returns the bindings for bubbles list based on the given ones.
+func (m Map) List(km list.KeyMap) list.KeyMap {
+	km.CursorUp = m.Get(ListCursorUp)
+	km.CursorDown = m.Get(ListCursorDown)
+	km.NextPage = m.Get(ListNextPage)
+	km.PrevPage = m.Get(ListPrevPage)
+	km.Filter = m.Get(ListFilter)
+	return km
+}
+
+func newBinding(keys []string, desc string) key.Binding {
+	if len(keys) == 0 {
+		return key.NewBinding(key.WithDisabled())
+	}
+
+	names := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if k == " " {
+			k = "space"
+		}
+		names = append(names, k)
+	}
+
+	return key.NewBinding(
+		key.WithKeys(keys...),
+		key.WithHelp(strings.Join(names, "/"), desc),
+	)
+}
+
+func validate(keys map[Action][]string) error {
+	actions := slices.Sorted(maps.Keys(keys))
+	for _, layer := range layers {
+		owners := make(map[string]Action)
+		for _, action := range actions {
+			if action == GlobalClose || !slices.Contains(layer, action.scope()) {
+				continue
+			}
+
+			for _, k := range keys[action] {
+				if owner, ok := owners[k]; ok && owner != action {
+					return fmt.Errorf(
+						"%w: key %q is bound to both %q and %q",
+						errs.ErrValidation, k, owner, action,
+					)
+				}
+				owners[k] = action
+			}
+		}
+	}
+
+	return nil
+}
+
+func (a Action) scope() string {
+	scope, _, _ := strings.Cut(string(a), ".")
+	return scope
+}
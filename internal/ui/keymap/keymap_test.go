@@ -0,0 +1,74 @@
+package keymap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestNew(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name      string
+		overrides map[string][]string
+		action    Action
+		wantKeys  []string
+		wantErr   bool
+	}{
+		{
+			name:     "Should use defaults without overrides",
+			action:   DetailsNextTab,
+			wantKeys: []string{"L"},
+		},
+		{
+			name:      "Should remap an action",
+			overrides: map[string][]string{"details.next_tab": {"ctrl+f"}},
+			action:    DetailsNextTab,
+			wantKeys:  []string{"ctrl+f"},
+		},
+		{
+			name:      "Should disable an action mapped to no keys",
+			overrides: map[string][]string{"contexts.delete": {}},
+			action:    ContextsDelete,
+		},
+		{
+			name:      "Should allow the same key in unrelated scopes",
+			overrides: map[string][]string{"contexts.create": {"H"}},
+			action:    ContextsCreate,
+			wantKeys:  []string{"H"},
+		},
+		{
+			name:      "Should return an error for unknown action",
+			overrides: map[string][]string{"details.next": {"L"}},
+			wantErr:   true,
+		},
+		{
+			name:      "Should return an error for conflicting keys",
+			overrides: map[string][]string{"table.scroll_left": {"H"}},
+			wantErr:   true,
+		},
+		{
+			name:      "Should return an error for conflict with global key",
+			overrides: map[string][]string{"contexts.delete": {"?"}},
+			wantErr:   true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := New(tt.overrides)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.wantKeys, got.Get(tt.action).Keys())
+		})
+	}
+}
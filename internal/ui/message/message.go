@@ -3,10 +3,12 @@ package message
 import (
 	"fmt"
 	"log/slog"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
 	"github.com/hrvadl/gowatchsql/internal/ui/command"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
 )
@@ -19,6 +21,15 @@ func With(msg tea.Msg) func() tea.Msg {
 	}
 }
 
+// Batch sends all the messages, for commands that produce more than one.
+func Batch(msgs ...tea.Msg) tea.Msg {
+	cmds := make([]tea.Cmd, 0, len(msgs))
+	for _, msg := range msgs {
+		cmds = append(cmds, With(msg))
+	}
+	return tea.BatchMsg(cmds)
+}
+
 type (
 	CleanCommandLine struct{}
 
@@ -27,14 +38,35 @@ type (
 	UnblockCommandLine struct{}
 
 	Command struct {
-		Text command.Command
+		command.Parsed
 	}
 
 	SelectedContext struct {
-		Name string
-		DSN  string
+		Name        string
+		DSN         string
+		Driver      string
+		Timeouts    cfg.Timeouts
+		ReadOnly    bool
+		Environment cfg.Environment
+	}
+
+	// ExplorerReady shares the explorer of the selected context with
+	// the models working on it.
+	ExplorerReady struct {
+		Context  string
+		Explorer engine.Explorer
 	}
 
+	// ExplorerFailed tells the selected context couldn't be connected
+	// to.
+	ExplorerFailed struct {
+		Context string
+		Err     error
+	}
+
+	// CancelConnect stops connecting to the selected context.
+	CancelConnect struct{}
+
 	Error struct {
 		Err error
 	}
@@ -43,6 +75,13 @@ type (
 		Tables []engine.Table
 	}
 
+	// FetchedTableStats holds stats of the tables of Context, by table
+	// name.
+	FetchedTableStats struct {
+		Context string
+		Stats   map[string]engine.TableStats
+	}
+
 	FetchedRows struct {
 		Rows [][]string
 		Cols []string
@@ -71,6 +110,60 @@ type (
 		Cmd string
 	}
 
+	// ExecutedQuery is the result of the query run on the context.
+	ExecutedQuery struct {
+		Context  string
+		Query    string
+		Rows     [][]string
+		Cols     []string
+		Affected int64
+		Err      error
+	}
+
+	// ExplainedQuery is the plan of the query explained on the context.
+	ExplainedQuery struct {
+		Context string
+		Query   string
+		Plan    []engine.PlanNode
+		Err     error
+	}
+
+	QueryStats struct {
+		// Source names what ran the query, e.g. "query" for the query
+		// prompt or the details tab that loaded the table.
+		Source string
+		Took   time.Duration
+		Rows   int64
+		// Affected is set when Rows counts changed rows instead of
+		// returned ones.
+		Affected bool
+	}
+
+	FetchedServerInfo struct {
+		Engine  string
+		Version string
+	}
+
+	Notification struct {
+		Text string
+	}
+
+	// Reconnecting tells the connection of Context stopped answering.
+	// Done is set once it answers again, or with Err once it was given
+	// up on. Err is a context error when the wait was cancelled, with the
+	// connection kept.
+	Reconnecting struct {
+		Context string
+		Done    bool
+		Err     error
+	}
+
+	// Watch starts re-running the shown query every interval, or stops
+	// it when the interval is zero.
+	Watch struct {
+		Interval time.Duration
+	}
+
 	MoveFocus struct {
 		Direction direction.Direction
 	}
@@ -78,6 +171,21 @@ type (
 	NewContext struct {
 		DSN  string
 		Name string
+		TLS  cfg.TLS
 		OK   bool
 	}
+
+	// DiscoveredContexts holds the connections found outside the config.
+	// Err tells about the sources which couldn't be read.
+	DiscoveredContexts struct {
+		Found []cfg.Found
+		Err   error
+	}
+
+	// ImportContexts adds the connections picked out of the discovered
+	// ones.
+	ImportContexts struct {
+		Connections []cfg.Connection
+		OK          bool
+	}
 )
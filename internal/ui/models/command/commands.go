@@ -0,0 +1,38 @@
+package command
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	Run            key.Binding
+	Complete       key.Binding
+	NextSuggestion key.Binding
+	PrevSuggestion key.Binding
+	Forward        key.Binding
+	Backwards      key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Run:            keys.Get(keymap.CommandRun),
+		Complete:       keys.Get(keymap.CommandComplete),
+		NextSuggestion: keys.Get(keymap.CommandNextSuggestion),
+		PrevSuggestion: keys.Get(keymap.CommandPrevSuggestion),
+		Forward:        keys.Get(keymap.CommandForward),
+		Backwards:      keys.Get(keymap.CommandBackwards),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Run, k.Complete, k.Forward}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.Run, k.Forward, k.Backwards},
+		{k.Complete, k.NextSuggestion, k.PrevSuggestion},
+	}
+}
@@ -1,15 +1,25 @@
 package command
 
 import (
+	"context"
+	"fmt"
+	"slices"
 	"strings"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
 	"github.com/hrvadl/gowatchsql/internal/ui/command"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
 )
 
@@ -21,25 +31,47 @@ const (
 
 var inputStyles = lipgloss.NewStyle().MarginTop(margin)
 
-func NewModel() Model {
+type ConnectionsRepo interface {
+	GetConnections(context.Context) []cfg.Connection
+}
+
+func NewModel(connections ConnectionsRepo, km keymap.Map) Model {
 	input := textinput.New()
 	input.Focus()
 	input.Placeholder = placeholder
 	input.PromptStyle = lipgloss.NewStyle().Foreground(color.MainAccent)
-	return Model{
-		input: input,
+	input.ShowSuggestions = true
+
+	keys := newKeyMap(km)
+	input.KeyMap.AcceptSuggestion = keys.Complete
+	input.KeyMap.NextSuggestion = keys.NextSuggestion
+	input.KeyMap.PrevSuggestion = keys.PrevSuggestion
+	input.CompletionStyle = lipgloss.NewStyle().Foreground(color.Placeholder)
+
+	m := Model{
+		input:       input,
+		registry:    command.NewDefaultRegistry(),
+		connections: connections,
+		keys:        keys,
 		state: state{
 			active: true,
 		},
 	}
+	m.input.SetSuggestions(m.suggestions())
+
+	return m
 }
 
 type Model struct {
-	width      int
-	height     int
-	state      state
-	input      textinput.Model
-	currentCtx string
+	width       int
+	height      int
+	state       state
+	input       textinput.Model
+	currentCtx  string
+	registry    command.Registry
+	connections ConnectionsRepo
+	keys        keyMap
+	tables      []string
 }
 
 func (m Model) Init() tea.Cmd {
@@ -56,6 +88,8 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.handleFocus()
 	case message.SelectedContext:
 		return m.handleSelectedDB(msg)
+	case message.FetchedTableList:
+		return m.handleFetchedTableList(msg)
 	default:
 		return m, nil
 	}
@@ -66,15 +100,20 @@ func (m Model) View() string {
 	titleStyles := m.newTitleStyles()
 
 	title := titleStyles.Render("Command prompt")
-	if m.currentCtx != "" {
+	switch {
+	case m.state.err != nil:
+		title = titleStyles.Foreground(color.Error).Render(secret.Mask(m.state.err.Error()))
+	case m.state.hint != "":
+		title = titleStyles.Foreground(color.SecondaryText).Render(m.state.hint)
+	case m.currentCtx != "":
 		title = titleStyles.Foreground(color.MainAccent).Render("Context: " + m.currentCtx)
 	}
 
 	return barStyles.Render(title, inputStyles.Render(m.input.View()))
 }
 
-func (m Model) Help() string {
-	return "searchbar help"
+func (m Model) Help() help.KeyMap {
+	return m.keys
 }
 
 func (m Model) Value() string {
@@ -86,13 +125,26 @@ func (m Model) handleSelectedDB(msg message.SelectedContext) (Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) handleFetchedTableList(msg message.FetchedTableList) (Model, tea.Cmd) {
+	m.tables = make([]string, 0, len(msg.Tables))
+	for _, t := range msg.Tables {
+		m.tables = append(m.tables, t.Name)
+	}
+
+	m.input.SetSuggestions(m.suggestions())
+	return m, nil
+}
+
 func (m Model) handleFocus() (Model, tea.Cmd) {
 	m.input.Focus()
+	m.input.SetSuggestions(m.suggestions())
 	m.state.active = true
 	return m, nil
 }
 
 func (m Model) handleMoveFocus(to direction.Direction) (Model, tea.Cmd) {
+	m.state.err = nil
+	m.state.hint = ""
 	m.input.Blur()
 	m.state.active = false
 	return m, message.With(message.MoveFocus{Direction: to})
@@ -105,26 +157,103 @@ func (m Model) handleUpdateSize(w, h int) (Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyEnter() (Model, tea.Cmd) {
+	m.state.err = nil
+	m.state.hint = ""
+
+	parsed, err := m.registry.Parse(m.Value())
+	if err != nil {
+		m.state.err = err
+		return m, nil
+	}
+
+	if parsed.Name == command.Help {
+		return m.handleHelp(parsed.Arg(0))
+	}
+
+	if err := m.validateArgs(parsed); err != nil {
+		m.state.err = err
+		return m, nil
+	}
+
 	m.input.Blur()
 	m.state.active = false
-	val := m.Value()
+	m.input.Placeholder = m.Value()
 	m.input.SetValue("")
-	m.input.Placeholder = val
-	return m, message.With(message.Command{Text: command.Command(val)})
+	return m, message.With(message.Command{Parsed: parsed})
+}
+
+func (m Model) handleHelp(name string) (Model, tea.Cmd) {
+	m.input.SetValue("")
+	if name == "" {
+		names := make([]string, 0, len(m.registry.Specs()))
+		for _, s := range m.registry.Specs() {
+			names = append(names, string(s.Name))
+		}
+		m.state.hint = "Commands: " + strings.Join(names, ", ")
+		return m, nil
+	}
+
+	spec, ok := m.registry.Lookup(name)
+	if !ok {
+		m.state.err = fmt.Errorf("%w: unknown command %q", errs.ErrValidation, name)
+		return m, nil
+	}
+
+	m.state.hint = spec.Usage()
+	return m, nil
+}
+
+func (m Model) validateArgs(parsed command.Parsed) error {
+	arg := parsed.Arg(0)
+	if parsed.Name == command.Watch {
+		_, err := watch.ParseInterval(arg)
+		return err
+	}
+
+	known := m.knownArgs()[parsed.Name]
+	if arg == "" || len(known) == 0 || slices.Contains(known, arg) {
+		return nil
+	}
+
+	return fmt.Errorf("%w: unknown %s %q", errs.ErrValidation, parsed.Name, arg)
+}
+
+func (m Model) suggestions() []string {
+	return m.registry.Suggestions(m.knownArgs())
+}
+
+func (m Model) knownArgs() map[command.Command][]string {
+	args := map[command.Command][]string{
+		command.Tables: m.tables,
+		command.Watch:  {watch.Off},
+	}
+
+	for _, s := range m.registry.Specs() {
+		args[command.Help] = append(args[command.Help], string(s.Name))
+	}
+
+	if m.connections != nil {
+		for _, c := range m.connections.GetConnections(context.Background()) {
+			args[command.Context] = append(args[command.Context], c.Name)
+		}
+	}
+
+	return args
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
 	var cmd tea.Cmd
-	switch msg.Type {
-	case tea.KeyCtrlC:
+	switch {
+	case msg.Type == tea.KeyCtrlC:
 		return Model{}, tea.Quit
-	case tea.KeyEsc:
+	case key.Matches(msg, m.keys.Forward):
 		return m.handleMoveFocus(direction.Forward)
-	case tea.KeyShiftTab:
+	case key.Matches(msg, m.keys.Backwards):
 		return m.handleMoveFocus(direction.Backwards)
-	case tea.KeyEnter:
+	case key.Matches(msg, m.keys.Run):
 		return m.handleKeyEnter()
 	default:
+		m.state.err = nil
 		m.input, cmd = m.input.Update(msg)
 		return m, cmd
 	}
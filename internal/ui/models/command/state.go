@@ -2,4 +2,6 @@ package command
 
 type state struct {
 	active bool
+	err    error
+	hint   string
 }
@@ -0,0 +1,52 @@
+package activity
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+)
+
+const chainSeparator = " ← "
+
+// blockingChain follows the sessions blocking the given one up to the
+// session which blocks without waiting itself, e.g. "12 ← 7" when the
+// session waits for 12, which waits for 7. Only the first blocker of
+// each session is followed.
+func blockingChain(sessions map[int64]engine.Session, s engine.Session) string {
+	var (
+		chain   []string
+		visited = map[int64]bool{s.ID: true}
+	)
+
+	for len(s.BlockedBy) > 0 {
+		id := s.BlockedBy[0]
+		chain = append(chain, strconv.FormatInt(id, 10))
+		if visited[id] {
+			// Deadlocks are resolved by the server, but may still be
+			// seen for a moment.
+			chain = append(chain, "deadlock")
+			break
+		}
+		visited[id] = true
+
+		next, ok := sessions[id]
+		if !ok {
+			break
+		}
+		s = next
+	}
+
+	return strings.Join(chain, chainSeparator)
+}
+
+// blockers returns ids of the sessions other sessions wait for.
+func blockers(sessions []engine.Session) map[int64]bool {
+	ids := make(map[int64]bool)
+	for _, s := range sessions {
+		for _, id := range s.BlockedBy {
+			ids[id] = true
+		}
+	}
+	return ids
+}
@@ -0,0 +1,62 @@
+package activity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestBlockingChain(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	sessions := map[int64]engine.Session{
+		1: {ID: 1},
+		2: {ID: 2, BlockedBy: []int64{1}},
+		3: {ID: 3, BlockedBy: []int64{2, 1}},
+		4: {ID: 4, BlockedBy: []int64{5}},
+		5: {ID: 5, BlockedBy: []int64{4}},
+		6: {ID: 6, BlockedBy: []int64{42}},
+	}
+
+	tests := []struct {
+		name string
+		id   int64
+		want string
+	}{
+		{
+			name: "Should be empty for session which is not blocked",
+			id:   1,
+		},
+		{
+			name: "Should show direct blocker",
+			id:   2,
+			want: "1",
+		},
+		{
+			name: "Should follow blockers up to the root",
+			id:   3,
+			want: "2 ← 1",
+		},
+		{
+			name: "Should stop on deadlock",
+			id:   4,
+			want: "5 ← 4 ← deadlock",
+		},
+		{
+			name: "Should stop on blocker which is not listed",
+			id:   6,
+			want: "42",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, blockingChain(sessions, sessions[tt.id]))
+		})
+	}
+}
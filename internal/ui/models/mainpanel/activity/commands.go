@@ -0,0 +1,71 @@
+package activity
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	Refresh   key.Binding
+	Switch    key.Binding
+	Cancel    key.Binding
+	Terminate key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Refresh:   keys.Get(keymap.ActivityRefresh),
+		Switch:    keys.Get(keymap.ActivitySwitch),
+		Cancel:    keys.Get(keymap.ActivityCancel),
+		Terminate: keys.Get(keymap.ActivityTerminate),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Refresh, k.Switch, k.Cancel, k.Terminate}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type confirmKeyMap struct {
+	Confirm key.Binding
+	Abort   key.Binding
+}
+
+func newConfirmKeyMap(keys keymap.Map) confirmKeyMap {
+	return confirmKeyMap{
+		Confirm: keys.Get(keymap.ActivityConfirm),
+		Abort:   keys.Get(keymap.ActivityAbort),
+	}
+}
+
+func (k confirmKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Confirm, k.Abort}
+}
+
+func (k confirmKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type treeKeyMap struct {
+	Up   key.Binding
+	Down key.Binding
+}
+
+func newTreeKeyMap(keys keymap.Map) treeKeyMap {
+	return treeKeyMap{
+		Up:   keys.Get(keymap.TableRowUp),
+		Down: keys.Get(keymap.TableRowDown),
+	}
+}
+
+func (k treeKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down}
+}
+
+func (k treeKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
@@ -0,0 +1,62 @@
+package activity
+
+import (
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+)
+
+// handleStartConfirm holds back a signal to a session until it is
+// confirmed, as it aborts work of somebody else.
+func (m Model) handleStartConfirm(a action) (Model, tea.Cmd) {
+	m.state.pending = &a
+	return m, message.With(message.BlockCommandLine{})
+}
+
+func (m Model) handleConfirmKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.confirmKeys.Confirm):
+		a := *m.state.pending
+		m, cmd := m.handleStopConfirm()
+		return m, tea.Batch(cmd, m.commandSignal(a))
+	case key.Matches(msg, m.confirmKeys.Abort):
+		return m.handleStopConfirm()
+	default:
+		return m, nil
+	}
+}
+
+func (m Model) handleStopConfirm() (Model, tea.Cmd) {
+	m.state.pending = nil
+	return m, message.With(message.UnblockCommandLine{})
+}
+
+func (m Model) confirmView() string {
+	a := m.state.pending
+	width := max(m.width/2, 20)
+
+	text := lipgloss.NewStyle().Foreground(color.Text).Width(width)
+	query := lipgloss.NewStyle().Foreground(color.SecondaryText).Width(width)
+	hint := lipgloss.NewStyle().Foreground(color.Placeholder)
+
+	lines := []string{text.Render(fmt.Sprintf("Do you want to %s %d on %q?", a.verb(), a.session, m.context))}
+	if a.query != "" {
+		lines = append(lines, query.Render(a.query))
+	}
+	lines = append(lines, "", hint.Render(fmt.Sprintf(
+		"%s to confirm, %s to abort",
+		m.confirmKeys.Confirm.Help().Key,
+		m.confirmKeys.Abort.Help().Key,
+	)))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(color.Error).
+		Padding(0, padding).
+		Render(lipgloss.JoinVertical(lipgloss.Left, lines...))
+}
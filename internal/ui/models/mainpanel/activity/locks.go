@@ -0,0 +1,167 @@
+package activity
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+)
+
+const (
+	blockerMark = "● "
+	waiterMark  = "└ "
+)
+
+// lockTree shows who blocks whom, the sessions blocking others being the
+// roots.
+type lockTree struct {
+	roots  []engine.LockNode
+	cursor int
+	keys   treeKeyMap
+}
+
+type lockLine struct {
+	node  engine.LockNode
+	depth int
+}
+
+func newLockTree(keys treeKeyMap) lockTree {
+	return lockTree{keys: keys}
+}
+
+// WithLocks shows the tree of the locks, keeping the cursor on the
+// session it was on.
+func (t lockTree) WithLocks(locks []engine.Lock) lockTree {
+	selected, hasSelected := t.selected()
+
+	t.roots = engine.BuildLockTree(locks)
+	lines := t.lines()
+
+	t.cursor = min(t.cursor, max(len(lines)-1, 0))
+	if hasSelected {
+		for i, l := range lines {
+			if l.node.Session == selected.Session {
+				t.cursor = i
+				break
+			}
+		}
+	}
+
+	return t
+}
+
+func (t lockTree) Update(msg tea.Msg) lockTree {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return t
+	}
+
+	switch {
+	case key.Matches(km, t.keys.Down):
+		t.cursor = max(min(t.cursor+1, len(t.lines())-1), 0)
+	case key.Matches(km, t.keys.Up):
+		t.cursor = max(t.cursor-1, 0)
+	}
+
+	return t
+}
+
+func (t lockTree) View(width, height int) string {
+	lines := t.lines()
+	if len(lines) == 0 {
+		return lipgloss.NewStyle().Foreground(color.SecondaryText).Render("No session waits for a lock")
+	}
+
+	height = max(height, 1)
+	offset := max(t.cursor-height+1, 0)
+
+	rendered := make([]string, 0, height)
+	for i := offset; i < len(lines) && i < offset+height; i++ {
+		rendered = append(rendered, lockLineView(lines[i], i == t.cursor, width))
+	}
+
+	return strings.Join(rendered, "\n")
+}
+
+func (t lockTree) selected() (engine.LockNode, bool) {
+	lines := t.lines()
+	if t.cursor >= len(lines) {
+		return engine.LockNode{}, false
+	}
+	return lines[t.cursor].node, true
+}
+
+func (t lockTree) lines() []lockLine {
+	var lines []lockLine
+
+	var walk func(nodes []engine.LockNode, depth int)
+	walk = func(nodes []engine.LockNode, depth int) {
+		for _, n := range nodes {
+			lines = append(lines, lockLine{node: n, depth: depth})
+			walk(n.Children, depth+1)
+		}
+	}
+	walk(t.roots, 0)
+
+	return lines
+}
+
+func lockLineView(l lockLine, selected bool, width int) string {
+	n := l.node
+
+	mark, nameStyle := blockerMark, lipgloss.NewStyle().Foreground(color.Error).Bold(true)
+	if l.depth > 0 {
+		mark, nameStyle = waiterMark, lipgloss.NewStyle().Foreground(color.Changed)
+	}
+
+	name := fmt.Sprintf("%d", n.Session)
+	if n.User != "" {
+		name += " " + n.User
+	}
+
+	info := lipgloss.NewStyle().Foreground(color.SecondaryText)
+	line := strings.Repeat("  ", l.depth) + mark + nameStyle.Render(name)
+	if summary := lockSummary(n); summary != "" {
+		line += info.Render(" " + summary)
+	}
+	if n.Duration > 0 {
+		line += info.Render(" for " + n.Duration.Truncate(time.Second).String())
+	}
+	if n.Query != "" {
+		line += "  " + lipgloss.NewStyle().Foreground(color.Text).Render(strings.Join(strings.Fields(n.Query), " "))
+	}
+
+	style := lipgloss.NewStyle().MaxWidth(width)
+	if selected {
+		style = style.Reverse(true)
+	}
+
+	return style.Render(line)
+}
+
+// lockSummary tells what the session waits for, or what it holds when it
+// doesn't wait. The awaited locks go first, see engine.LockNode.
+func lockSummary(n engine.LockNode) string {
+	if len(n.Locks) == 0 {
+		return ""
+	}
+
+	l := n.Locks[0]
+	verb := "holds"
+	if !l.Granted {
+		verb = "waits for"
+	}
+
+	summary := fmt.Sprintf("%s %s on %s", verb, l.Mode, l.Object)
+	if more := len(n.Locks) - 1; more > 0 {
+		summary += fmt.Sprintf(" (+%d)", more)
+	}
+
+	return summary
+}
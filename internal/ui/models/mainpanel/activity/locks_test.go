@@ -0,0 +1,83 @@
+package activity
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestLockTree(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	down := tea.KeyMsg{Type: tea.KeyDown}
+
+	locks := []engine.Lock{
+		{Session: 1, Mode: "RowExclusiveLock", Object: "users", Granted: true},
+		{Session: 2, Mode: "AccessExclusiveLock", Object: "users", BlockedBy: []int64{1}},
+		{Session: 3, Mode: "AccessShareLock", Object: "users", BlockedBy: []int64{2}},
+	}
+
+	sessions := func(tree lockTree) []int64 {
+		var ids []int64
+		for _, l := range tree.lines() {
+			ids = append(ids, l.node.Session)
+		}
+		return ids
+	}
+
+	tests := []struct {
+		name        string
+		keys        []tea.KeyMsg
+		refreshed   []engine.Lock
+		want        []int64
+		wantCursor  int
+		wantSummary string
+	}{
+		{
+			name:        "Should put blocker first",
+			want:        []int64{1, 2, 3},
+			wantSummary: "holds RowExclusiveLock on users",
+		},
+		{
+			name:        "Should move cursor to waiter",
+			keys:        []tea.KeyMsg{down, down, down},
+			want:        []int64{1, 2, 3},
+			wantCursor:  2,
+			wantSummary: "waits for AccessShareLock on users",
+		},
+		{
+			name:        "Should keep cursor on session after refresh",
+			keys:        []tea.KeyMsg{down},
+			refreshed:   locks[:2],
+			want:        []int64{1, 2},
+			wantCursor:  1,
+			wantSummary: "waits for AccessExclusiveLock on users",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tree := newLockTree(newTreeKeyMap(keymap.Default())).WithLocks(locks)
+			for _, k := range tt.keys {
+				tree = tree.Update(k)
+			}
+			if tt.refreshed != nil {
+				tree = tree.WithLocks(tt.refreshed)
+			}
+
+			require.Equal(t, tt.want, sessions(tree))
+			require.Equal(t, tt.wantCursor, tree.cursor)
+
+			selected, ok := tree.selected()
+			require.True(t, ok)
+			require.Equal(t, tt.wantSummary, lockSummary(selected))
+		})
+	}
+}
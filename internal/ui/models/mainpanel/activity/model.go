@@ -0,0 +1,486 @@
+package activity
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/pkg/direction"
+	"github.com/hrvadl/gowatchsql/pkg/overlay"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
+	"github.com/hrvadl/gowatchsql/pkg/xtable"
+)
+
+const (
+	padding         = 1
+	titleHeight     = 2
+	refreshInterval = time.Second * 3
+)
+
+var columns = []string{"ID", "User", "Database", "Client", "State", "Wait", "Time", "Blocked by", "Query"}
+
+// Mode is what the screen shows.
+type Mode int
+
+const (
+	SessionsMode Mode = iota
+	LocksMode
+)
+
+// Tick asks for the next refresh of the sessions.
+type Tick struct {
+	id int
+}
+
+// Result holds the sessions or the locks fetched by one refresh.
+type Result struct {
+	id       int
+	Sessions []engine.Session
+	Locks    []engine.Lock
+	Err      error
+}
+
+func NewModel(keys keymap.Map) Model {
+	return Model{
+		keys:        newKeyMap(keys),
+		confirmKeys: newConfirmKeyMap(keys),
+		tableKeys:   keys.Table(),
+		treeKeys:    newTreeKeyMap(keys),
+		locks:       newLockTree(newTreeKeyMap(keys)),
+	}
+}
+
+// Model lists the sessions of the server, or the locks they wait for,
+// refreshing them while open.
+type Model struct {
+	width  int
+	height int
+
+	explorer    engine.Explorer
+	timeouts    cfg.Timeouts
+	context     string
+	environment cfg.Environment
+
+	sessions  []engine.Session
+	updatedAt time.Time
+	table     xtable.Model
+	locks     lockTree
+	mode      Mode
+
+	// open is set while the screen is shown, refresh is the id of the
+	// refresh loop, so ticks of a stopped loop are told apart.
+	open    bool
+	refresh int
+
+	keys        keyMap
+	confirmKeys confirmKeyMap
+	tableKeys   table.KeyMap
+	treeKeys    treeKeyMap
+	state       state
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.handleUpdateSize(msg)
+	case tea.KeyMsg:
+		return m.handleKeyPress(msg)
+	case message.MoveFocus:
+		return m.handleMoveFocus(msg)
+	case message.SelectedContext:
+		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		return m.handleExplorerReady(msg)
+	case message.ExplorerFailed:
+		m.state.status = errored
+		m.state.err = msg.Err
+		return m, nil
+	case Tick:
+		return m.handleTick(msg)
+	case Result:
+		return m.handleResult(msg)
+	default:
+		return m, nil
+	}
+}
+
+func (m Model) View() string {
+	title := m.newTitleStyles().Render(m.titleView())
+	panel := m.newStyles().Render(lipgloss.JoinVertical(lipgloss.Left, title, m.contentView()))
+
+	if m.state.pending == nil {
+		return panel
+	}
+
+	return overlay.Place(m.width/4, m.height/4, m.confirmView(), panel, true)
+}
+
+func (m Model) Help() help.KeyMap {
+	if m.state.pending != nil {
+		return m.confirmKeys
+	}
+	if m.mode == LocksMode {
+		return xhelp.Join(m.keys, m.treeKeys)
+	}
+	return xhelp.Join(m.keys, xtable.NewHelp(m.tableKeys))
+}
+
+// Open shows the sessions or the locks and keeps refreshing them until
+// Close.
+func (m Model) Open(mode Mode) (Model, tea.Cmd) {
+	m.open = true
+	return m.setMode(mode)
+}
+
+func (m Model) setMode(mode Mode) (Model, tea.Cmd) {
+	if mode != m.mode && m.state.status == ready {
+		m.state.status = loading
+	}
+	m.mode = mode
+	return m.startRefresh()
+}
+
+// Close stops refreshing the sessions.
+func (m Model) Close() Model {
+	m.open = false
+	m.refresh++
+	return m
+}
+
+func (m Model) handleUpdateSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
+	m.width = msg.Width - 2
+	m.height = msg.Height - 2
+	m.table = m.table.WithMaxTotalWidth(m.width - padding*2)
+	return m, nil
+}
+
+func (m Model) handleMoveFocus(msg message.MoveFocus) (Model, tea.Cmd) {
+	if msg.Direction == direction.Away {
+		m.state.active = false
+		return m, nil
+	}
+
+	m.state.active = !m.state.active
+	return m, nil
+}
+
+func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.context = msg.Name
+	m.environment = msg.Environment
+	m.sessions = nil
+	m.table = xtable.Model{}
+	m.locks = m.locks.WithLocks(nil)
+
+	// A session picked on the previous context must not be signalled on
+	// this one.
+	var stopCmd tea.Cmd
+	if m.state.pending != nil {
+		m, stopCmd = m.handleStopConfirm()
+	}
+
+	m.explorer = nil
+	m.state.err = nil
+	m.state.status = empty
+	if m.open {
+		m.state.status = loading
+	}
+
+	return m, stopCmd
+}
+
+func (m Model) handleExplorerReady(msg message.ExplorerReady) (Model, tea.Cmd) {
+	m.explorer = msg.Explorer
+	if !m.open {
+		return m, nil
+	}
+
+	return m.startRefresh()
+}
+
+// startRefresh fetches the sessions now, dropping the previous refresh
+// loop so there is only one running.
+func (m Model) startRefresh() (Model, tea.Cmd) {
+	m.refresh++
+	if m.explorer == nil {
+		return m, nil
+	}
+
+	if m.state.status != ready {
+		m.state.status = loading
+	}
+
+	return m, m.commandFetch(m.refresh)
+}
+
+func (m Model) handleTick(msg Tick) (Model, tea.Cmd) {
+	if msg.id != m.refresh || !m.open || m.explorer == nil {
+		return m, nil
+	}
+	return m, m.commandFetch(m.refresh)
+}
+
+func (m Model) handleResult(msg Result) (Model, tea.Cmd) {
+	if msg.id != m.refresh {
+		return m, nil
+	}
+
+	switch {
+	case errors.Is(msg.Err, engine.ErrNotSupported):
+		m.state.status = unsupported
+		return m, nil
+	case msg.Err != nil:
+		m.state.status = errored
+		m.state.err = msg.Err
+	case m.mode == LocksMode:
+		m.state.status = ready
+		m.state.err = nil
+		m.locks = m.locks.WithLocks(msg.Locks)
+		m.updatedAt = time.Now()
+	default:
+		m.state.status = ready
+		m.state.err = nil
+		m = m.setSessions(msg.Sessions)
+	}
+
+	id := msg.id
+	return m, tea.Tick(refreshInterval, func(time.Time) tea.Msg {
+		return Tick{id: id}
+	})
+}
+
+// setSessions shows the sessions running longest first, keeping the
+// cursor on the session it was on.
+func (m Model) setSessions(sessions []engine.Session) Model {
+	selected, hasSelected := m.selected()
+
+	m.sessions = slices.Clone(sessions)
+	slices.SortStableFunc(m.sessions, func(a, b engine.Session) int {
+		return cmp.Compare(b.Duration, a.Duration)
+	})
+	m.updatedAt = time.Now()
+
+	byID := make(map[int64]engine.Session, len(m.sessions))
+	for _, s := range m.sessions {
+		byID[s.ID] = s
+	}
+	blocking := blockers(m.sessions)
+
+	rows := make([][]string, 0, len(m.sessions))
+	styles := make([]lipgloss.Style, 0, len(m.sessions))
+	cursor := 0
+	for i, s := range m.sessions {
+		rows = append(rows, []string{
+			strconv.FormatInt(s.ID, 10),
+			s.User,
+			s.Database,
+			s.Client,
+			s.State,
+			s.Wait,
+			s.Duration.Truncate(time.Second).String(),
+			blockingChain(byID, s),
+			strings.Join(strings.Fields(s.Query), " "),
+		})
+		styles = append(styles, rowStyle(s, blocking[s.ID]))
+
+		if hasSelected && s.ID == selected.ID {
+			cursor = i
+		}
+	}
+
+	m.table = xtable.New(columns, rows).
+		WithKeyMap(m.tableKeys).
+		WithRowStyles(styles).
+		WithMaxTotalWidth(m.width - padding*2).
+		WithHighlightedRow(cursor)
+
+	return m
+}
+
+func rowStyle(s engine.Session, blocking bool) lipgloss.Style {
+	style := lipgloss.NewStyle()
+	switch {
+	case blocking:
+		return style.Foreground(color.Error).Bold(true)
+	case len(s.BlockedBy) > 0:
+		return style.Foreground(color.Changed)
+	default:
+		return style
+	}
+}
+
+func (m Model) selected() (engine.Session, bool) {
+	i := m.table.HighlightedRow()
+	if i < 0 || i >= len(m.sessions) {
+		return engine.Session{}, false
+	}
+	return m.sessions[i], true
+}
+
+func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
+	if m.state.pending != nil {
+		return m.handleConfirmKeyPress(msg)
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Refresh):
+		return m.startRefresh()
+	case key.Matches(msg, m.keys.Switch):
+		return m.setMode((m.mode + 1) % (LocksMode + 1))
+	case key.Matches(msg, m.keys.Cancel):
+		return m.handleAction(cancelQuery)
+	case key.Matches(msg, m.keys.Terminate):
+		return m.handleAction(terminateSession)
+	case m.mode == LocksMode:
+		m.locks = m.locks.Update(msg)
+		return m, nil
+	default:
+		table, cmd := m.table.Update(msg)
+		m.table = table
+		return m, cmd
+	}
+}
+
+func (m Model) handleAction(kind actionKind) (Model, tea.Cmd) {
+	if m.state.status != ready {
+		return m, nil
+	}
+
+	if m.mode == LocksMode {
+		n, ok := m.locks.selected()
+		if !ok {
+			return m, nil
+		}
+		return m.handleStartConfirm(action{kind: kind, session: n.Session, query: n.Query})
+	}
+
+	s, ok := m.selected()
+	if !ok {
+		return m, nil
+	}
+
+	return m.handleStartConfirm(action{kind: kind, session: s.ID, query: s.Query})
+}
+
+func (m Model) commandFetch(id int) tea.Cmd {
+	explorer, timeout, mode := m.explorer, m.timeouts.Query, m.mode
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		if mode == LocksMode {
+			locks, err := explorer.GetLocks(ctx)
+			return Result{id: id, Locks: locks, Err: err}
+		}
+
+		sessions, err := explorer.GetActivity(ctx)
+		return Result{id: id, Sessions: sessions, Err: err}
+	}
+}
+
+func (m Model) commandSignal(a action) tea.Cmd {
+	explorer, timeout := m.explorer, m.timeouts.Query
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		signal, done := explorer.CancelQuery, "Cancelled the query of session %d"
+		if a.kind == terminateSession {
+			signal, done = explorer.TerminateSession, "Terminated session %d"
+		}
+
+		if err := signal(ctx, a.session); err != nil {
+			return message.Error{Err: err}
+		}
+
+		return message.Notification{Text: fmt.Sprintf(done, a.session)}
+	}
+}
+
+func (m Model) titleView() string {
+	title, count := "Activity", fmt.Sprintf("%d sessions", len(m.sessions))
+	if m.mode == LocksMode {
+		title, count = "Locks", fmt.Sprintf("%d blocking", len(m.locks.roots))
+	}
+
+	if m.context != "" {
+		title += " - " + m.context
+	}
+
+	if m.state.status == ready {
+		title += lipgloss.NewStyle().
+			Foreground(color.SecondaryText).
+			Render(fmt.Sprintf("  %s, updated %s", count, m.updatedAt.Format(time.TimeOnly)))
+	}
+
+	return title
+}
+
+func (m Model) contentView() string {
+	hint := lipgloss.NewStyle().Foreground(color.SecondaryText)
+	switch m.state.status {
+	case empty:
+		return hint.Render("Select a context to see its sessions")
+	case loading:
+		return hint.Render("Loading...")
+	case unsupported:
+		return hint.Render("Sessions and locks are not supported by the engine of this context")
+	case errored:
+		return lipgloss.NewStyle().Foreground(color.Error).Render(secret.Mask(m.state.err.Error()))
+	}
+
+	if m.mode == LocksMode {
+		return m.locks.View(m.width-padding*2, m.height-titleHeight)
+	}
+	return m.table.View()
+}
+
+func (m Model) newStyles() lipgloss.Style {
+	base := lipgloss.
+		NewStyle().
+		Height(m.height).
+		Width(m.width).
+		Padding(0, padding).
+		Border(lipgloss.NormalBorder())
+
+	accent, border := color.MainAccent, color.Border
+	if m.environment.IsProd() {
+		accent, border = color.Error, color.Error
+	}
+
+	if m.state.active {
+		return base.Border(lipgloss.ThickBorder()).
+			BorderForeground(accent)
+	}
+
+	return base.BorderForeground(border)
+}
+
+func (m Model) newTitleStyles() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Border(lipgloss.NormalBorder(), false, false, true, false).
+		BorderForeground(color.Border).
+		Bold(true).
+		Width(m.width - padding*2)
+}
@@ -0,0 +1,117 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestModelSignal(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	var (
+		down = tea.KeyMsg{Type: tea.KeyDown}
+		c    = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'c'}}
+		tKey = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'t'}}
+		y    = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'y'}}
+		n    = tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune{'n'}}
+	)
+
+	sessions := []engine.Session{
+		{ID: 10, Query: "SELECT 1", Duration: time.Second},
+		{ID: 20, Query: "SELECT pg_sleep(60)", Duration: time.Minute},
+	}
+
+	tests := []struct {
+		name          string
+		keys          []tea.KeyMsg
+		wantCancel    int64
+		wantTerminate int64
+	}{
+		{
+			name:       "Should cancel query of the longest session",
+			keys:       []tea.KeyMsg{c, y},
+			wantCancel: 20,
+		},
+		{
+			name:          "Should terminate selected session",
+			keys:          []tea.KeyMsg{down, tKey, y},
+			wantTerminate: 10,
+		},
+		{
+			name: "Should not signal session when aborted",
+			keys: []tea.KeyMsg{c, n, y},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			explorer := engine.NewMockExplorer(ctrl)
+			explorer.EXPECT().GetActivity(gomock.Any()).Return(sessions, nil)
+			if tt.wantCancel != 0 {
+				explorer.EXPECT().CancelQuery(gomock.Any(), tt.wantCancel).Return(nil)
+			}
+			if tt.wantTerminate != 0 {
+				explorer.EXPECT().TerminateSession(gomock.Any(), tt.wantTerminate).Return(nil)
+			}
+
+			m := NewModel(keymap.Default())
+			m, _ = m.Update(tea.WindowSizeMsg{Width: 200, Height: 50})
+			m, _ = m.Update(message.SelectedContext{Name: "local", DSN: "dsn"})
+			m, _ = m.Update(message.ExplorerReady{Context: "local", Explorer: explorer})
+			m, cmd := m.Open(SessionsMode)
+			m, _ = m.Update(cmd())
+
+			for _, k := range tt.keys {
+				m, cmd = m.Update(k)
+				if cmd != nil {
+					runAll(cmd)
+				}
+			}
+
+			require.Nil(t, m.state.pending)
+		})
+	}
+}
+
+func TestModelNotSupported(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	explorer := engine.NewMockExplorer(ctrl)
+	explorer.EXPECT().GetActivity(gomock.Any()).Return(nil, engine.ErrNotSupported)
+
+	m := NewModel(keymap.Default())
+	m, _ = m.Update(message.SelectedContext{Name: "file", DSN: "test.db"})
+	m, _ = m.Update(message.ExplorerReady{Context: "file", Explorer: explorer})
+	m, cmd := m.Open(SessionsMode)
+	m, cmd = m.Update(cmd())
+
+	require.Equal(t, unsupported, m.state.status)
+	require.Nil(t, cmd, "refresh must stop")
+}
+
+// runAll runs the command and the commands it batches.
+func runAll(cmd tea.Cmd) {
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, c := range batch {
+			if c != nil {
+				runAll(c)
+			}
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package activity
+
+type status int
+
+const (
+	empty status = iota
+	loading
+	ready
+	errored
+	unsupported
+)
+
+type state struct {
+	status status
+	active bool
+	err    error
+	// pending is the action waiting for a confirmation, nil when there
+	// is none.
+	pending *action
+}
+
+type actionKind int
+
+const (
+	cancelQuery actionKind = iota
+	terminateSession
+)
+
+// action is a signal to send to a session.
+type action struct {
+	kind    actionKind
+	session int64
+	query   string
+}
+
+func (a action) verb() string {
+	if a.kind == terminateSession {
+		return "terminate session"
+	}
+	return "cancel the query of session"
+}
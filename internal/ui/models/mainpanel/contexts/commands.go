@@ -1,6 +1,35 @@
 package contexts
 
-const (
-	toggleCreateForm = "N"
-	deleteContext    = "d"
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 )
+
+type keyMap struct {
+	Select key.Binding
+	Create key.Binding
+	Import key.Binding
+	Delete key.Binding
+	Cancel key.Binding
+	Retry  key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Select: keys.Get(keymap.ContextsSelect),
+		Create: keys.Get(keymap.ContextsCreate),
+		Import: keys.Get(keymap.ContextsImport),
+		Delete: keys.Get(keymap.ContextsDelete),
+		Cancel: keys.Get(keymap.ContextsCancel),
+		Retry:  keys.Get(keymap.ContextsRetry),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Select, k.Create, k.Import, k.Delete, k.Cancel, k.Retry}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
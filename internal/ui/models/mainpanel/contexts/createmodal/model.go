@@ -1,18 +1,26 @@
 package createmodal
 
 import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/huh"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
 const margin = 1
 
-func NewModel() Model {
+func NewModel(keys keymap.Map) Model {
 	return Model{
 		form: newForm(),
+		keys: newKeyMap(keys),
 	}
 }
 
@@ -20,6 +28,7 @@ type Model struct {
 	width  int
 	height int
 	form   *huh.Form
+	keys   keyMap
 }
 
 func (m Model) Init() tea.Cmd {
@@ -42,9 +51,13 @@ func (m Model) View() string {
 	return s.Render(m.form.View())
 }
 
+func (m Model) Help() help.KeyMap {
+	return xhelp.Join(xhelp.Bindings{m.keys.Cancel}, xhelp.Bindings(m.form.KeyBinds()))
+}
+
 func (m Model) handleKeyMessage(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEsc:
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
 		return m.handleFormCompleted()
 	default:
 		return m.handleDefault(msg)
@@ -63,10 +76,17 @@ func (m Model) handleDefault(msg tea.Msg) (Model, tea.Cmd) {
 }
 
 func (m Model) handleFormCompleted() (Model, tea.Cmd) {
-	dsn := m.form.GetString("dsn")
+	dsn := strings.TrimSpace(m.form.GetString("dsn"))
 	msg := message.NewContext{
 		Name: formatEmodjiBasedOnDB(dsn, m.form.GetString("name")),
 		DSN:  dsn,
+		TLS: cfg.TLS{
+			Mode:       cfg.TLSMode(m.form.GetString("tls_mode")),
+			CAFile:     strings.TrimSpace(m.form.GetString("tls_ca")),
+			CertFile:   strings.TrimSpace(m.form.GetString("tls_cert")),
+			KeyFile:    strings.TrimSpace(m.form.GetString("tls_key")),
+			ServerName: strings.TrimSpace(m.form.GetString("tls_server_name")),
+		},
 	}
 
 	if done := m.form.GetBool("done"); done {
@@ -2,9 +2,9 @@ package createmodal
 
 import (
 	"github.com/charmbracelet/huh"
-	"github.com/charmbracelet/lipgloss"
 
-	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/ui/styles"
 )
 
 func newForm() *huh.Form {
@@ -19,6 +19,31 @@ func newForm() *huh.Form {
 		Title("DSN").
 		Placeholder("mysql://root:notrealpassword@(0.0.0.0:3306)/test")
 
+	tlsMode := huh.NewSelect[string]().
+		Key("tls_mode").
+		Title("TLS").
+		Options(newTLSModeOptions()...)
+
+	caInput := huh.NewInput().
+		Key("tls_ca").
+		Title("CA bundle").
+		Placeholder("~/certs/ca.pem")
+
+	certInput := huh.NewInput().
+		Key("tls_cert").
+		Title("Client certificate").
+		Placeholder("~/certs/client.pem")
+
+	keyInput := huh.NewInput().
+		Key("tls_key").
+		Title("Client key").
+		Placeholder("~/certs/client.key")
+
+	serverNameInput := huh.NewInput().
+		Key("tls_server_name").
+		Title("Server name").
+		Placeholder("db.internal")
+
 	confirm := huh.NewConfirm().
 		Key("done").
 		Title("Are you sure?").
@@ -30,43 +55,25 @@ func newForm() *huh.Form {
 			nameInput,
 			dsnInput,
 		),
+		huh.NewGroup(
+			tlsMode,
+			caInput,
+			certInput,
+			keyInput,
+			serverNameInput,
+		),
 		huh.NewGroup(
 			confirm,
 		),
-	).WithTheme(newHuhTheme()).WithShowHelp(false)
+	).WithTheme(styles.NewForForm()).WithShowHelp(false)
 
 	return form
 }
 
-func newHuhTheme() *huh.Theme {
-	return &huh.Theme{
-		Form: lipgloss.NewStyle().
-			Border(lipgloss.NormalBorder()).
-			BorderForeground(color.Border),
-		Group: lipgloss.NewStyle(),
-		Blurred: huh.FieldStyles{
-			TextInput: newTextInputStyles(),
-		},
-		Focused: huh.FieldStyles{
-			BlurredButton: lipgloss.NewStyle().
-				Foreground(color.Text).
-				Padding(0, 1).
-				Margin(1),
-			FocusedButton: lipgloss.NewStyle().
-				Foreground(color.Text).
-				Background(color.MainAccent).
-				Padding(0, 1).
-				Margin(1),
-			TextInput: newTextInputStyles(),
-		},
-	}
-}
-
-func newTextInputStyles() huh.TextInputStyles {
-	return huh.TextInputStyles{
-		Placeholder: lipgloss.NewStyle().Foreground(color.Placeholder),
-		Text:        lipgloss.NewStyle().Foreground(color.Text),
-		Cursor:      lipgloss.NewStyle().Foreground(color.Text),
-		Prompt:      lipgloss.NewStyle().Foreground(color.MainAccent),
+func newTLSModeOptions() []huh.Option[string] {
+	options := []huh.Option[string]{huh.NewOption("driver default", "")}
+	for _, mode := range cfg.TLSModes {
+		options = append(options, huh.NewOption(string(mode), string(mode)))
 	}
+	return options
 }
@@ -0,0 +1,17 @@
+package importmodal
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	Cancel key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Cancel: keys.Get(keymap.ContextsCancelForm),
+	}
+}
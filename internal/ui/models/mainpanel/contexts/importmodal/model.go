@@ -0,0 +1,111 @@
+package importmodal
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
+)
+
+const margin = 1
+
+// NewModel lets the user pick which of the found connections to add.
+func NewModel(keys keymap.Map, found []cfg.Found) Model {
+	return Model{
+		form:  newForm(found),
+		found: found,
+		keys:  newKeyMap(keys),
+	}
+}
+
+type Model struct {
+	width  int
+	height int
+	form   *huh.Form
+	found  []cfg.Found
+	keys   keyMap
+	// done is set once the picked connections are sent, after which
+	// the form takes no more input.
+	done bool
+}
+
+func (m Model) Init() tea.Cmd {
+	return m.form.Init()
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if m.done {
+		return m, nil
+	}
+
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		return m.handleUpdateSize(msg.Width-margin*2, msg.Height-margin*2)
+	case tea.KeyMsg:
+		return m.handleKeyMessage(msg)
+	default:
+		return m.handleDefault(msg)
+	}
+}
+
+func (m Model) View() string {
+	return lipgloss.NewStyle().
+		Height(m.height).
+		Width(m.width).
+		Render(m.form.View())
+}
+
+func (m Model) Help() help.KeyMap {
+	return xhelp.Join(xhelp.Bindings{m.keys.Cancel}, xhelp.Bindings(m.form.KeyBinds()))
+}
+
+func (m Model) handleKeyMessage(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Cancel):
+		m.done = true
+		return m, message.With(message.ImportContexts{})
+	default:
+		return m.handleDefault(msg)
+	}
+}
+
+func (m Model) handleDefault(msg tea.Msg) (Model, tea.Cmd) {
+	m, cmd := m.delegateToForm(msg)
+	if m.form.State == huh.StateNormal {
+		return m, cmd
+	}
+
+	m.done = true
+	return m, tea.Batch(cmd, message.With(m.picked()))
+}
+
+// picked returns the connections picked once the form is confirmed.
+func (m Model) picked() message.ImportContexts {
+	msg := message.ImportContexts{OK: m.form.State == huh.StateCompleted && m.form.GetBool("done")}
+	indexes, _ := m.form.Get("found").([]int)
+	for _, i := range indexes {
+		msg.Connections = append(msg.Connections, m.found[i].Connection)
+	}
+	return msg
+}
+
+func (m Model) handleUpdateSize(w, h int) (Model, tea.Cmd) {
+	m.width = w
+	m.height = h
+	m.form = m.form.WithWidth(w).WithHeight(h)
+	return m.delegateToForm(tea.WindowSizeMsg{Height: h, Width: w})
+}
+
+func (m Model) delegateToForm(msg tea.Msg) (Model, tea.Cmd) {
+	form, cmd := m.form.Update(msg)
+	if f, ok := form.(*huh.Form); ok {
+		m.form = f
+	}
+	return m, cmd
+}
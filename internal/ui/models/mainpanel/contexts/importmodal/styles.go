@@ -0,0 +1,43 @@
+package importmodal
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/huh"
+
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
+	"github.com/hrvadl/gowatchsql/internal/ui/styles"
+)
+
+const maxVisibleOptions = 10
+
+func newForm(found []cfg.Found) *huh.Form {
+	options := make([]huh.Option[int], 0, len(found))
+	for i, f := range found {
+		label := strings.Join([]string{f.Connection.Name, secret.Mask(f.Connection.DSN), f.Source}, " · ")
+		options = append(options, huh.NewOption(label, i))
+	}
+
+	picker := huh.NewMultiSelect[int]().
+		Key("found").
+		Title("Found connections").
+		Description("Pick the ones to add to the contexts").
+		Options(options...).
+		Height(min(len(options), maxVisibleOptions) + 2)
+
+	confirm := huh.NewConfirm().
+		Key("done").
+		Title("Add the picked connections?").
+		Affirmative("Yes").
+		Negative("No")
+
+	return huh.NewForm(
+		huh.NewGroup(
+			picker,
+		),
+		huh.NewGroup(
+			confirm,
+		),
+	).WithTheme(styles.NewForForm()).WithShowHelp(false)
+}
@@ -1,23 +1,60 @@
 package contexts
 
 import (
-	"github.com/charmbracelet/bubbles/list"
+	"strings"
 
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 )
 
-func newItemFromContext(ctx message.NewContext) list.Item {
+func newItemFromConnection(c cfg.Connection) ctxItem {
 	return ctxItem{
-		name: ctx.Name,
-		dsn:  ctx.DSN,
+		name:        c.Name,
+		dsn:         c.DSN,
+		driver:      c.Driver,
+		timeouts:    c.Timeouts,
+		readOnly:    c.ReadOnly,
+		environment: c.Environment,
 	}
 }
 
 type ctxItem struct {
-	name string
-	dsn  string
+	name        string
+	dsn         string
+	driver      string
+	timeouts    cfg.Timeouts
+	readOnly    bool
+	environment cfg.Environment
+	// status tells how connecting to the context goes, set only on the
+	// selected one.
+	status string
 }
 
 func (i ctxItem) Title() string       { return i.name }
-func (i ctxItem) Description() string { return i.dsn }
 func (i ctxItem) FilterValue() string { return i.name }
+
+func (i ctxItem) Description() string {
+	tags := make([]string, 0, 4)
+	if i.status != "" {
+		tags = append(tags, i.status)
+	}
+	if i.environment != "" {
+		tags = append(tags, string(i.environment))
+	}
+	if i.readOnly {
+		tags = append(tags, "read-only")
+	}
+	return strings.Join(append(tags, secret.Mask(i.dsn)), " · ")
+}
+
+func (i ctxItem) selected() message.SelectedContext {
+	return message.SelectedContext{
+		Name:        i.name,
+		DSN:         i.dsn,
+		Driver:      i.driver,
+		Timeouts:    i.timeouts,
+		ReadOnly:    i.readOnly,
+		Environment: i.environment,
+	}
+}
@@ -2,21 +2,28 @@ package contexts
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"slices"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/contexts/createmodal"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/contexts/importmodal"
 	"github.com/hrvadl/gowatchsql/internal/ui/styles"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
 const margin = 1
@@ -25,21 +32,30 @@ const margin = 1
 type ConnectionsRepo interface {
 	GetConnections(context.Context) []cfg.Connection
 	DeleteConnection(ctx context.Context, dsn string) error
+	SaveConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	ImportConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	Discover(ctx context.Context) ([]cfg.Found, error)
 }
 
-func NewModel(connections ConnectionsRepo) *Model {
+func NewModel(connections ConnectionsRepo, keys keymap.Map) *Model {
 	item := list.NewDefaultDelegate()
 	item.Styles = styles.NewForItemDelegate()
-	list := newList(item, []list.Item{})
+	list := newList(item, []list.Item{}, keys)
+
+	s := spinner.New()
+	s.Spinner = spinner.Dot
 
 	return &Model{
-		List: list,
+		List:    list,
+		spinner: s,
 		state: state{
 			active:     false,
 			formActive: false,
 		},
-		newCtx:      createmodal.NewModel(),
+		newCtx:      createmodal.NewModel(keys),
 		connections: connections,
+		keys:        newKeyMap(keys),
+		bindings:    keys,
 	}
 }
 
@@ -49,7 +65,14 @@ type Model struct {
 	List        list.Model
 	state       state
 	newCtx      createmodal.Model
+	importer    importmodal.Model
 	connections ConnectionsRepo
+	keys        keyMap
+	bindings    keymap.Map
+
+	// selected is the context connected to last, kept to retry it.
+	selected message.SelectedContext
+	spinner  spinner.Model
 }
 
 func (m *Model) Init() tea.Cmd {
@@ -63,15 +86,14 @@ func (m *Model) Init() tea.Cmd {
 
 	listItems := make([]list.Item, 0, len(connections))
 	for _, connection := range connections {
-		msg := message.NewContext{OK: true, DSN: connection.DSN, Name: connection.Name}
-		listItems = append(listItems, newItemFromContext(msg))
+		listItems = append(listItems, newItemFromConnection(connection))
 	}
 
 	item := list.NewDefaultDelegate()
 	item.Styles = styles.NewForItemDelegate()
-	m.List = newList(item, listItems)
+	m.List = newList(item, listItems, m.bindings)
 
-	return message.With(message.SelectedContext{Name: connections[0].Name, DSN: connections[0].DSN})
+	return message.With(newItemFromConnection(connections[0]).selected())
 }
 
 func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
@@ -91,6 +113,18 @@ func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.handleMoveFocus(msg)
 	case message.NewContext:
 		return m.handleNewContext(msg)
+	case message.DiscoveredContexts:
+		return m.handleDiscoveredContexts(msg)
+	case message.ImportContexts:
+		return m.handleImportContexts(msg)
+	case message.SelectedContext:
+		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		return m.handleConnected(msg.Context, nil)
+	case message.ExplorerFailed:
+		return m.handleConnected(msg.Context, msg.Err)
+	case spinner.TickMsg:
+		return m.handleSpinnerTick(msg)
 	default:
 		return m.delegateToActive(msg)
 	}
@@ -98,15 +132,32 @@ func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 
 func (m Model) View() string {
 	s := m.newContainerStyles()
-	if !m.state.formActive {
+	switch {
+	case m.state.formActive:
+		return s.Padding(1, 2).Render(m.newCtx.View())
+	case m.state.importActive:
+		return s.Padding(1, 2).Render(m.importer.View())
+	default:
 		return s.Render(m.List.View())
 	}
+}
 
-	return s.Padding(1, 2).Render(m.newCtx.View())
+// Select highlights the context with the given name and makes it the
+// current one.
+func (m *Model) Select(name string) (*Model, tea.Cmd) {
+	mm, cmd := m.handleSelectByName(name)
+	return &mm, cmd
 }
 
-func (m Model) Help() string {
-	return "help from contexts"
+func (m Model) Help() help.KeyMap {
+	switch {
+	case m.state.formActive:
+		return m.newCtx.Help()
+	case m.state.importActive:
+		return m.importer.Help()
+	default:
+		return xhelp.Join(m.keys, m.List)
+	}
 }
 
 func (m Model) handleNewContext(msg message.NewContext) (Model, tea.Cmd) {
@@ -115,8 +166,96 @@ func (m Model) handleNewContext(msg message.NewContext) (Model, tea.Cmd) {
 		return m, cmd
 	}
 
-	newItems := append(m.List.Items(), newItemFromContext(msg))
-	return m, tea.Batch(cmd, m.List.SetItems(newItems))
+	// The context is saved right away, so its password is taken out of
+	// the DSN before it is shown or connected to.
+	conn := cfg.Connection{Name: msg.Name, DSN: msg.DSN, TLS: msg.TLS}
+	saved, err := m.connections.SaveConnection(context.Background(), conn)
+	if err != nil {
+		slog.Error("Save connection", slog.Any("err", err))
+		return m, tea.Batch(cmd, message.With(message.Error{Err: err}))
+	}
+
+	note := "Saved context " + msg.Name
+	if _, password := secret.SplitPassword(msg.DSN); password != "" && saved.Password == "" {
+		note += ", the vault is locked so its password is kept until exit"
+	}
+
+	newItems := append(m.List.Items(), newItemFromConnection(saved))
+	return m, tea.Batch(
+		cmd,
+		m.List.SetItems(newItems),
+		message.With(message.Notification{Text: note}),
+	)
+}
+
+func (m Model) commandDiscover() tea.Cmd {
+	connections := m.connections
+	return func() tea.Msg {
+		found, err := connections.Discover(context.Background())
+		return message.DiscoveredContexts{Found: found, Err: err}
+	}
+}
+
+// handleDiscoveredContexts shows the found connections to pick from.
+// Sources which couldn't be read are only reported when nothing was
+// found in the others.
+func (m Model) handleDiscoveredContexts(msg message.DiscoveredContexts) (Model, tea.Cmd) {
+	if msg.Err != nil {
+		slog.Warn("Discover connections", slog.Any("err", msg.Err))
+	}
+
+	switch {
+	case len(msg.Found) == 0 && msg.Err != nil:
+		return m, message.With(message.Error{Err: msg.Err})
+	case len(msg.Found) == 0:
+		return m, message.With(message.Notification{Text: "Found no connections to import"})
+	}
+
+	m.importer = importmodal.NewModel(m.bindings, msg.Found)
+	m.state.importActive = true
+
+	m, sizeCmd := m.delegateToImportModel(tea.WindowSizeMsg{Width: m.width, Height: m.height})
+	return m, tea.Batch(m.importer.Init(), sizeCmd, message.With(message.BlockCommandLine{}))
+}
+
+func (m Model) handleImportContexts(msg message.ImportContexts) (Model, tea.Cmd) {
+	m.state.importActive = false
+	cmds := []tea.Cmd{message.With(message.UnblockCommandLine{})}
+	if !msg.OK || len(msg.Connections) == 0 {
+		return m, tea.Batch(cmds...)
+	}
+
+	items := slices.Clone(m.List.Items())
+	locked := 0
+	for _, conn := range msg.Connections {
+		saved, err := m.connections.ImportConnection(context.Background(), conn)
+		if err != nil {
+			slog.Error("Import connection", slog.Any("err", err))
+			cmds = append(cmds, message.With(message.Error{Err: err}))
+			break
+		}
+
+		if conn.Password != "" && saved.Password == "" {
+			locked++
+		}
+		items = append(items, newItemFromConnection(saved))
+	}
+
+	imported := len(items) - len(m.List.Items())
+	if imported == 0 {
+		return m, tea.Batch(cmds...)
+	}
+
+	note := fmt.Sprintf("Imported %d contexts", imported)
+	if locked > 0 {
+		note += fmt.Sprintf(", the vault is locked so %d passwords are kept until exit", locked)
+	}
+
+	return m, tea.Batch(append(
+		cmds,
+		m.List.SetItems(items),
+		message.With(message.Notification{Text: note}),
+	)...)
 }
 
 func (m Model) handleMoveFocus(msg message.MoveFocus) (Model, tea.Cmd) {
@@ -130,10 +269,14 @@ func (m Model) handleMoveFocus(msg message.MoveFocus) (Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEnter:
+	switch {
+	case key.Matches(msg, m.keys.Select):
 		return m.handleKeyEnter(msg)
-	case tea.KeyRunes:
+	case m.takesKeys() && key.Matches(msg, m.keys.Cancel):
+		return m.handleCancelConnect()
+	case m.takesKeys() && key.Matches(msg, m.keys.Retry):
+		return m.handleRetry()
+	case msg.Type == tea.KeyRunes:
 		return m.handleKeyRunes(msg)
 	default:
 		return m.delegateToActive(msg)
@@ -141,20 +284,136 @@ func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyEnter(msg tea.KeyMsg) (Model, tea.Cmd) {
-	if m.state.formActive {
+	if m.modalActive() {
 		return m.delegateToActive(msg)
 	}
 
 	if ctx, ok := m.List.SelectedItem().(ctxItem); ok {
-		return m, message.With(message.SelectedContext{DSN: ctx.Description(), Name: ctx.Title()})
+		return m, message.With(ctx.selected())
+	}
+	return m, nil
+}
+
+// takesKeys tells whether keys are meant for the list rather than the
+// forms or the filter.
+func (m Model) takesKeys() bool {
+	return !m.modalActive() && m.List.FilterState() != list.Filtering
+}
+
+// modalActive tells whether one of the forms is shown over the list.
+func (m Model) modalActive() bool {
+	return m.state.formActive || m.state.importActive
+}
+
+func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
+	ticking := m.state.connection == connecting
+	m.selected = msg
+	m.state.connection = connecting
+	m.state.err = nil
+
+	var tickCmd tea.Cmd
+	if !ticking {
+		tickCmd = m.spinner.Tick
 	}
+
+	m, cmd := m.showConnection()
+	return m, tea.Batch(cmd, tickCmd)
+}
+
+func (m Model) handleConnected(name string, err error) (Model, tea.Cmd) {
+	if name != m.selected.Name {
+		return m, nil
+	}
+
+	m.state.connection = connected
+	m.state.err = err
+	if err != nil {
+		m.state.connection = failed
+	}
+
+	return m.showConnection()
+}
+
+func (m Model) handleSpinnerTick(msg spinner.TickMsg) (Model, tea.Cmd) {
+	if m.state.connection != connecting {
+		return m, nil
+	}
+
+	var tickCmd tea.Cmd
+	m.spinner, tickCmd = m.spinner.Update(msg)
+	m, cmd := m.showConnection()
+	return m, tea.Batch(cmd, tickCmd)
+}
+
+func (m Model) handleCancelConnect() (Model, tea.Cmd) {
+	if m.state.connection != connecting {
+		return m, nil
+	}
+	return m, message.With(message.CancelConnect{})
+}
+
+func (m Model) handleRetry() (Model, tea.Cmd) {
+	if m.state.connection != failed {
+		return m, nil
+	}
+	return m, message.With(m.selected)
+}
+
+// showConnection marks the selected context with how connecting to it
+// goes.
+func (m Model) showConnection() (Model, tea.Cmd) {
+	items := slices.Clone(m.List.Items())
+	for i, item := range items {
+		ctx, ok := item.(ctxItem)
+		if !ok {
+			continue
+		}
+
+		ctx.status = ""
+		if ctx.name == m.selected.Name && ctx.dsn == m.selected.DSN {
+			ctx.status = m.connectionView()
+		}
+		items[i] = ctx
+	}
+
+	return m, m.List.SetItems(items)
+}
+
+func (m Model) connectionView() string {
+	switch m.state.connection {
+	case connecting:
+		return m.spinner.View() + "connecting, " + m.keys.Cancel.Help().Key + " to cancel"
+	case connected:
+		return "connected"
+	case failed:
+		return "failed, " + m.keys.Retry.Help().Key + " to retry"
+	default:
+		return ""
+	}
+}
+
+func (m Model) handleSelectByName(name string) (Model, tea.Cmd) {
+	for i, item := range m.List.Items() {
+		ctx, ok := item.(ctxItem)
+		if !ok || ctx.Title() != name {
+			continue
+		}
+
+		m.List.Select(i)
+		return m, message.With(ctx.selected())
+	}
+
 	return m, nil
 }
 
 func (m Model) handleKeyRunes(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.String() {
-	case toggleCreateForm:
+	switch {
+	case m.state.importActive:
+		return m.delegateToActive(msg)
+	case key.Matches(msg, m.keys.Create):
 		return m.handleToggleForm()
+	case m.takesKeys() && key.Matches(msg, m.keys.Import):
+		return m, m.commandDiscover()
 	default:
 		return m.delegateKeypress(msg)
 	}
@@ -169,11 +428,15 @@ func (m Model) handleDeleteContext() (Model, tea.Cmd) {
 		return m, m.List.SetItems(slices.Delete(items, idx, idx+1))
 	}
 
-	if err := m.connections.DeleteConnection(context.Background(), fv.Description()); err != nil {
+	if err := m.connections.DeleteConnection(context.Background(), fv.dsn); err != nil {
 		slog.Error("Delete connection", slog.Any("err", err))
+		return m, message.With(message.Error{Err: err})
 	}
 
-	return m, m.List.SetItems(slices.Delete(items, idx, idx+1))
+	return m, tea.Batch(
+		m.List.SetItems(slices.Delete(items, idx, idx+1)),
+		message.With(message.Notification{Text: "Deleted context " + fv.Title()}),
+	)
 }
 
 func (m Model) handleDisableForm() (Model, tea.Cmd) {
@@ -207,16 +470,21 @@ func (m Model) handleWindowSize(w, h int) (Model, tea.Cmd) {
 func (m Model) delegateToAll(msg tea.Msg) (Model, tea.Cmd) {
 	m, listCmd := m.delegateToList(msg)
 	m, ctxCmd := m.delegateToNewContextModel(msg)
-	return m, tea.Batch(listCmd, ctxCmd)
+	if !m.state.importActive {
+		return m, tea.Batch(listCmd, ctxCmd)
+	}
+
+	m, importCmd := m.delegateToImportModel(msg)
+	return m, tea.Batch(listCmd, ctxCmd, importCmd)
 }
 
 func (m Model) delegateKeypress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	if m.state.formActive {
-		return m.delegateToNewContextModel(msg)
+	if m.modalActive() {
+		return m.delegateToActive(msg)
 	}
 
-	switch msg.String() {
-	case deleteContext:
+	switch {
+	case key.Matches(msg, m.keys.Delete):
 		return m.handleDeleteContext()
 	default:
 		return m.delegateToActive(msg)
@@ -224,11 +492,20 @@ func (m Model) delegateKeypress(msg tea.KeyMsg) (Model, tea.Cmd) {
 }
 
 func (m Model) delegateToActive(msg tea.Msg) (Model, tea.Cmd) {
-	if m.state.formActive {
+	switch {
+	case m.state.formActive:
 		return m.delegateToNewContextModel(msg)
+	case m.state.importActive:
+		return m.delegateToImportModel(msg)
+	default:
+		return m.delegateToList(msg)
 	}
+}
 
-	return m.delegateToList(msg)
+func (m Model) delegateToImportModel(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.importer, cmd = m.importer.Update(msg)
+	return m, cmd
 }
 
 func (m Model) delegateToNewContextModel(msg tea.Msg) (Model, tea.Cmd) {
@@ -257,7 +534,7 @@ func (m Model) newContainerStyles() lipgloss.Style {
 	return base.BorderForeground(color.Border)
 }
 
-func newList(item list.ItemDelegate, rows []list.Item) list.Model {
+func newList(item list.ItemDelegate, rows []list.Item, keys keymap.Map) list.Model {
 	const defaultTitle = "Contexts"
 
 	l := list.New(rows, item, 0, 0)
@@ -266,6 +543,7 @@ func newList(item list.ItemDelegate, rows []list.Item) list.Model {
 	l.InfiniteScrolling = true
 	l.Styles = styles.NewForList()
 	l.Title = defaultTitle
+	l.KeyMap = keys.List(l.KeyMap)
 	l.KeyMap.Quit = key.NewBinding(key.WithDisabled())
 
 	return l
@@ -1,6 +1,20 @@
 package contexts
 
+// connection is how connecting to the selected context went.
+type connection int
+
+const (
+	idle connection = iota
+	connecting
+	connected
+	failed
+)
+
 type state struct {
 	active     bool
 	formActive bool
+	// importActive is set while the discovered connections are picked.
+	importActive bool
+	connection   connection
+	err          error
 }
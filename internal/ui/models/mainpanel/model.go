@@ -3,41 +3,58 @@ package mainpanel
 import (
 	"context"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
 	"github.com/hrvadl/gowatchsql/internal/ui/command"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/activity"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/contexts"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/queryrun"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/session"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
 )
 
 //go:generate mockgen -destination=mocks/mock_factory.go -package=mocks . ExplorerFactory
 type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
+	Create(ctx context.Context, name, driver, dsn string) (engine.Explorer, error)
 }
 
 //go:generate mockgen -destination=mocks/mock_repo.go -package=mocks . ConnectionsRepo
 type ConnectionsRepo interface {
 	GetConnections(context.Context) []cfg.Connection
 	DeleteConnection(ctx context.Context, dsn string) error
+	SaveConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	ImportConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	Discover(ctx context.Context) ([]cfg.Found, error)
 }
 
-func NewModel(explorerFactory ExplorerFactory, connections ConnectionsRepo) Model {
+func NewModel(
+	explorerFactory ExplorerFactory,
+	connections ConnectionsRepo,
+	keys keymap.Map,
+) Model {
 	return Model{
-		objects:  objects.NewModel(explorerFactory),
-		contexts: contexts.NewModel(connections),
-		queryrun: queryrun.NewModel(explorerFactory),
+		session:  session.New(explorerFactory),
+		objects:  objects.NewModel(keys),
+		contexts: contexts.NewModel(connections, keys),
+		queryrun: queryrun.NewModel(keys),
+		activity: activity.NewModel(keys),
 	}
 }
 
 type Model struct {
+	session  session.Session
 	objects  objects.Model
 	contexts *contexts.Model
 	queryrun queryrun.Model
+	activity activity.Model
 	state    state
 }
 
@@ -59,16 +76,34 @@ func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.delegateToActiveModel(msg)
 	case message.MoveFocus:
 		return m.delegateToActiveModel(msg)
-	case message.SelectedContext,
+	case message.SelectedContext:
+		return m.handleSelectedContext(msg)
+	case session.Result:
+		return m.handleSessionResult(msg)
+	case message.ExplorerReady,
+		message.ExplorerFailed,
 		message.SelectedTable,
 		message.FetchedRows,
 		message.FetchedColumns,
 		message.FetchedTableList,
+		message.FetchedTableStats,
 		message.FetchedIndexes,
 		message.FetchedConstraints:
 		return m.delegateToAllModels(msg)
 	case message.Command:
 		return m.handleCommand(msg)
+	case message.CancelConnect:
+		return m.handleCancelConnect()
+	case spinner.TickMsg:
+		m, cmd := m.delegateToContextsModel(msg)
+		m, queryRunCmd := m.delegateToQueryRunModel(msg)
+		return m, tea.Batch(cmd, queryRunCmd)
+	case message.ExecuteCommand, message.ExecutedQuery, message.ExplainedQuery:
+		return m.delegateToQueryRunModel(msg)
+	case watch.Tick, watch.Result:
+		return m.delegateToAllModels(msg)
+	case activity.Tick, activity.Result:
+		return m.delegateToActivityModel(msg)
 	case message.Error:
 		return m.delegateToActiveModel(msg)
 	default:
@@ -84,41 +119,114 @@ func (m Model) View() string {
 		return m.contexts.View()
 	case queryRunActive:
 		return m.queryrun.View()
+	case activityActive:
+		return m.activity.View()
 	default:
 		return "Idk that view"
 	}
 }
 
-func (m Model) Help() string {
+func (m Model) Help() help.KeyMap {
 	switch m.state.active {
 	case objectsActive:
 		return m.objects.Help()
 	case contextsActive:
 		return m.contexts.Help()
+	case queryRunActive:
+		return m.queryrun.Help()
+	case activityActive:
+		return m.activity.Help()
 	default:
-		return "TODO: change me"
+		return nil
 	}
 }
 
 func (m Model) handleCommand(msg message.Command) (Model, tea.Cmd) {
-	switch msg.Text {
+	var cmd tea.Cmd
+	switch msg.Name {
 	case command.Tables:
-		m.state.active = objectsActive
+		m = m.setActive(objectsActive)
+		if name := msg.Arg(0); name != "" {
+			cmd = message.With(message.SelectedTable{Name: name})
+		}
 	case command.Query:
-		m.state.active = queryRunActive
+		m = m.setActive(queryRunActive)
+		if query := msg.Arg(0); query != "" {
+			cmd = message.With(message.ExecuteCommand{Cmd: query})
+		}
 	case command.Context:
-		m.state.active = contextsActive
+		m = m.setActive(contextsActive)
+		if name := msg.Arg(0); name != "" {
+			m.contexts, cmd = m.contexts.Select(name)
+		}
+	case command.Watch:
+		return m.handleWatch(msg.Arg(0))
+	case command.Activity:
+		m = m.setActive(activityActive)
+		m.activity, cmd = m.activity.Open(activity.SessionsMode)
+	case command.Locks:
+		m = m.setActive(activityActive)
+		m.activity, cmd = m.activity.Open(activity.LocksMode)
 	case command.Exit:
 		return m, tea.Quit
 	}
-	return m, message.With(message.MoveFocus{Direction: direction.Forward})
+	return m, tea.Batch(message.With(message.MoveFocus{Direction: direction.Forward}), cmd)
+}
+
+// handleSelectedContext resets the models for the context and connects
+// to it once for all of them.
+func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
+	var connectCmd tea.Cmd
+	m.session, connectCmd = m.session.Connect(msg)
+	m, cmd := m.delegateToAllModels(msg)
+	return m, tea.Batch(cmd, connectCmd)
+}
+
+func (m Model) handleSessionResult(msg session.Result) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.session, cmd = m.session.Update(msg)
+	return m, cmd
+}
+
+func (m Model) handleCancelConnect() (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.session, cmd = m.session.Cancel()
+	return m, cmd
+}
+
+func (m Model) handleWatch(arg string) (Model, tea.Cmd) {
+	interval, err := watch.ParseInterval(arg)
+	if err != nil {
+		return m, message.With(message.Error{Err: err})
+	}
+
+	focus := message.With(message.MoveFocus{Direction: direction.Forward})
+	if m.state.active == queryRunActive {
+		m, cmd := m.delegateToQueryRunModel(message.Watch{Interval: interval})
+		return m, tea.Batch(focus, cmd)
+	}
+
+	m = m.setActive(objectsActive)
+	m, cmd := m.delegateToObjectsModel(message.Watch{Interval: interval})
+	return m, tea.Batch(focus, cmd)
+}
+
+// setActive shows the given model, stopping the refresh of the sessions
+// when they are left.
+func (m Model) setActive(a active) Model {
+	if m.state.active == activityActive && a != activityActive {
+		m.activity = m.activity.Close()
+	}
+	m.state.active = a
+	return m
 }
 
 func (m Model) delegateToAllModels(msg tea.Msg) (Model, tea.Cmd) {
 	m, objCmd := m.delegateToObjectsModel(msg)
 	m, contextsCmd := m.delegateToContextsModel(msg)
 	m, queryRunCmd := m.delegateToQueryRunModel(msg)
-	return m, tea.Batch(objCmd, contextsCmd, queryRunCmd)
+	m, activityCmd := m.delegateToActivityModel(msg)
+	return m, tea.Batch(objCmd, contextsCmd, queryRunCmd, activityCmd)
 }
 
 func (m Model) delegateToActiveModel(msg tea.Msg) (Model, tea.Cmd) {
@@ -129,6 +237,8 @@ func (m Model) delegateToActiveModel(msg tea.Msg) (Model, tea.Cmd) {
 		return m.delegateToContextsModel(msg)
 	case queryRunActive:
 		return m.delegateToQueryRunModel(msg)
+	case activityActive:
+		return m.delegateToActivityModel(msg)
 	default:
 		return m, nil
 	}
@@ -151,3 +261,9 @@ func (m Model) delegateToQueryRunModel(msg tea.Msg) (Model, tea.Cmd) {
 	m.queryrun = model
 	return m, cmd
 }
+
+func (m Model) delegateToActivityModel(msg tea.Msg) (Model, tea.Cmd) {
+	model, cmd := m.activity.Update(msg)
+	m.activity = model
+	return m, cmd
+}
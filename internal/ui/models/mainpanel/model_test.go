@@ -6,10 +6,12 @@ import (
 	"time"
 
 	"github.com/charmbracelet/x/exp/teatest"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/mocks"
 	"github.com/hrvadl/gowatchsql/pkg/xtest"
@@ -18,7 +20,8 @@ import (
 func TestInterfaceShowsAfterConnection(t *testing.T) {
 	xtest.SkipIntegrationIfRequired(t)
 
-	exp := engine.NewMockExplorer(gomock.NewController(t))
+	ctrl := gomock.NewController(t)
+	exp := engine.NewMockExplorer(ctrl)
 	exp.EXPECT().GetTables(gomock.Any()).Return([]engine.Table{
 		{
 			Name:   "table1",
@@ -30,6 +33,8 @@ func TestInterfaceShowsAfterConnection(t *testing.T) {
 		},
 	}, nil)
 
+	exp.EXPECT().GetTableStats(gomock.Any()).Return(nil, nil)
+
 	exp.EXPECT().GetRows(gomock.Any(), "table1").MinTimes(1).Return([]engine.Row{
 		{
 			"row1",
@@ -84,13 +89,13 @@ func TestInterfaceShowsAfterConnection(t *testing.T) {
 
 	ef := mocks.NewMockExplorerFactory(gomock.NewController(t))
 	ef.EXPECT().
-		Create(gomock.Any(), "new naaame", "DSN").MinTimes(1).
+		Create(gomock.Any(), "new naaame", "", "DSN").Times(1).
 		Return(exp, nil)
 
 	repo := mocks.NewMockConnectionsRepo(gomock.NewController(t))
 	repo.EXPECT().GetConnections(gomock.Any()).Return([]cfg.Connection{})
 
-	m := NewModel(ef, repo)
+	m := NewModel(ef, repo, keymap.Default())
 	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(300, 100))
 
 	tm.Send(message.SelectedContext{DSN: "DSN", Name: "new naaame"})
@@ -106,12 +111,17 @@ func TestInterfaceShowsAfterConnection(t *testing.T) {
 		teatest.WithCheckInterval(time.Millisecond*100),
 		teatest.WithDuration(time.Second*3),
 	)
+
+	// The table is loaded by commands running in the background, which
+	// may still be on the way once the panels are drawn.
+	require.Eventually(t, ctrl.Satisfied, time.Second*3, time.Millisecond*50)
 }
 
 func TestAllTablesAreShownAfterConnection(t *testing.T) {
 	xtest.SkipIntegrationIfRequired(t)
 
-	exp := engine.NewMockExplorer(gomock.NewController(t))
+	ctrl := gomock.NewController(t)
+	exp := engine.NewMockExplorer(ctrl)
 	exp.EXPECT().GetTables(gomock.Any()).Return([]engine.Table{
 		{
 			Name:   "table1",
@@ -123,6 +133,10 @@ func TestAllTablesAreShownAfterConnection(t *testing.T) {
 		},
 	}, nil)
 
+	exp.EXPECT().GetTableStats(gomock.Any()).Return(map[string]engine.TableStats{
+		"table1": {Rows: 12},
+	}, nil)
+
 	exp.EXPECT().GetRows(gomock.Any(), "table1").MinTimes(1).Return([]engine.Row{
 		{
 			"row1",
@@ -177,13 +191,13 @@ func TestAllTablesAreShownAfterConnection(t *testing.T) {
 
 	ef := mocks.NewMockExplorerFactory(gomock.NewController(t))
 	ef.EXPECT().
-		Create(gomock.Any(), "new naaame", "DSN").MinTimes(1).
+		Create(gomock.Any(), "new naaame", "", "DSN").Times(1).
 		Return(exp, nil)
 
 	repo := mocks.NewMockConnectionsRepo(gomock.NewController(t))
 	repo.EXPECT().GetConnections(gomock.Any()).Return([]cfg.Connection{})
 
-	m := NewModel(ef, repo)
+	m := NewModel(ef, repo, keymap.Default())
 	tm := teatest.NewTestModel(t, m, teatest.WithInitialTermSize(300, 100))
 
 	tm.Send(message.SelectedContext{DSN: "DSN", Name: "new naaame"})
@@ -192,9 +206,14 @@ func TestAllTablesAreShownAfterConnection(t *testing.T) {
 		t, tm.Output(),
 		func(bts []byte) bool {
 			return bytes.Contains(bts, []byte("table1")) &&
-				bytes.Contains(bts, []byte("table2"))
+				bytes.Contains(bts, []byte("table2")) &&
+				bytes.Contains(bts, []byte("12 rows"))
 		},
 		teatest.WithCheckInterval(time.Millisecond*100),
 		teatest.WithDuration(time.Second*3),
 	)
+
+	// The table is loaded by commands running in the background, which
+	// may still be on the way once the panels are drawn.
+	require.Eventually(t, ctrl.Satisfied, time.Second*3, time.Millisecond*50)
 }
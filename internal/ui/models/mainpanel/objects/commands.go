@@ -0,0 +1,27 @@
+package objects
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	NextPanel key.Binding
+	PrevPanel key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		NextPanel: keys.Get(keymap.ObjectsNextPanel),
+		PrevPanel: keys.Get(keymap.ObjectsPrevPanel),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.NextPanel, k.PrevPanel}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
@@ -1,26 +1,25 @@
 package objects
 
 import (
-	"context"
-
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
-	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/info"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
-
-func NewModel(ef ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	return Model{
-		info:    info.NewModel(ef),
-		details: details.NewModel(ef),
+		keys:    newKeyMap(keys),
+		info:    info.NewModel(keys),
+		details: details.NewModel(keys),
 	}
 }
 
@@ -38,6 +37,7 @@ const (
 
 type Model struct {
 	state state
+	keys  keyMap
 
 	width  int
 	height int
@@ -58,10 +58,17 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.handleKeyPress(msg)
 	case message.SelectedTable:
 		return m.handleTableChosen(msg)
-	case message.FetchedRows, message.FetchedColumns:
+	case message.FetchedRows, message.FetchedColumns, message.Watch, watch.Tick, watch.Result:
 		return m.delegateToDetailsModel(msg)
-	case message.SelectedContext, message.FetchedTableList, message.FetchedIndexes, message.FetchedConstraints:
+	case message.SelectedContext,
+		message.ExplorerReady,
+		message.ExplorerFailed,
+		message.FetchedTableList,
+		message.FetchedIndexes,
+		message.FetchedConstraints:
 		return m.delegateToAllModels(msg)
+	case message.FetchedTableStats:
+		return m.delegateToInfoModel(msg)
 	case message.Error:
 		return m.handleError(msg)
 	case message.MoveFocus:
@@ -77,14 +84,14 @@ func (m Model) View() string {
 	)
 }
 
-func (m Model) Help() string {
+func (m Model) Help() help.KeyMap {
 	switch m.state.focused {
 	case infoFocused:
-		return m.info.Help()
+		return xhelp.Join(m.keys, m.info.Help())
 	case detailsFocused:
-		return "TODO"
+		return xhelp.Join(m.keys, m.details.Help())
 	default:
-		return "error: unknown view"
+		return m.keys
 	}
 }
 
@@ -131,10 +138,10 @@ func (m Model) backwardsStrategy(msg message.MoveFocus) (Model, tea.Cmd) {
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyTab:
+	switch {
+	case key.Matches(msg, m.keys.NextPanel):
 		return m.handleMoveFocus(message.MoveFocus{Direction: direction.Forward})
-	case tea.KeyShiftTab:
+	case key.Matches(msg, m.keys.PrevPanel):
 		return m.handleMoveFocus(message.MoveFocus{Direction: direction.Backwards})
 	default:
 		return m.delegateToActiveModel(msg)
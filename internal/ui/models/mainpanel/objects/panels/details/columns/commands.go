@@ -1,6 +0,0 @@
-package columns
-
-const (
-	scrollLeft  = "h"
-	scrollRight = "l"
-)
@@ -1,6 +1,27 @@
 package details
 
-const (
-	moveFocusLeft  = "H"
-	moveFocusRight = "L"
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 )
+
+type keyMap struct {
+	PrevTab key.Binding
+	NextTab key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		PrevTab: keys.Get(keymap.DetailsPrevTab),
+		NextTab: keys.Get(keymap.DetailsNextTab),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.PrevTab, k.NextTab}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
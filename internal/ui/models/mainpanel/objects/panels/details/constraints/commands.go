@@ -1,6 +0,0 @@
-package constraints
-
-const (
-	scrollLeft  = "h"
-	scrollRight = "l"
-)
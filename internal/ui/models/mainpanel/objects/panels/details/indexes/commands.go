@@ -1,6 +0,0 @@
-package indexes
-
-const (
-	scrollLeft  = "h"
-	scrollRight = "l"
-)
@@ -5,39 +5,44 @@ import (
 	"log/slog"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/pkg/xtable"
 )
 
-const margin = 1
+const (
+	margin      = 1
+	statsSource = "indexes"
+)
 
 type Column = string
 
 type Row = []string
 
-func NewModel(factory ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	return Model{
-		engineFactory: factory,
+		keys: keys.Table(),
 	}
 }
 
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
-
 type Model struct {
 	width  int
 	height int
 
-	chosen        string
-	engineFactory ExplorerFactory
-	explorer      engine.Explorer
-	table         xtable.Model
+	chosen   string
+	explorer engine.Explorer
+	timeouts cfg.Timeouts
+	table    xtable.Model
+	keys     table.KeyMap
 
 	state state
 	err   error
@@ -61,6 +66,11 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.handleFetchedTableContent(msg)
 	case message.SelectedContext:
 		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		m.explorer = msg.Explorer
+		return m, nil
+	case message.ExplorerFailed:
+		return m.handleError(message.Error{Err: msg.Err})
 	default:
 		return m.delegateToTable(msg)
 	}
@@ -74,14 +84,14 @@ func (m Model) View() string {
 	case loading:
 		content = "Loading..."
 	case errored:
-		content = m.err.Error()
+		content = secret.Mask(m.err.Error())
 	}
 
 	return s.Render(content)
 }
 
-func (m Model) Help() string {
-	return "Details help"
+func (m Model) Help() help.KeyMap {
+	return xtable.NewHelp(m.keys)
 }
 
 func (m Model) delegateToTable(msg tea.Msg) (Model, tea.Cmd) {
@@ -101,29 +111,24 @@ func (m Model) handleFetchedTableContent(msg message.FetchedIndexes) (Model, tea
 	m.state.status = ready
 
 	m.table = xtable.New(msg.Cols, msg.Rows).
+		WithKeyMap(m.keys).
 		WithMaxTotalWidth(m.width - 1)
 
 	return m, nil
 }
 
 func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
-	defer cancel()
-
-	explorer, err := m.engineFactory.Create(ctx, msg.Name, msg.DSN)
-	if err != nil {
-		m.err = err
-		m.state.status = errored
-		return m, nil
-	}
-
-	m.explorer = explorer
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.explorer = nil
 	m.state.status = loading
-
 	return m, nil
 }
 
 func (m Model) handleTableChosen(msg message.SelectedTable) (Model, tea.Cmd) {
+	if m.explorer == nil {
+		return m, nil
+	}
+
 	m.chosen = msg.Name
 	m.state.status = loading
 	return m, m.commandFetchTableContent(msg.Name)
@@ -143,10 +148,11 @@ func (m Model) handleUpdateSize(w, h int) (Model, tea.Cmd) {
 }
 
 func (m *Model) commandFetchTableContent(table string) tea.Cmd {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
 	return func() tea.Msg {
 		defer cancel()
 
+		start := time.Now()
 		rows, cols, err := m.explorer.GetIndexes(ctx, table)
 		if err != nil {
 			m.state.status = errored
@@ -154,7 +160,10 @@ func (m *Model) commandFetchTableContent(table string) tea.Cmd {
 			return message.Error{Err: err}
 		}
 
-		return message.FetchedIndexes{Rows: rows, Cols: cols}
+		return message.Batch(
+			message.FetchedIndexes{Rows: rows, Cols: cols},
+			message.QueryStats{Source: statsSource, Took: time.Since(start), Rows: int64(len(rows))},
+		)
 	}
 }
 
@@ -1,41 +1,41 @@
 package details
 
 import (
-	"context"
-
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
-	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details/columns"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details/constraints"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details/indexes"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details/rows"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
 const margin = 1
 
-func NewModel(ef ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	return Model{
-		rows:        rows.NewModel(ef),
-		columns:     columns.NewModel(ef),
-		indexes:     indexes.NewModel(ef),
-		constraints: constraints.NewModel(ef),
+		keys:        newKeyMap(keys),
+		rows:        rows.NewModel(keys),
+		columns:     columns.NewModel(keys),
+		indexes:     indexes.NewModel(keys),
+		constraints: constraints.NewModel(keys),
 	}
 }
 
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
-
 type Model struct {
 	width  int
 	height int
 
 	state state
+	keys  keyMap
 
 	rows        rows.Model
 	columns     columns.Model
@@ -56,13 +56,19 @@ func (m Model) update(msg tea.Msg) (Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		return m.handleUpdateSize(msg.Width-margin*2, msg.Height-margin*2)
-	case message.SelectedContext, message.SelectedTable:
+	case message.SelectedContext,
+		message.SelectedTable,
+		message.ExplorerReady,
+		message.ExplorerFailed:
 		return m.delegateToAllModels(msg)
 	case message.MoveFocus:
 		return m.handleMoveFocus(msg)
 	case tea.KeyMsg:
 		return m.handleKeyPress(msg)
-	case message.FetchedRows:
+	case message.FetchedRows, watch.Tick, watch.Result:
+		return m.delegateToRowsModel(msg)
+	case message.Watch:
+		m.state.focused = rowsFocused
 		return m.delegateToRowsModel(msg)
 	case message.FetchedColumns:
 		return m.delegateToColumnsModel(msg)
@@ -108,11 +114,27 @@ func (m Model) View() string {
 	return s.Render(lipgloss.JoinVertical(lipgloss.Top, header, content))
 }
 
+func (m Model) Help() help.KeyMap {
+	var active help.KeyMap
+	switch m.state.focused {
+	case columnsFocused:
+		active = m.columns.Help()
+	case indexesFocused:
+		active = m.indexes.Help()
+	case constraintsFocused:
+		active = m.constraints.Help()
+	default:
+		active = m.rows.Help()
+	}
+
+	return xhelp.Join(m.keys, active)
+}
+
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.String() {
-	case moveFocusLeft:
+	switch {
+	case key.Matches(msg, m.keys.PrevTab):
 		return m.handleMoveTabFocus(direction.Backwards)
-	case moveFocusRight:
+	case key.Matches(msg, m.keys.NextTab):
 		return m.handleMoveTabFocus(direction.Forward)
 	default:
 		return m.delegateToActiveModel(msg)
@@ -2,42 +2,53 @@ package rows
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/xtable"
 )
 
-const margin = 1
+const (
+	margin      = 1
+	statsSource = "rows"
+)
+
+var errNothingToWatch = fmt.Errorf("%w: choose a table to watch", errs.ErrValidation)
 
 type Column = string
 
 type Row = []string
 
-func NewModel(factory ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	return Model{
-		engineFactory: factory,
+		keys: keys.Table(),
 	}
 }
 
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
-
 type Model struct {
 	width  int
 	height int
 
-	chosen        string
-	engineFactory ExplorerFactory
-	explorer      engine.Explorer
-	table         xtable.Model
+	chosen   string
+	explorer engine.Explorer
+	timeouts cfg.Timeouts
+	table    xtable.Model
+	keys     table.KeyMap
+	watch    watch.Watcher
 
 	state state
 	err   error
@@ -61,6 +72,17 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.handleFetchedTableContent(msg)
 	case message.SelectedContext:
 		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		m.explorer = msg.Explorer
+		return m, nil
+	case message.ExplorerFailed:
+		return m.handleError(message.Error{Err: msg.Err})
+	case message.Watch:
+		return m.handleWatch(msg)
+	case watch.Tick:
+		return m.handleWatchTick(msg)
+	case watch.Result:
+		return m.handleWatchResult(msg)
 	default:
 		return m.delegateToTable(msg)
 	}
@@ -74,7 +96,11 @@ func (m Model) View() string {
 	case loading:
 		content = "Loading..."
 	case errored:
-		content = m.err.Error()
+		content = secret.Mask(m.err.Error())
+	}
+
+	if m.watch.Active() {
+		content = lipgloss.JoinVertical(lipgloss.Left, m.watch.View(), content)
 	}
 
 	return s.Render(content)
@@ -86,8 +112,8 @@ func (m Model) delegateToTable(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
-func (m Model) Help() string {
-	return "Details help"
+func (m Model) Help() help.KeyMap {
+	return xtable.NewHelp(m.keys)
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
@@ -101,6 +127,7 @@ func (m Model) handleFetchedTableContent(msg message.FetchedRows) (Model, tea.Cm
 	m.state.status = ready
 
 	m.table = xtable.New(msg.Cols, msg.Rows).
+		WithKeyMap(m.keys).
 		WithMaxTotalWidth(m.width - 1)
 
 	slog.Info("Scroll keymaps", slog.Any("keys", m.table.KeyMap().ScrollRight.Keys()))
@@ -109,28 +136,70 @@ func (m Model) handleFetchedTableContent(msg message.FetchedRows) (Model, tea.Cm
 }
 
 func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
-	defer cancel()
-
-	explorer, err := m.engineFactory.Create(ctx, msg.Name, msg.DSN)
-	if err != nil {
-		m.err = err
-		m.state.status = errored
-		return m, nil
-	}
-
-	m.explorer = explorer
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.explorer = nil
+	m.watch = watch.Watcher{}
 	m.state.status = loading
-
 	return m, nil
 }
 
 func (m Model) handleTableChosen(msg message.SelectedTable) (Model, tea.Cmd) {
+	if m.explorer == nil {
+		return m, nil
+	}
+
+	if msg.Name != m.chosen {
+		m.watch = watch.Watcher{}
+	}
 	m.chosen = msg.Name
 	m.state.status = loading
 	return m, m.commandFetchTableContent(msg.Name)
 }
 
+func (m Model) handleWatch(msg message.Watch) (Model, tea.Cmd) {
+	if msg.Interval == 0 {
+		m.watch = watch.Watcher{}
+		return m, nil
+	}
+
+	if m.explorer == nil || m.chosen == "" {
+		return m, message.With(message.Error{Err: errNothingToWatch})
+	}
+
+	m.watch = watch.New(msg.Interval)
+	return m, m.commandWatch(m.watch, m.chosen)
+}
+
+func (m Model) handleWatchTick(msg watch.Tick) (Model, tea.Cmd) {
+	if !m.watch.OwnsTick(msg) {
+		return m, nil
+	}
+	return m, m.commandWatch(m.watch, m.chosen)
+}
+
+func (m Model) handleWatchResult(msg watch.Result) (Model, tea.Cmd) {
+	if !m.watch.OwnsResult(msg) {
+		return m, nil
+	}
+
+	if msg.Err != nil {
+		m.watch = watch.Watcher{}
+		return m, message.With(message.Error{Err: msg.Err})
+	}
+
+	var diff []watch.Row
+	m.watch, diff = m.watch.Observe(msg)
+	values, styles := watch.Split(diff)
+
+	m.state.status = ready
+	m.table = xtable.New(msg.Cols, values).
+		WithKeyMap(m.keys).
+		WithMaxTotalWidth(m.width - 1).
+		WithRowStyles(styles)
+
+	return m, m.watch.Schedule()
+}
+
 func (m Model) handleError(msg message.Error) (Model, tea.Cmd) {
 	m.err = msg.Err
 	m.state.status = errored
@@ -145,17 +214,41 @@ func (m Model) handleUpdateSize(w, h int) (Model, tea.Cmd) {
 }
 
 func (m *Model) commandFetchTableContent(table string) tea.Cmd {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
 	return func() tea.Msg {
 		defer cancel()
 
+		start := time.Now()
 		rows, cols, err := m.explorer.GetRows(ctx, table)
 		if err != nil {
 			m.state.status = errored
 			return message.Error{Err: err}
 		}
 
-		return message.FetchedRows{Rows: rows, Cols: cols}
+		return message.Batch(
+			message.FetchedRows{Rows: rows, Cols: cols},
+			message.QueryStats{Source: statsSource, Took: time.Since(start), Rows: int64(len(rows))},
+		)
+	}
+}
+
+func (m Model) commandWatch(w watch.Watcher, table string) tea.Cmd {
+	explorer := m.explorer
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
+	return func() tea.Msg {
+		defer cancel()
+
+		key := w.Key()
+		if key == nil {
+			pk, err := explorer.GetPrimaryKey(ctx, table)
+			if err != nil {
+				slog.Warn("Get primary key to watch", slog.String("table", table), slog.Any("err", err))
+			}
+			key = append([]string{}, pk...)
+		}
+
+		rows, cols, err := explorer.GetRows(ctx, table)
+		return w.Result(rows, cols, key, err)
 	}
 }
 
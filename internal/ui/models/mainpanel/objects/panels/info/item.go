@@ -1,11 +1,17 @@
 package info
 
 import (
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/charmbracelet/bubbles/list"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 )
 
+const descriptionSeparator = " · "
+
 func newItemsFromTable(t []engine.Table) []list.Item {
 	items := make([]list.Item, 0, len(t))
 	for _, tt := range t {
@@ -23,9 +29,82 @@ func (i tableItem) Title() string {
 }
 
 func (i tableItem) Description() string {
-	return i.Schema
+	if i.Stats == nil {
+		return i.Schema
+	}
+
+	// The panel is narrow, so the stats go first and the schema last.
+	return strings.Join(append(statsParts(*i.Stats, time.Now()), i.Schema), descriptionSeparator)
 }
 
 func (i tableItem) FilterValue() string {
 	return i.Name
 }
+
+// statsParts describes the stats an engine could tell, skipping the
+// unknown ones.
+func statsParts(s engine.TableStats, now time.Time) []string {
+	parts := []string{humanCount(s.Rows) + " " + plural(s.Rows, "row", "rows")}
+	if s.Size > 0 || s.IndexSize > 0 {
+		parts = append(parts, humanBytes(s.Size), "idx "+humanBytes(s.IndexSize))
+	}
+	if !s.LastVacuum.IsZero() {
+		parts = append(parts, "vacuumed "+ago(s.LastVacuum, now))
+	}
+	if !s.LastAnalyze.IsZero() {
+		parts = append(parts, "analyzed "+ago(s.LastAnalyze, now))
+	}
+	if s.AutoIncrement > 0 {
+		parts = append(parts, fmt.Sprintf("next id %d", s.AutoIncrement))
+	}
+	return parts
+}
+
+func humanCount(n int64) string {
+	switch {
+	case n >= 1_000_000_000:
+		return fmt.Sprintf("%.1fB", float64(n)/1_000_000_000)
+	case n >= 1_000_000:
+		return fmt.Sprintf("%.1fM", float64(n)/1_000_000)
+	case n >= 1_000:
+		return fmt.Sprintf("%.1fk", float64(n)/1_000)
+	default:
+		return fmt.Sprint(n)
+	}
+}
+
+func humanBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for v := n / unit; v >= unit; v /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+func ago(t, now time.Time) string {
+	d := now.Sub(t)
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return fmt.Sprintf("%dm ago", int(d.Minutes()))
+	case d < time.Hour*24:
+		return fmt.Sprintf("%dh ago", int(d.Hours()))
+	default:
+		return fmt.Sprintf("%dd ago", int(d.Hours()/24))
+	}
+}
+
+func plural(n int64, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
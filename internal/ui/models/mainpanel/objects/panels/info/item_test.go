@@ -0,0 +1,53 @@
+package info
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestStatsParts(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	now := time.Date(2025, time.March, 10, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name  string
+		stats engine.TableStats
+		want  []string
+	}{
+		{
+			name:  "Should show only rows when size is unknown",
+			stats: engine.TableStats{Rows: 1},
+			want:  []string{"1 row"},
+		},
+		{
+			name: "Should show sizes and maintenance of PostgreSQL",
+			stats: engine.TableStats{
+				Rows:        1_250_000,
+				Size:        3 * 1024 * 1024,
+				IndexSize:   512,
+				LastVacuum:  now.Add(-time.Hour * 50),
+				LastAnalyze: now.Add(-time.Minute * 5),
+			},
+			want: []string{"1.2M rows", "3.0 MiB", "idx 512 B", "vacuumed 2d ago", "analyzed 5m ago"},
+		},
+		{
+			name:  "Should show next id of MySQL",
+			stats: engine.TableStats{Rows: 1500, Size: 16 * 1024, AutoIncrement: 1501},
+			want:  []string{"1.5k rows", "16.0 KiB", "idx 0 B", "next id 1501"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, statsParts(tt.stats, now))
+		})
+	}
+}
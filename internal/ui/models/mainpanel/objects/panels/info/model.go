@@ -2,47 +2,55 @@ package info
 
 import (
 	"context"
-	"time"
+	"log/slog"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/styles"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
 const margin = 1
 
-func NewModel(ef ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	item := list.NewDefaultDelegate()
 	item.Styles = styles.NewForItemDelegate()
-	l := newList(item)
+	l := newList(item, keys)
 	l.SetShowHelp(false)
 	return Model{
-		engineFactory: ef,
-		list:          l,
+		list: l,
+		keys: newKeyMap(keys),
 	}
 }
 
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
-
 type Model struct {
 	width  int
 	height int
 
 	list list.Model
+	keys keyMap
 
 	state state
 
-	engineFactory ExplorerFactory
-	explorer      engine.Explorer
+	// tables are kept in the order of the engine, so they can be sorted
+	// again as stats come in.
+	tables  []engine.Table
+	sort    sortOrder
+	context string
+
+	explorer engine.Explorer
+	timeouts cfg.Timeouts
 }
 
 func (m Model) Init() tea.Cmd {
@@ -55,6 +63,10 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleWindowSize(msg.Width-margin*2, msg.Height-margin*2)
 	case message.SelectedContext:
 		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		return m.handleExplorerReady(msg)
+	case message.ExplorerFailed:
+		return m.handleError(message.Error{Err: msg.Err})
 	case message.Error:
 		return m.handleError(msg)
 	case tea.KeyMsg:
@@ -63,6 +75,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleMoveFocus(msg)
 	case message.FetchedTableList:
 		return m.handleFetchedTableList(msg)
+	case message.FetchedTableStats:
+		return m.handleFetchedTableStats(msg)
 	default:
 		return m, nil
 	}
@@ -72,7 +86,7 @@ func (m Model) View() string {
 	s := m.newStyles()
 	switch m.state.status {
 	case errored:
-		return s.Render(m.state.err.Error())
+		return s.Render(secret.Mask(m.state.err.Error()))
 	case loading:
 		return s.Render("Loading...")
 	default:
@@ -90,14 +104,16 @@ func (m Model) handleMoveFocus(msg message.MoveFocus) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func (m Model) Help() string {
-	return "info help"
+func (m Model) Help() help.KeyMap {
+	return xhelp.Join(m.keys, m.list)
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEnter:
+	switch {
+	case key.Matches(msg, m.keys.Select):
 		return m.handleSelectItem()
+	case key.Matches(msg, m.keys.Sort) && m.list.FilterState() != list.Filtering:
+		return m.handleSort()
 	default:
 		return m.delegateToList(msg)
 	}
@@ -132,26 +148,67 @@ func (m Model) handleError(msg message.Error) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
+func (m Model) handleSort() (tea.Model, tea.Cmd) {
+	m.sort = m.sort.next()
+	m.list.Title = listTitle(m.sort)
+	return m, m.setTables()
+}
+
 func (m Model) handleFetchedTableList(msg message.FetchedTableList) (Model, tea.Cmd) {
 	m.state.status = ready
-	items := newItemsFromTable(msg.Tables)
-	cmd := m.list.SetItems(items)
-	return m, tea.Batch(cmd, m.commandSelectTable(msg.Tables))
+	m.tables = msg.Tables
+	sorted := sortTables(m.tables, m.sort)
+	cmd := m.list.SetItems(newItemsFromTable(sorted))
+	return m, tea.Batch(cmd, m.commandSelectTable(sorted), m.commandFetchTableStats())
 }
 
-func (m Model) handleSelectedContext(msg message.SelectedContext) (tea.Model, tea.Cmd) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
-	defer cancel()
-
-	explorer, err := m.engineFactory.Create(ctx, msg.Name, msg.DSN)
-	if err != nil {
-		m.state.err = err
-		m.state.status = errored
+func (m Model) handleFetchedTableStats(msg message.FetchedTableStats) (tea.Model, tea.Cmd) {
+	if msg.Context != m.context {
 		return m, nil
 	}
 
-	m.explorer = explorer
+	tables := make([]engine.Table, 0, len(m.tables))
+	for _, t := range m.tables {
+		if stats, ok := msg.Stats[t.Name]; ok {
+			t.Stats = &stats
+		}
+		tables = append(tables, t)
+	}
+
+	m.tables = tables
+	return m, m.setTables()
+}
+
+// setTables shows the tables in the chosen order, keeping the cursor on
+// the selected one.
+func (m *Model) setTables() tea.Cmd {
+	selected, hasSelected := m.list.SelectedItem().(tableItem)
+	sorted := sortTables(m.tables, m.sort)
+	cmd := m.list.SetItems(newItemsFromTable(sorted))
+
+	if hasSelected {
+		for i, item := range m.list.VisibleItems() {
+			if t, ok := item.(tableItem); ok && t.Name == selected.Name {
+				m.list.Select(i)
+				break
+			}
+		}
+	}
+
+	return cmd
+}
+
+func (m Model) handleSelectedContext(msg message.SelectedContext) (tea.Model, tea.Cmd) {
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.explorer = nil
+	m.context = msg.Name
+	m.tables = nil
 	m.state.status = loading
+	return m, nil
+}
+
+func (m Model) handleExplorerReady(msg message.ExplorerReady) (tea.Model, tea.Cmd) {
+	m.explorer = msg.Explorer
 	return m, m.commandFetchTables
 }
 
@@ -164,7 +221,7 @@ func (m Model) commandSelectTable(tables []engine.Table) tea.Cmd {
 }
 
 func (m *Model) commandFetchTables() tea.Msg {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
 	defer cancel()
 
 	tables, err := m.explorer.GetTables(ctx)
@@ -176,6 +233,26 @@ func (m *Model) commandFetchTables() tea.Msg {
 	return message.FetchedTableList{Tables: tables}
 }
 
+func (m Model) commandFetchTableStats() tea.Cmd {
+	if m.explorer == nil {
+		return nil
+	}
+
+	explorer, ctxName, timeout := m.explorer, m.context, m.timeouts.Query
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		stats, err := explorer.GetTableStats(ctx)
+		if err != nil {
+			slog.Error("Failed to get table stats", slog.Any("err", err))
+			return nil
+		}
+
+		return message.FetchedTableStats{Context: ctxName, Stats: stats}
+	}
+}
+
 func (m Model) newStyles() lipgloss.Style {
 	base := lipgloss.
 		NewStyle().
@@ -190,15 +267,23 @@ func (m Model) newStyles() lipgloss.Style {
 	return base.BorderForeground(color.Border)
 }
 
-func newList(item list.ItemDelegate) list.Model {
-	const defaultTitle = "Tables 📋"
+const defaultTitle = "Tables 📋"
+
+func listTitle(order sortOrder) string {
+	if order == unsorted {
+		return defaultTitle
+	}
+	return defaultTitle + " " + order.String()
+}
 
+func newList(item list.ItemDelegate, keys keymap.Map) list.Model {
 	l := list.New([]list.Item{}, item, 0, 0)
 	l.SetShowStatusBar(false)
 	l.SetShowPagination(false)
 	l.InfiniteScrolling = true
 	l.Styles = styles.NewForList()
 	l.Title = defaultTitle
+	l.KeyMap = keys.List(l.KeyMap)
 	l.KeyMap.Quit = key.NewBinding(key.WithDisabled())
 
 	return l
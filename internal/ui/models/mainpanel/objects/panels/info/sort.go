@@ -0,0 +1,63 @@
+package info
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+)
+
+// sortOrder is how the tables are listed. Unsorted keeps the order of
+// the engine.
+type sortOrder int
+
+const (
+	unsorted sortOrder = iota
+	bySize
+	byRows
+)
+
+func (o sortOrder) next() sortOrder {
+	return (o + 1) % (byRows + 1)
+}
+
+func (o sortOrder) String() string {
+	switch o {
+	case bySize:
+		return "by size"
+	case byRows:
+		return "by rows"
+	default:
+		return ""
+	}
+}
+
+// sortTables returns a sorted copy of the tables, biggest first. Tables
+// without stats go last, in the order of the engine.
+func sortTables(tables []engine.Table, order sortOrder) []engine.Table {
+	sorted := slices.Clone(tables)
+	if order == unsorted {
+		return sorted
+	}
+
+	slices.SortStableFunc(sorted, func(a, b engine.Table) int {
+		return cmp.Compare(order.key(b), order.key(a))
+	})
+
+	return sorted
+}
+
+func (o sortOrder) key(t engine.Table) int64 {
+	if t.Stats == nil {
+		return -1
+	}
+
+	switch o {
+	case bySize:
+		return t.Stats.Size + t.Stats.IndexSize
+	case byRows:
+		return t.Stats.Rows
+	default:
+		return 0
+	}
+}
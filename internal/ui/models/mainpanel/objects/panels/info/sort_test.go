@@ -0,0 +1,69 @@
+package info
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestSortTables(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	tables := []engine.Table{
+		{Name: "logs", Stats: &engine.TableStats{Rows: 900, Size: 4096, IndexSize: 1024}},
+		{Name: "pending"},
+		{Name: "users", Stats: &engine.TableStats{Rows: 1200, Size: 2048}},
+		{Name: "orders", Stats: &engine.TableStats{Rows: 10, Size: 8192}},
+	}
+
+	names := func(tables []engine.Table) []string {
+		var n []string
+		for _, t := range tables {
+			n = append(n, t.Name)
+		}
+		return n
+	}
+
+	tests := []struct {
+		name  string
+		order sortOrder
+		want  []string
+	}{
+		{
+			name:  "Should keep order of the engine when unsorted",
+			order: unsorted,
+			want:  []string{"logs", "pending", "users", "orders"},
+		},
+		{
+			name:  "Should put biggest tables with their indexes first",
+			order: bySize,
+			want:  []string{"orders", "logs", "users", "pending"},
+		},
+		{
+			name:  "Should put tables with most rows first",
+			order: byRows,
+			want:  []string{"users", "logs", "orders", "pending"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, names(sortTables(tables, tt.order)))
+			require.Equal(t, "logs", tables[0].Name, "input must not be changed")
+		})
+	}
+}
+
+func TestSortOrderNext(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	require.Equal(t, bySize, unsorted.next())
+	require.Equal(t, byRows, bySize.next())
+	require.Equal(t, unsorted, byRows.next())
+}
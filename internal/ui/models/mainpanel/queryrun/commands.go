@@ -0,0 +1,83 @@
+package queryrun
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	Execute     key.Binding
+	SwitchFocus key.Binding
+	Leave       key.Binding
+	Cancel      key.Binding
+	Explain     key.Binding
+	Analyze     key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Execute:     keys.Get(keymap.QueryExecute),
+		SwitchFocus: keys.Get(keymap.QuerySwitchFocus),
+		Leave:       keys.Get(keymap.QueryLeave),
+		Cancel:      keys.Get(keymap.QueryCancel),
+		Explain:     keys.Get(keymap.QueryExplain),
+		Analyze:     keys.Get(keymap.QueryAnalyze),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Execute, k.SwitchFocus, k.Leave, k.Cancel, k.Explain, k.Analyze}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type tabsKeyMap struct {
+	NextTab   key.Binding
+	PrevTab   key.Binding
+	CloseTab  key.Binding
+	PinTab    key.Binding
+	RenameTab key.Binding
+}
+
+func newTabsKeyMap(keys keymap.Map) tabsKeyMap {
+	return tabsKeyMap{
+		NextTab:   keys.Get(keymap.QueryNextTab),
+		PrevTab:   keys.Get(keymap.QueryPrevTab),
+		CloseTab:  keys.Get(keymap.QueryCloseTab),
+		PinTab:    keys.Get(keymap.QueryPinTab),
+		RenameTab: keys.Get(keymap.QueryRenameTab),
+	}
+}
+
+func (k tabsKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.PrevTab, k.NextTab, k.CloseTab, k.PinTab, k.RenameTab}
+}
+
+func (k tabsKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
+
+type planKeyMap struct {
+	Up     key.Binding
+	Down   key.Binding
+	Toggle key.Binding
+}
+
+func newPlanKeyMap(keys keymap.Map) planKeyMap {
+	return planKeyMap{
+		Up:     keys.Get(keymap.TableRowUp),
+		Down:   keys.Get(keymap.TableRowDown),
+		Toggle: keys.Get(keymap.PlanToggle),
+	}
+}
+
+func (k planKeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Up, k.Down, k.Toggle}
+}
+
+func (k planKeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.ShortHelp()}
+}
@@ -0,0 +1,78 @@
+package queryrun
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+)
+
+// handleStartConfirm holds back a statement which drops data in bulk on a
+// prod context until the name of the context is typed in.
+func (m Model) handleStartConfirm(query string, analyze bool) (Model, tea.Cmd) {
+	m.state.pending = query
+	m.state.pendingAnalyze = analyze
+	m.confirm.SetValue("")
+	return m, tea.Batch(m.confirm.Focus(), message.With(message.BlockCommandLine{}))
+}
+
+func (m Model) handleConfirmKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Execute):
+		if strings.TrimSpace(m.confirm.Value()) != m.context {
+			return m, nil
+		}
+
+		query, analyze := m.state.pending, m.state.pendingAnalyze
+		m, stopCmd := m.handleStopConfirm()
+
+		var runCmd tea.Cmd
+		if analyze {
+			m, runCmd = m.runExplain(query, true)
+		} else {
+			m, runCmd = m.runQuery(query)
+		}
+		return m, tea.Batch(stopCmd, runCmd)
+	case key.Matches(msg, m.keys.Leave):
+		m, cmd := m.handleStopConfirm()
+		return m, tea.Batch(cmd, message.With(message.Notification{Text: "Statement not run"}))
+	default:
+		confirm, cmd := m.confirm.Update(msg)
+		m.confirm = confirm
+		return m, cmd
+	}
+}
+
+func (m Model) handleStopConfirm() (Model, tea.Cmd) {
+	m.state.pending = ""
+	m.state.pendingAnalyze = false
+	m.confirm.Blur()
+	return m, message.With(message.UnblockCommandLine{})
+}
+
+func (m Model) confirmView() string {
+	width := max(m.width/2, 20)
+
+	text := lipgloss.NewStyle().Foreground(color.Text).Width(width)
+	query := lipgloss.NewStyle().Foreground(color.SecondaryText).Width(width)
+	hint := lipgloss.NewStyle().Foreground(color.Placeholder)
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.ThickBorder()).
+		BorderForeground(color.Error).
+		Padding(0, padding).
+		Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			text.Render(fmt.Sprintf("This statement may destroy data on prod context %q:", m.context)),
+			query.Render(m.state.pending),
+			"",
+			text.Render("Type the name of the context to run it."),
+			m.confirm.View(),
+			hint.Render(fmt.Sprintf("%s to run, %s to cancel", m.keys.Execute.Help().Key, m.keys.Leave.Help().Key)),
+		))
+}
@@ -2,54 +2,104 @@ package queryrun
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/evertras/bubble-table/table"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/objects/panels/details/rows"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
+	"github.com/hrvadl/gowatchsql/pkg/overlay"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
+	"github.com/hrvadl/gowatchsql/pkg/xtable"
 )
 
 const (
 	padding     = 1
 	margin      = 1
 	placeholder = "SELECT * FROM"
+	statsSource = "query"
 )
 
-var inputStyles = lipgloss.NewStyle().MarginTop(margin).PaddingRight(1).Foreground(color.Border)
-
-type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
-}
+var (
+	errNotConnected = errors.New("no context selected")
+	errRunning      = errors.New("a query is already running")
+	errCancelled    = errors.New("query cancelled")
+	errNotWatchable = errors.New("only read-only queries can be watched")
+)
 
-func NewModel(ef ExplorerFactory) Model {
+func NewModel(keys keymap.Map) Model {
 	input := textinput.New()
 	input.Focus()
 	input.Placeholder = placeholder
 	input.PromptStyle = lipgloss.NewStyle().Foreground(color.MainAccent)
 
+	s := spinner.New()
+	s.Spinner = spinner.Dot
+	s.Style = lipgloss.NewStyle().Foreground(color.MainAccent)
+
+	rename := textinput.New()
+	rename.Prompt = "Name: "
+	rename.PromptStyle = lipgloss.NewStyle().Foreground(color.MainAccent)
+
+	confirm := textinput.New()
+	confirm.PromptStyle = lipgloss.NewStyle().Foreground(color.Error)
+
 	return Model{
-		input:           input,
-		rows:            rows.NewModel(ef),
-		explorerFactory: ef,
+		input:     input,
+		rename:    rename,
+		confirm:   confirm,
+		spinner:   s,
+		rows:      rows.NewModel(keys),
+		keys:      newKeyMap(keys),
+		tabsKeys:  newTabsKeyMap(keys),
+		planKeys:  newPlanKeyMap(keys),
+		tableKeys: keys.Table(),
 	}
 }
 
 type Model struct {
-	width           int
-	height          int
-	state           state
-	input           textinput.Model
-	explorerFactory ExplorerFactory
-	explorer        engine.Explorer
-	rows            rows.Model
-	table           string
+	width    int
+	height   int
+	state    state
+	input    textinput.Model
+	explorer engine.Explorer
+	readOnly bool
+	rows     rows.Model
+	table    string
+	keys     keyMap
+
+	context     string
+	environment cfg.Environment
+	confirm     textinput.Model
+
+	timeouts cfg.Timeouts
+	spinner  spinner.Model
+	// cancel stops the running query, it is set only while one runs.
+	cancel context.CancelFunc
+
+	results   []result
+	resultIDs int
+	rename    textinput.Model
+	tabsKeys  tabsKeyMap
+	tableKeys table.KeyMap
+	planKeys  planKeyMap
 }
 
 func (m Model) Init() tea.Cmd {
@@ -70,8 +120,26 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		return m.delegateToRows(msg)
 	case message.SelectedContext:
 		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		return m.handleExplorerReady(msg)
+	case message.ExplorerFailed:
+		return m.delegateToRows(msg)
 	case message.FetchedRows:
 		return m.delegateToRows(msg)
+	case message.ExecuteCommand:
+		return m.handleExecuteCommand(msg)
+	case message.ExecutedQuery:
+		return m.handleExecutedQuery(msg)
+	case message.ExplainedQuery:
+		return m.handleExplainedQuery(msg)
+	case spinner.TickMsg:
+		return m.handleSpinnerTick(msg)
+	case message.Watch:
+		return m.handleWatch(msg)
+	case watch.Tick:
+		return m.handleWatchTick(msg)
+	case watch.Result:
+		return m.handleWatchResult(msg)
 	case message.Error:
 		return m.handleError(msg)
 	default:
@@ -88,7 +156,7 @@ func (m Model) View() string {
 		titleText += " - " + m.table
 	}
 
-	inputStyles := inputStyles
+	inputStyles := newInputStyles()
 	m.input.TextStyle = m.input.TextStyle.Foreground(color.Border)
 	if m.state.focused == promptFocused {
 		m.input.TextStyle = m.input.TextStyle.Foreground(color.Text)
@@ -96,17 +164,38 @@ func (m Model) View() string {
 
 	slog.Info("Rendering query view", slog.Any("err", m.state.err))
 	if m.state.err != nil {
-		m.input.Placeholder = m.state.err.Error()
+		m.input.Placeholder = secret.Mask(m.state.err.Error())
 		m.input.PlaceholderStyle = m.input.PlaceholderStyle.Foreground(color.Error)
 	}
 
 	title := titleStyles.Render(titleText)
 	input := inputStyles.Render(m.input.View())
-	return barStyles.Render(title, lipgloss.JoinVertical(lipgloss.Top, input, m.rows.View()))
+	panel := barStyles.Render(
+		title,
+		lipgloss.JoinVertical(lipgloss.Top, input, m.runningView(), m.tabsView(), m.activeTabView()),
+	)
+
+	if m.state.pending == "" {
+		return panel
+	}
+
+	return overlay.Place(m.width/4, m.height/4, m.confirmView(), panel, true)
 }
 
-func (m Model) Help() string {
-	return "searchbar help"
+func (m Model) Help() help.KeyMap {
+	if m.state.focused != tableFocused {
+		return m.keys
+	}
+
+	if m.state.tab == 0 {
+		return xhelp.Join(m.keys, m.tabsKeys, m.rows.Help())
+	}
+
+	if m.results[m.state.tab-1].explained {
+		return xhelp.Join(m.keys, m.tabsKeys, m.planKeys)
+	}
+
+	return xhelp.Join(m.keys, m.tabsKeys, xtable.NewHelp(m.tableKeys))
 }
 
 func (m Model) Value() string {
@@ -114,15 +203,41 @@ func (m Model) Value() string {
 }
 
 func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
-	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
-	defer cancel()
+	// The query of the previous context is dropped along with its
+	// result, so it doesn't keep this one from running.
+	if m.cancel != nil {
+		m.cancel()
+		m.cancel = nil
+	}
 
-	explorer, err := m.explorerFactory.Create(ctx, msg.Name, msg.DSN)
-	if err != nil {
-		return m, nil
+	// A statement confirmed for the previous context must not run on
+	// this one.
+	var stopCmd tea.Cmd
+	if m.state.pending != "" {
+		m, stopCmd = m.handleStopConfirm()
 	}
 
-	m.explorer = explorer
+	m.context = msg.Name
+	m.environment = msg.Environment
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.readOnly = msg.ReadOnly
+	m.explorer = nil
+
+	// Results of the previous context can't be re-run against this one.
+	m.results = slices.Clone(m.results)
+	for i := range m.results {
+		m.results[i].watch = watch.Watcher{}
+	}
+
+	m, cmd := m.delegateToRows(msg)
+	return m, tea.Batch(stopCmd, cmd)
+}
+
+func (m Model) handleExplorerReady(msg message.ExplorerReady) (Model, tea.Cmd) {
+	m.explorer = msg.Explorer
+	if m.readOnly {
+		m.explorer = msg.Explorer.ReadOnly()
+	}
 
 	return m.delegateToRows(msg)
 }
@@ -132,7 +247,7 @@ func (m Model) delegateToActiveModel(msg tea.Msg) (Model, tea.Cmd) {
 	case promptFocused:
 		return m.delegateToPrompt(msg)
 	case tableFocused:
-		return m.delegateToRows(msg)
+		return m.delegateToActiveTab(msg)
 	}
 
 	return m, nil
@@ -156,6 +271,23 @@ func (m Model) delegateToRows(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) delegateToActiveTab(msg tea.Msg) (Model, tea.Cmd) {
+	if m.state.tab == 0 {
+		return m.delegateToRows(msg)
+	}
+
+	m.results = slices.Clone(m.results)
+	r := &m.results[m.state.tab-1]
+	if r.explained {
+		r.plan = r.plan.Update(msg)
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	r.table, cmd = r.table.Update(msg)
+	return m, cmd
+}
+
 func (m Model) handleFocus() (Model, tea.Cmd) {
 	if m.state.focused == promptFocused {
 		m.input.Focus()
@@ -196,6 +328,13 @@ func (m Model) handleUpdateSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 	m.height = msg.Height - 2
 
 	m.input.Width = msg.Width - 10
+	m.rename.Width = msg.Width - 10
+
+	m.results = slices.Clone(m.results)
+	for i := range m.results {
+		m.results[i].table = m.results[i].table.WithMaxTotalWidth(m.resultWidth() - 1)
+	}
+
 	return m.delegateToAllModels(tea.WindowSizeMsg{Height: msg.Height - 5, Width: msg.Width - 5})
 }
 
@@ -216,34 +355,493 @@ func (m Model) handleKeyEnter() (Model, tea.Cmd) {
 	m.input.Placeholder = query
 	m.state.focused = tableFocused
 
-	return m, func() tea.Msg {
-		ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	return m.handleRunQuery(query)
+}
+
+func (m Model) handleExecuteCommand(msg message.ExecuteCommand) (Model, tea.Cmd) {
+	m.input.Blur()
+	m.input.Placeholder = msg.Cmd
+	m.state.err = nil
+	m.state.focused = tableFocused
+	return m.handleRunQuery(msg.Cmd)
+}
+
+func (m Model) handleRunQuery(query string) (Model, tea.Cmd) {
+	if m.explorer == nil {
+		return m, message.With(message.Error{Err: errNotConnected})
+	}
+
+	if m.cancel != nil {
+		return m, message.With(message.Error{Err: errRunning})
+	}
+
+	if m.environment.IsProd() && engine.IsDangerous(query) {
+		return m.handleStartConfirm(query, false)
+	}
+
+	return m.runQuery(query)
+}
+
+func (m Model) runQuery(query string) (Model, tea.Cmd) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
+	m.cancel = cancel
+	m.state.startedAt = time.Now()
+
+	return m, tea.Batch(m.spinner.Tick, m.commandExecute(ctx, cancel, query))
+}
+
+// handleExplain shows the plan of the query in the prompt, or of the
+// shown result when the prompt is empty. Analyzing runs the query to tell
+// how long each step took, so it is only done when asked for, and
+// confirmed outside of read-only contexts like running the query would be.
+func (m Model) handleExplain(analyze bool) (Model, tea.Cmd) {
+	query := m.Value()
+	if query == "" && m.state.tab > 0 {
+		query = m.results[m.state.tab-1].query
+	}
+
+	if query == "" {
+		return m, nil
+	}
+
+	if m.explorer == nil {
+		return m, message.With(message.Error{Err: errNotConnected})
+	}
+
+	if m.cancel != nil {
+		return m, message.With(message.Error{Err: errRunning})
+	}
+
+	if m.Value() != "" {
+		m.input.SetValue("")
+		m.input.Placeholder = query
+	}
+
+	if analyze && !m.readOnly && m.environment.IsProd() && engine.IsDangerous(query) {
+		return m.handleStartConfirm(query, true)
+	}
+
+	return m.runExplain(query, analyze)
+}
+
+func (m Model) runExplain(query string, analyze bool) (Model, tea.Cmd) {
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
+	m.cancel = cancel
+	m.state.startedAt = time.Now()
+
+	return m, tea.Batch(m.spinner.Tick, m.commandExplain(ctx, cancel, query, analyze))
+}
+
+func (m Model) handleCancelQuery() (Model, tea.Cmd) {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	return m, nil
+}
+
+func (m Model) handleSpinnerTick(msg spinner.TickMsg) (Model, tea.Cmd) {
+	if m.cancel == nil {
+		return m, nil
+	}
+
+	s, cmd := m.spinner.Update(msg)
+	m.spinner = s
+	return m, cmd
+}
+
+func (m Model) commandExecute(ctx context.Context, cancel context.CancelFunc, query string) tea.Cmd {
+	timeout, name := m.timeouts.Query, m.context
+	return func() tea.Msg {
 		defer cancel()
 
-		if err := m.explorer.Execute(ctx, query); err != nil {
+		start := time.Now()
+		if !engine.ReturnsRows(query) {
+			affected, err := m.explorer.Execute(ctx, query)
+			if err != nil {
+				slog.Error("Execute query", slog.Any("err", err), slog.Any("state", m.state))
+				return message.ExecutedQuery{Context: name, Query: query, Err: queryError(ctx, err, timeout)}
+			}
+
+			return message.Batch(
+				message.ExecutedQuery{Context: name, Query: query, Affected: affected},
+				message.QueryStats{
+					Source:   statsSource,
+					Took:     time.Since(start),
+					Rows:     affected,
+					Affected: true,
+				},
+			)
+		}
+
+		rows, cols, err := m.explorer.Query(ctx, query)
+		if err != nil {
 			slog.Error("Execute query", slog.Any("err", err), slog.Any("state", m.state))
-			return message.Error{Err: err}
+			return message.ExecutedQuery{Context: name, Query: query, Err: queryError(ctx, err, timeout)}
+		}
+
+		return message.Batch(
+			message.ExecutedQuery{Context: name, Query: query, Rows: rows, Cols: cols},
+			message.QueryStats{
+				Source: statsSource,
+				Took:   time.Since(start),
+				Rows:   int64(len(rows)),
+			},
+		)
+	}
+}
+
+func (m Model) handleWatch(msg message.Watch) (Model, tea.Cmd) {
+	if m.state.tab == 0 {
+		return m.delegateToRows(msg)
+	}
+
+	m.results = slices.Clone(m.results)
+	r := &m.results[m.state.tab-1]
+
+	if msg.Interval == 0 {
+		r.watch = watch.Watcher{}
+		return m, nil
+	}
+
+	if r.statement || r.explained || !engine.IsReadOnly(r.query) {
+		return m, message.With(message.Error{Err: errNotWatchable})
+	}
+
+	r.watch = watch.New(msg.Interval)
+	return m, m.commandWatch(r.watch, r.query)
+}
+
+func (m Model) handleWatchTick(msg watch.Tick) (Model, tea.Cmd) {
+	m, cmd := m.delegateToRows(msg)
+
+	for _, r := range m.results {
+		if r.watch.OwnsTick(msg) {
+			return m, tea.Batch(cmd, m.commandWatch(r.watch, r.query))
+		}
+	}
+
+	return m, cmd
+}
+
+func (m Model) handleWatchResult(msg watch.Result) (Model, tea.Cmd) {
+	m, cmd := m.delegateToRows(msg)
+
+	i := slices.IndexFunc(m.results, func(r result) bool { return r.watch.OwnsResult(msg) })
+	if i == -1 {
+		return m, cmd
+	}
+
+	m.results = slices.Clone(m.results)
+	r := &m.results[i]
+
+	if msg.Err != nil {
+		r.watch = watch.Watcher{}
+		return m, tea.Batch(cmd, message.With(message.Error{Err: msg.Err}))
+	}
+
+	var diff []watch.Row
+	r.watch, diff = r.watch.Observe(msg)
+	values, styles := watch.Split(diff)
+
+	r.table = xtable.New(msg.Cols, values).
+		WithKeyMap(m.tableKeys).
+		WithMaxTotalWidth(m.resultWidth() - 1).
+		WithRowStyles(styles)
+
+	return m, tea.Batch(cmd, r.watch.Schedule())
+}
+
+// commandWatch re-runs the query of a watched result. Results have no
+// primary key to match rows by, so whole rows are compared.
+func (m Model) commandWatch(w watch.Watcher, query string) tea.Cmd {
+	explorer := m.explorer
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeouts.Query)
+	return func() tea.Msg {
+		defer cancel()
+
+		rows, cols, err := explorer.Query(ctx, query)
+		return w.Result(rows, cols, []string{}, err)
+	}
+}
+
+func (m Model) commandExplain(ctx context.Context, cancel context.CancelFunc, query string, analyze bool) tea.Cmd {
+	timeout, name := m.timeouts.Query, m.context
+	explorer := m.explorer
+	return func() tea.Msg {
+		defer cancel()
+
+		plan, err := explorer.Explain(ctx, query, analyze)
+		if err != nil {
+			slog.Error("Explain query", slog.Any("err", err))
+			return message.ExplainedQuery{Context: name, Query: query, Err: queryError(ctx, err, timeout)}
 		}
 
-		return message.SelectedTable{Name: m.table}
+		return message.ExplainedQuery{Context: name, Query: query, Plan: plan}
+	}
+}
+
+func (m Model) handleExplainedQuery(msg message.ExplainedQuery) (Model, tea.Cmd) {
+	if msg.Context != m.context {
+		return m, nil
+	}
+
+	m.cancel = nil
+	if msg.Err != nil {
+		m.state.err = msg.Err
+		return m, message.With(message.Error{Err: msg.Err})
+	}
+
+	m.resultIDs++
+	m.results = addResult(slices.Clone(m.results), newPlanResult(m.resultIDs, msg, m.planKeys))
+	m.state.tab = len(m.results)
+	m.state.focused = tableFocused
+	m.input.Blur()
+
+	return m, nil
+}
+
+func (m Model) handleExecutedQuery(msg message.ExecutedQuery) (Model, tea.Cmd) {
+	if msg.Context != m.context {
+		return m, nil
+	}
+
+	m.cancel = nil
+	if msg.Err != nil {
+		m.state.err = msg.Err
+		return m, message.With(message.Error{Err: msg.Err})
+	}
+
+	m.resultIDs++
+	m.results = addResult(
+		slices.Clone(m.results),
+		newResult(m.resultIDs, msg, m.tableKeys, m.resultWidth()),
+	)
+	m.state.tab = len(m.results)
+
+	if m.table == "" {
+		return m, nil
 	}
+
+	return m, message.With(message.SelectedTable{Name: m.table})
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyCtrlC:
+	if msg.Type == tea.KeyCtrlC {
 		return Model{}, tea.Quit
-	case tea.KeyEsc:
+	}
+
+	if key.Matches(msg, m.keys.Cancel) {
+		return m.handleCancelQuery()
+	}
+
+	if m.state.pending == "" && !m.state.renaming {
+		switch {
+		case key.Matches(msg, m.keys.Explain):
+			return m.handleExplain(false)
+		case key.Matches(msg, m.keys.Analyze):
+			return m.handleExplain(true)
+		}
+	}
+
+	if m.state.pending != "" {
+		return m.handleConfirmKeyPress(msg)
+	}
+
+	if m.state.renaming {
+		return m.handleRenameKeyPress(msg)
+	}
+
+	if m.state.focused == tableFocused {
+		if mm, cmd, ok := m.handleTabsKeyPress(msg); ok {
+			return mm, cmd
+		}
+	}
+
+	switch {
+	case key.Matches(msg, m.keys.Leave):
 		return m.handleMoveFocus()
-	case tea.KeyShiftTab, tea.KeyTab:
+	case key.Matches(msg, m.keys.SwitchFocus):
 		return m.handleMoveTabFocus()
-	case tea.KeyEnter:
+	case key.Matches(msg, m.keys.Execute):
 		return m.handleKeyEnter()
 	default:
 		return m.delegateToActiveModel(msg)
 	}
 }
 
+func (m Model) handleTabsKeyPress(msg tea.KeyMsg) (Model, tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.tabsKeys.NextTab):
+		m.state.tab = (m.state.tab + 1) % (len(m.results) + 1)
+	case key.Matches(msg, m.tabsKeys.PrevTab):
+		m.state.tab = (m.state.tab + len(m.results)) % (len(m.results) + 1)
+	case key.Matches(msg, m.tabsKeys.CloseTab):
+		return m.handleCloseTab()
+	case key.Matches(msg, m.tabsKeys.PinTab):
+		return m.handlePinTab()
+	case key.Matches(msg, m.tabsKeys.RenameTab):
+		return m.handleStartRename()
+	default:
+		return m, nil, false
+	}
+
+	return m, nil, true
+}
+
+func (m Model) handleCloseTab() (Model, tea.Cmd, bool) {
+	if m.state.tab == 0 {
+		return m, nil, true
+	}
+
+	m.results = slices.Delete(slices.Clone(m.results), m.state.tab-1, m.state.tab)
+	m.state.tab = min(m.state.tab, len(m.results))
+	return m, nil, true
+}
+
+func (m Model) handlePinTab() (Model, tea.Cmd, bool) {
+	if m.state.tab == 0 {
+		return m, nil, true
+	}
+
+	m.results = slices.Clone(m.results)
+	r := &m.results[m.state.tab-1]
+	r.pinned = !r.pinned
+	return m, nil, true
+}
+
+func (m Model) handleStartRename() (Model, tea.Cmd, bool) {
+	if m.state.tab == 0 {
+		return m, nil, true
+	}
+
+	m.state.renaming = true
+	m.rename.SetValue(m.results[m.state.tab-1].name)
+	m.rename.CursorEnd()
+	return m, tea.Batch(m.rename.Focus(), message.With(message.BlockCommandLine{})), true
+}
+
+func (m Model) handleRenameKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
+	switch {
+	case key.Matches(msg, m.keys.Execute):
+		if name := strings.TrimSpace(m.rename.Value()); name != "" {
+			m.results = slices.Clone(m.results)
+			m.results[m.state.tab-1].name = name
+		}
+		return m.handleStopRename()
+	case key.Matches(msg, m.keys.Leave):
+		return m.handleStopRename()
+	default:
+		rename, cmd := m.rename.Update(msg)
+		m.rename = rename
+		return m, cmd
+	}
+}
+
+func (m Model) handleStopRename() (Model, tea.Cmd) {
+	m.state.renaming = false
+	m.rename.Blur()
+	return m, message.With(message.UnblockCommandLine{})
+}
+
+func (m Model) runningView() string {
+	if m.cancel == nil {
+		return ""
+	}
+
+	elapsed := time.Since(m.state.startedAt).Round(time.Millisecond * 100)
+	text := fmt.Sprintf(" Running for %s, %s to cancel", elapsed, m.keys.Cancel.Help().Key)
+	return m.spinner.View() + lipgloss.NewStyle().Foreground(color.SecondaryText).Render(text)
+}
+
+// queryError explains why the query stopped when it was cancelled by the
+// user or ran out of time, instead of showing the bare context error.
+func queryError(ctx context.Context, err error, timeout time.Duration) error {
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		return fmt.Errorf("query timed out after %s", timeout)
+	case errors.Is(ctx.Err(), context.Canceled):
+		return errCancelled
+	default:
+		return err
+	}
+}
+
+func (m Model) tabsView() string {
+	tableTitle := tableTabTitle
+	if m.table != "" {
+		tableTitle = m.table
+	}
+
+	tabs := []string{m.newTabStyles(m.state.tab == 0).Render(tableTitle)}
+	for i, r := range m.results {
+		tabs = append(tabs, m.newTabStyles(m.state.tab == i+1).Render(r.title()))
+	}
+
+	return lipgloss.NewStyle().
+		MaxWidth(m.resultWidth()).
+		Render(lipgloss.JoinHorizontal(lipgloss.Left, tabs...))
+}
+
+func (m Model) activeTabView() string {
+	if m.state.tab == 0 {
+		return m.rows.View()
+	}
+
+	r := m.results[m.state.tab-1]
+
+	header := lipgloss.NewStyle().
+		Foreground(color.SecondaryText).
+		MaxWidth(m.resultWidth()).
+		Render(r.query)
+	if m.state.renaming {
+		header = m.rename.View()
+	}
+	if r.watch.Active() {
+		header = lipgloss.JoinVertical(lipgloss.Left, header, r.watch.View())
+	}
+
+	var content string
+	switch {
+	case r.explained:
+		content = r.plan.View(m.resultWidth(), m.planHeight())
+	case r.statement:
+		content = lipgloss.NewStyle().
+			Foreground(color.SecondaryText).
+			Render(fmt.Sprintf("Statement executed, %d rows affected", r.affected))
+	default:
+		content = r.table.View()
+	}
+
+	return lipgloss.NewStyle().
+		Margin(margin).
+		Render(lipgloss.JoinVertical(lipgloss.Top, header, content))
+}
+
+func (m Model) resultWidth() int {
+	return m.width - 5
+}
+
+// planHeight is what is left of the panel for a plan below the prompt,
+// the tabs and the query of the tab.
+func (m Model) planHeight() int {
+	const chrome = 12
+	return m.height - chrome
+}
+
+func (m Model) newTabStyles(active bool) lipgloss.Style {
+	base := lipgloss.
+		NewStyle().
+		Border(lipgloss.NormalBorder()).
+		Padding(0, padding).
+		Align(lipgloss.Center)
+
+	if active {
+		return base.BorderForeground(color.MainAccent)
+	}
+
+	return base.BorderForeground(color.Border)
+}
+
 func (m Model) newBarStyles() lipgloss.Style {
 	base := lipgloss.
 		NewStyle().
@@ -251,12 +849,23 @@ func (m Model) newBarStyles() lipgloss.Style {
 		Width(m.width).
 		Border(lipgloss.NormalBorder())
 
+	// Prod contexts always have a red border as a reminder of where
+	// the queries go.
+	accent, border := color.MainAccent, color.Border
+	if m.environment.IsProd() {
+		accent, border = color.Error, color.Error
+	}
+
 	if m.state.active {
 		return base.Border(lipgloss.ThickBorder()).
-			BorderForeground(color.MainAccent)
+			BorderForeground(accent)
 	}
 
-	return base.BorderForeground(color.Border)
+	return base.BorderForeground(border)
+}
+
+func newInputStyles() lipgloss.Style {
+	return lipgloss.NewStyle().MarginTop(margin).PaddingRight(1).Foreground(color.Border)
 }
 
 func (m Model) newTitleStyles() lipgloss.Style {
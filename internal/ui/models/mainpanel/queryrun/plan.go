@@ -0,0 +1,150 @@
+package queryrun
+
+import (
+	"fmt"
+	"maps"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+)
+
+const (
+	expandedMark  = "▾ "
+	collapsedMark = "▸ "
+	leafMark      = "• "
+	slowScanMark  = " ⚠ full scan"
+)
+
+// planTree shows a query plan as a tree whose nodes can be collapsed.
+type planTree struct {
+	roots []engine.PlanNode
+	// collapsed holds paths of the collapsed nodes, see planLine.
+	collapsed map[string]bool
+	cursor    int
+	keys      planKeyMap
+}
+
+// planLine is a node visible in the tree. Its path is made of indexes of
+// the node and its ancestors among their siblings, e.g. "0.2.1".
+type planLine struct {
+	node  engine.PlanNode
+	path  string
+	depth int
+}
+
+func newPlanTree(roots []engine.PlanNode, keys planKeyMap) planTree {
+	return planTree{roots: roots, collapsed: make(map[string]bool), keys: keys}
+}
+
+func (t planTree) Update(msg tea.Msg) planTree {
+	km, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return t
+	}
+
+	lines := t.lines()
+	switch {
+	case key.Matches(km, t.keys.Down):
+		t.cursor = min(t.cursor+1, len(lines)-1)
+	case key.Matches(km, t.keys.Up):
+		t.cursor = max(t.cursor-1, 0)
+	case key.Matches(km, t.keys.Toggle):
+		if t.cursor < len(lines) && len(lines[t.cursor].node.Children) > 0 {
+			path := lines[t.cursor].path
+			t.collapsed = maps.Clone(t.collapsed)
+			t.collapsed[path] = !t.collapsed[path]
+		}
+	}
+
+	return t
+}
+
+func (t planTree) View(width, height int) string {
+	lines := t.lines()
+	if len(lines) == 0 {
+		return lipgloss.NewStyle().Foreground(color.SecondaryText).Render("The plan is empty")
+	}
+
+	height = max(height, 1)
+	offset := max(t.cursor-height+1, 0)
+
+	rendered := make([]string, 0, height)
+	for i := offset; i < len(lines) && i < offset+height; i++ {
+		rendered = append(rendered, t.lineView(lines[i], i == t.cursor, width))
+	}
+
+	return strings.Join(rendered, "\n")
+}
+
+func (t planTree) lines() []planLine {
+	var lines []planLine
+
+	var walk func(nodes []engine.PlanNode, prefix string, depth int)
+	walk = func(nodes []engine.PlanNode, prefix string, depth int) {
+		for i, n := range nodes {
+			path := prefix + strconv.Itoa(i)
+			lines = append(lines, planLine{node: n, path: path, depth: depth})
+			if !t.collapsed[path] {
+				walk(n.Children, path+".", depth+1)
+			}
+		}
+	}
+	walk(t.roots, "", 0)
+
+	return lines
+}
+
+func (t planTree) lineView(l planLine, selected bool, width int) string {
+	mark := leafMark
+	if len(l.node.Children) > 0 {
+		mark = expandedMark
+		if t.collapsed[l.path] {
+			mark = collapsedMark
+		}
+	}
+
+	name := l.node.Operation
+	if l.node.Relation != "" {
+		name += " on " + l.node.Relation
+	}
+
+	nameStyle := lipgloss.NewStyle().Foreground(color.Text)
+	if l.node.IsSlowScan() {
+		nameStyle = nameStyle.Foreground(color.Error).Bold(true)
+		name += slowScanMark
+	}
+
+	info := lipgloss.NewStyle().Foreground(color.SecondaryText)
+	line := strings.Repeat("  ", l.depth) + mark + nameStyle.Render(name)
+	if l.node.Detail != "" {
+		line += info.Render(" (" + l.node.Detail + ")")
+	}
+	if stats := planStats(l.node); stats != "" {
+		line += info.Render("  " + stats)
+	}
+
+	style := lipgloss.NewStyle().MaxWidth(width)
+	if selected {
+		style = style.Reverse(true)
+	}
+
+	return style.Render(line)
+}
+
+func planStats(n engine.PlanNode) string {
+	stats := make([]string, 0, 3)
+	if n.Estimated {
+		stats = append(stats, fmt.Sprintf("cost=%.2f", n.Cost), fmt.Sprintf("rows=%.0f", n.Rows))
+	}
+	if n.Analyzed {
+		stats = append(stats, "time="+n.Time.Round(time.Microsecond).String())
+	}
+	return strings.Join(stats, " ")
+}
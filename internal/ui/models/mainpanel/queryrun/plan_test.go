@@ -0,0 +1,95 @@
+package queryrun
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestPlanTree(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	var (
+		down   = tea.KeyMsg{Type: tea.KeyDown}
+		up     = tea.KeyMsg{Type: tea.KeyUp}
+		toggle = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	)
+
+	roots := []engine.PlanNode{
+		{
+			Operation: "Hash Join",
+			Children: []engine.PlanNode{
+				{Operation: "Seq Scan", Relation: "orders"},
+				{Operation: "Index Scan", Relation: "users"},
+			},
+		},
+		{Operation: "Sort"},
+	}
+
+	operations := func(tree planTree) []string {
+		var ops []string
+		for _, l := range tree.lines() {
+			ops = append(ops, l.node.Operation)
+		}
+		return ops
+	}
+
+	tests := []struct {
+		name       string
+		keys       []tea.KeyMsg
+		want       []string
+		wantCursor int
+	}{
+		{
+			name: "Should show every node expanded",
+			want: []string{"Hash Join", "Seq Scan", "Index Scan", "Sort"},
+		},
+		{
+			name: "Should hide children of collapsed node",
+			keys: []tea.KeyMsg{toggle},
+			want: []string{"Hash Join", "Sort"},
+		},
+		{
+			name:       "Should expand collapsed node again",
+			keys:       []tea.KeyMsg{toggle, down, up, toggle},
+			want:       []string{"Hash Join", "Seq Scan", "Index Scan", "Sort"},
+			wantCursor: 0,
+		},
+		{
+			name:       "Should not collapse leaf",
+			keys:       []tea.KeyMsg{down, toggle},
+			want:       []string{"Hash Join", "Seq Scan", "Index Scan", "Sort"},
+			wantCursor: 1,
+		},
+		{
+			name:       "Should keep cursor inside visible nodes",
+			keys:       []tea.KeyMsg{toggle, down, down, down},
+			want:       []string{"Hash Join", "Sort"},
+			wantCursor: 1,
+		},
+		{
+			name: "Should not move cursor above first node",
+			keys: []tea.KeyMsg{up},
+			want: []string{"Hash Join", "Seq Scan", "Index Scan", "Sort"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			tree := newPlanTree(roots, newPlanKeyMap(keymap.Default()))
+			for _, k := range tt.keys {
+				tree = tree.Update(k)
+			}
+
+			require.Equal(t, tt.want, operations(tree))
+			require.Equal(t, tt.wantCursor, tree.cursor)
+		})
+	}
+}
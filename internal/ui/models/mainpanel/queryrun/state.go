@@ -1,5 +1,7 @@
 package queryrun
 
+import "time"
+
 type focused int
 
 const (
@@ -11,4 +13,13 @@ type state struct {
 	active  bool
 	focused focused
 	err     error
+	// tab is 0 for the selected table and i for results[i-1].
+	tab      int
+	renaming bool
+	// startedAt is when the running query was started.
+	startedAt time.Time
+	// pending is the statement waiting for a confirmation to run.
+	pending string
+	// pendingAnalyze is set when pending is to be run by EXPLAIN ANALYZE.
+	pendingAnalyze bool
 }
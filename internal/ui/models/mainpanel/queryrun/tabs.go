@@ -0,0 +1,89 @@
+package queryrun
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/evertras/bubble-table/table"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
+	"github.com/hrvadl/gowatchsql/pkg/xtable"
+)
+
+const (
+	maxResults    = 8
+	maxTitleWidth = 18
+	pinnedMark    = "● "
+	tableTabTitle = "Table"
+)
+
+// result is a tab holding the output of one query together with the
+// query text, so several results can be compared side by side.
+type result struct {
+	name   string
+	query  string
+	pinned bool
+	// statement is set when the query returned no columns, e.g. for
+	// INSERT or CREATE, so there is no table to show.
+	statement bool
+	affected  int64
+	table     xtable.Model
+	watch     watch.Watcher
+	// explained is set when the tab shows the plan of the query
+	// instead of its rows.
+	explained bool
+	plan      planTree
+}
+
+func newResult(id int, msg message.ExecutedQuery, keys table.KeyMap, width int) result {
+	return result{
+		name:      fmt.Sprintf("Result %d", id),
+		query:     msg.Query,
+		statement: len(msg.Cols) == 0,
+		affected:  msg.Affected,
+		table: xtable.New(msg.Cols, msg.Rows).
+			WithKeyMap(keys).
+			WithMaxTotalWidth(width - 1),
+	}
+}
+
+func newPlanResult(id int, msg message.ExplainedQuery, keys planKeyMap) result {
+	return result{
+		name:      fmt.Sprintf("Plan %d", id),
+		query:     msg.Query,
+		explained: true,
+		plan:      newPlanTree(msg.Plan, keys),
+	}
+}
+
+func (r result) title() string {
+	name := r.name
+	if r.pinned {
+		name = pinnedMark + name
+	}
+
+	if runes := []rune(name); len(runes) > maxTitleWidth {
+		return string(runes[:maxTitleWidth-1]) + "…"
+	}
+
+	return name
+}
+
+// addResult appends the result and drops the oldest unpinned ones once
+// there are more than maxResults of them. The appended result is never
+// dropped, even if every other one is pinned.
+func addResult(results []result, r result) []result {
+	results = append(results, r)
+
+	for len(results) > maxResults {
+		old := results[:len(results)-1]
+		i := slices.IndexFunc(old, func(r result) bool { return !r.pinned })
+		if i == -1 {
+			break
+		}
+		results = slices.Delete(results, i, i+1)
+	}
+
+	return results
+}
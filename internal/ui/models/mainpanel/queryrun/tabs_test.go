@@ -0,0 +1,74 @@
+package queryrun
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestAddResult(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	newResults := func(n int, pinned ...int) []result {
+		results := make([]result, n)
+		for i := range results {
+			results[i].query = string(rune('a' + i))
+		}
+		for _, i := range pinned {
+			results[i].pinned = true
+		}
+		return results
+	}
+
+	queries := func(results []result) string {
+		var s string
+		for _, r := range results {
+			s += r.query
+		}
+		return s
+	}
+
+	tests := []struct {
+		name     string
+		results  []result
+		want     string
+		wantLeft int
+	}{
+		{
+			name:     "Should append result",
+			results:  newResults(2),
+			want:     "abz",
+			wantLeft: 3,
+		},
+		{
+			name:     "Should drop the oldest result when there are too many",
+			results:  newResults(maxResults),
+			want:     "bcdefghz",
+			wantLeft: maxResults,
+		},
+		{
+			name:     "Should keep pinned results",
+			results:  newResults(maxResults, 0, 1),
+			want:     "abdefghz",
+			wantLeft: maxResults,
+		},
+		{
+			name:     "Should keep all results when every one is pinned",
+			results:  newResults(maxResults, 0, 1, 2, 3, 4, 5, 6, 7),
+			want:     "abcdefghz",
+			wantLeft: maxResults + 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got := addResult(tt.results, result{query: "z"})
+			require.Equal(t, tt.want, queries(got))
+			require.Equal(t, tt.wantLeft, len(got))
+		})
+	}
+}
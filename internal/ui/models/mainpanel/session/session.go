@@ -0,0 +1,114 @@
+package session
+
+import (
+	"context"
+	"errors"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+)
+
+// ErrCancelled is the failure reported when connecting is cancelled.
+var ErrCancelled = errors.New("connecting cancelled")
+
+type ExplorerFactory interface {
+	Create(ctx context.Context, name, driver, dsn string) (engine.Explorer, error)
+}
+
+func New(ef ExplorerFactory) Session {
+	return Session{factory: ef}
+}
+
+// Session connects to the selected context once and shares the explorer
+// with every model of the main panel, so a failure is reported once
+// instead of by each of them.
+type Session struct {
+	factory ExplorerFactory
+
+	// id tells the results of the latest connect apart from the ones
+	// of contexts selected before.
+	id       int
+	context  string
+	explorer engine.Explorer
+	// cancel stops connecting, it is set only while connecting.
+	cancel context.CancelFunc
+}
+
+// Result is what connecting to the context returned.
+type Result struct {
+	id       int
+	context  string
+	explorer engine.Explorer
+	err      error
+}
+
+// Connect drops the explorer of the previous context and creates the one
+// of the selected context in the background.
+func (s Session) Connect(msg message.SelectedContext) (Session, tea.Cmd) {
+	if s.cancel != nil {
+		s.cancel()
+	}
+
+	s.id++
+	s.context = msg.Name
+	s.explorer = nil
+
+	ctx, cancel := context.WithTimeout(context.Background(), msg.Timeouts.WithDefaults().Connect)
+	s.cancel = cancel
+
+	id, factory := s.id, s.factory
+	return s, func() tea.Msg {
+		defer cancel()
+
+		explorer, err := factory.Create(ctx, msg.Name, msg.Driver, msg.DSN)
+		return Result{id: id, context: msg.Name, explorer: explorer, err: err}
+	}
+}
+
+// Update keeps the explorer of the latest connect and tells the models
+// whether it is ready.
+func (s Session) Update(res Result) (Session, tea.Cmd) {
+	if res.id != s.id {
+		return s, nil
+	}
+
+	s.cancel = nil
+
+	if res.err != nil {
+		return s, message.With(message.ExplorerFailed{Context: res.context, Err: res.err})
+	}
+
+	s.explorer = res.explorer
+	return s, message.With(message.ExplorerReady{Context: res.context, Explorer: res.explorer})
+}
+
+// Cancel stops connecting to the context, reporting it as failed so it
+// can be retried.
+func (s Session) Cancel() (Session, tea.Cmd) {
+	if s.cancel == nil {
+		return s, nil
+	}
+
+	s.cancel()
+	s.cancel = nil
+	s.id++
+	return s, message.With(message.ExplorerFailed{Context: s.context, Err: ErrCancelled})
+}
+
+// Connecting tells whether the explorer of the context is being created.
+func (s Session) Connecting() bool {
+	return s.cancel != nil
+}
+
+// Explorer returns the explorer of the selected context, nil until it
+// is ready.
+func (s Session) Explorer() engine.Explorer {
+	return s.explorer
+}
+
+// Context returns the name of the selected context.
+func (s Session) Context() string {
+	return s.context
+}
@@ -0,0 +1,115 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/mock/gomock"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/mocks"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestSessionConnect(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	errRefused := errors.New("connection refused")
+
+	tests := []struct {
+		name      string
+		createErr error
+		want      any
+	}{
+		{
+			name: "Should share explorer once connected",
+			want: message.ExplorerReady{Context: "local"},
+		},
+		{
+			name:      "Should report failure once",
+			createErr: errRefused,
+			want:      message.ExplorerFailed{Context: "local", Err: errRefused},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			ctrl := gomock.NewController(t)
+
+			var explorer engine.Explorer
+			if tt.createErr == nil {
+				explorer = engine.NewMockExplorer(ctrl)
+			}
+
+			factory := mocks.NewMockExplorerFactory(ctrl)
+			factory.EXPECT().Create(gomock.Any(), "local", "", "dsn").Times(1).Return(explorer, tt.createErr)
+
+			s, cmd := New(factory).Connect(message.SelectedContext{Name: "local", DSN: "dsn"})
+			s, cmd = s.Update(cmd().(Result))
+
+			want := tt.want
+			if ready, ok := want.(message.ExplorerReady); ok {
+				ready.Explorer = explorer
+				want = ready
+			}
+
+			require.Equal(t, want, cmd())
+			require.Equal(t, explorer, s.Explorer())
+		})
+	}
+}
+
+func TestSessionDropsStaleResult(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	old, current := engine.NewMockExplorer(ctrl), engine.NewMockExplorer(ctrl)
+	factory := mocks.NewMockExplorerFactory(ctrl)
+	factory.EXPECT().Create(gomock.Any(), "old", "", "old.db").Return(old, nil)
+	factory.EXPECT().Create(gomock.Any(), "current", "", "current.db").Return(current, nil)
+
+	s, oldCmd := New(factory).Connect(message.SelectedContext{Name: "old", DSN: "old.db"})
+	s, currentCmd := s.Connect(message.SelectedContext{Name: "current", DSN: "current.db"})
+
+	s, cmd := s.Update(currentCmd().(Result))
+	require.NotNil(t, cmd)
+
+	s, cmd = s.Update(oldCmd().(Result))
+	require.Nil(t, cmd)
+	require.Equal(t, engine.Explorer(current), s.Explorer())
+	require.Equal(t, "current", s.Context())
+}
+
+func TestSessionCancel(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	ctrl := gomock.NewController(t)
+
+	factory := mocks.NewMockExplorerFactory(ctrl)
+	factory.EXPECT().
+		Create(gomock.Any(), "slow", "", "slow.db").
+		DoAndReturn(func(ctx context.Context, _, _, _ string) (engine.Explorer, error) {
+			<-ctx.Done()
+			return nil, ctx.Err()
+		})
+
+	s, connectCmd := New(factory).Connect(message.SelectedContext{Name: "slow", DSN: "slow.db"})
+	require.True(t, s.Connecting())
+
+	s, cmd := s.Cancel()
+	require.False(t, s.Connecting())
+	require.Equal(t, message.ExplorerFailed{Context: "slow", Err: ErrCancelled}, cmd())
+
+	s, cmd = s.Update(connectCmd().(Result))
+	require.Nil(t, cmd, "result of cancelled connect must be dropped")
+	require.Nil(t, s.Explorer())
+
+	_, cmd = s.Cancel()
+	require.Nil(t, cmd)
+}
@@ -7,6 +7,7 @@ const (
 	contextsActive
 	newContextActive
 	queryRunActive
+	activityActive
 )
 
 type state struct {
@@ -0,0 +1,284 @@
+package statusbar
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/engine"
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
+)
+
+const (
+	separator        = " │ "
+	notificationTime = time.Second * 3
+	querySource      = "query"
+)
+
+func NewModel() Model {
+	return Model{}
+}
+
+// Model is a single line at the bottom of the screen showing the active
+// context, the server behind it, stats of the last query and transient
+// notifications.
+type Model struct {
+	width int
+
+	context     string
+	timeouts    cfg.Timeouts
+	engine      string
+	version     string
+	readOnly    bool
+	environment cfg.Environment
+	// reconnecting is set while the connection of the context is lost.
+	reconnecting bool
+
+	// query holds stats of the last query run from the prompt and load
+	// those of the last table load, so refreshing the table after a
+	// query doesn't hide how the query went.
+	query    *message.QueryStats
+	load     *message.QueryStats
+	note     string
+	noteID   int
+	noteIsOK bool
+}
+
+type clearNotification struct {
+	id int
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width = msg.Width
+		return m, nil
+	case message.SelectedContext:
+		return m.handleSelectedContext(msg)
+	case message.ExplorerReady:
+		return m.handleExplorerReady(msg)
+	case message.ExplorerFailed:
+		return m.handleExplorerFailed(msg)
+	case message.FetchedServerInfo:
+		m.engine = msg.Engine
+		m.version = msg.Version
+		return m, nil
+	case message.QueryStats:
+		return m.handleQueryStats(msg)
+	case message.Notification:
+		return m.handleNotification(msg.Text, true)
+	case message.Reconnecting:
+		return m.handleReconnecting(msg)
+	case message.Error:
+		return m.handleNotification(msg.Err.Error(), false)
+	case clearNotification:
+		return m.handleClearNotification(msg)
+	default:
+		return m, nil
+	}
+}
+
+func (m Model) View() string {
+	segments := []string{m.contextView()}
+	for _, stats := range []*message.QueryStats{m.query, m.load} {
+		if stats != nil {
+			segments = append(segments, statsView(*stats))
+		}
+	}
+
+	left := lipgloss.NewStyle().
+		Foreground(color.SecondaryText).
+		Render(strings.Join(segments, separator))
+	if tags := m.tagsView(); tags != "" {
+		left = tags + " " + left
+	}
+
+	right := m.noteView()
+	gap := max(m.width-lipgloss.Width(left)-lipgloss.Width(right), 1)
+
+	return lipgloss.NewStyle().
+		MaxWidth(m.width).
+		Render(left + strings.Repeat(" ", gap) + right)
+}
+
+func (m Model) handleSelectedContext(msg message.SelectedContext) (Model, tea.Cmd) {
+	m.context = msg.Name
+	m.readOnly = msg.ReadOnly
+	m.environment = msg.Environment
+	m.reconnecting = false
+	m.engine = ""
+	m.version = ""
+	m.timeouts = msg.Timeouts.WithDefaults()
+	m.query = nil
+	m.load = nil
+	return m, nil
+}
+
+func (m Model) handleExplorerReady(msg message.ExplorerReady) (Model, tea.Cmd) {
+	if msg.Context != m.context {
+		return m, nil
+	}
+	return m, m.commandFetchServerInfo(msg.Explorer)
+}
+
+func (m Model) handleExplorerFailed(msg message.ExplorerFailed) (Model, tea.Cmd) {
+	if msg.Context != m.context {
+		return m, nil
+	}
+	return m.handleNotification(fmt.Sprintf("Connect to %s: %v", msg.Context, msg.Err), false)
+}
+
+func (m Model) handleQueryStats(msg message.QueryStats) (Model, tea.Cmd) {
+	if msg.Source == querySource {
+		m.query = &msg
+		return m, nil
+	}
+
+	m.load = &msg
+	return m, nil
+}
+
+func (m Model) handleReconnecting(msg message.Reconnecting) (Model, tea.Cmd) {
+	if msg.Context != m.context {
+		return m, nil
+	}
+
+	m.reconnecting = !msg.Done
+	switch {
+	case !msg.Done:
+		return m, nil
+	case errors.Is(msg.Err, context.Canceled), errors.Is(msg.Err, context.DeadlineExceeded):
+		return m, nil
+	case msg.Err != nil:
+		return m.handleNotification("Lost connection to "+msg.Context, false)
+	default:
+		return m.handleNotification("Reconnected to "+msg.Context, true)
+	}
+}
+
+func (m Model) handleNotification(text string, ok bool) (Model, tea.Cmd) {
+	m.noteID++
+	// Errors of the drivers may quote the DSN.
+	m.note = secret.Mask(text)
+	m.noteIsOK = ok
+
+	id := m.noteID
+	return m, tea.Tick(notificationTime, func(time.Time) tea.Msg {
+		return clearNotification{id: id}
+	})
+}
+
+func (m Model) handleClearNotification(msg clearNotification) (Model, tea.Cmd) {
+	if msg.id == m.noteID {
+		m.note = ""
+	}
+	return m, nil
+}
+
+func (m Model) commandFetchServerInfo(explorer engine.Explorer) tea.Cmd {
+	timeout := m.timeouts.Query
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		info, err := explorer.ServerInfo(ctx)
+		if err != nil {
+			return nil
+		}
+
+		return message.FetchedServerInfo{Engine: info.Engine, Version: info.Version}
+	}
+}
+
+func (m Model) contextView() string {
+	if m.context == "" {
+		return "No context"
+	}
+
+	server := m.engine
+	if m.version != "" {
+		server += " " + m.version
+	}
+
+	if server == "" {
+		return m.context
+	}
+
+	return m.context + separator + server
+}
+
+// tagsView marks contexts which need care, so it is always clear which
+// database is about to be changed.
+func (m Model) tagsView() string {
+	tags := make([]string, 0, 3)
+	if m.environment != "" {
+		tags = append(tags, strings.ToUpper(string(m.environment)))
+	}
+	if m.readOnly {
+		tags = append(tags, "READ-ONLY")
+	}
+	if m.reconnecting {
+		tags = append(tags, "RECONNECTING")
+	}
+
+	if len(tags) == 0 {
+		return ""
+	}
+
+	fg := color.MainAccent
+	if m.environment.IsProd() || m.reconnecting {
+		fg = color.Error
+	}
+
+	return lipgloss.NewStyle().
+		Foreground(fg).
+		Bold(true).
+		Reverse(color.Disabled()).
+		Render(strings.Join(tags, " "))
+}
+
+func statsView(stats message.QueryStats) string {
+	took := stats.Took.Round(time.Millisecond)
+	if stats.Took < time.Millisecond {
+		took = stats.Took.Round(time.Microsecond)
+	}
+
+	verb := "returned"
+	if stats.Affected {
+		verb = "affected"
+	}
+
+	return fmt.Sprintf("%s %s, %d %s %s", stats.Source, took, stats.Rows, plural(stats.Rows), verb)
+}
+
+func (m Model) noteView() string {
+	if m.note == "" {
+		return ""
+	}
+
+	fg := color.MainAccent
+	if !m.noteIsOK {
+		fg = color.Error
+	}
+
+	return lipgloss.NewStyle().Foreground(fg).Bold(true).Render(m.note)
+}
+
+func plural(n int64) string {
+	if n == 1 {
+		return "row"
+	}
+	return "rows"
+}
@@ -0,0 +1,31 @@
+package welcome
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+)
+
+type keyMap struct {
+	Help    key.Binding
+	Command key.Binding
+	Close   key.Binding
+	Quit    key.Binding
+}
+
+func newKeyMap(keys keymap.Map) keyMap {
+	return keyMap{
+		Help:    keys.Get(keymap.GlobalHelp),
+		Command: keys.Get(keymap.GlobalCommand),
+		Close:   keys.Get(keymap.GlobalClose),
+		Quit:    keys.Get(keymap.GlobalQuit),
+	}
+}
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Help, k.Command, k.Quit}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{{k.Help, k.Close, k.Command, k.Quit}}
+}
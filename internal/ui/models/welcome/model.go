@@ -4,41 +4,64 @@ import (
 	"context"
 	"log/slog"
 
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/spinner"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
 	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/command"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/activity"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/mainpanel/session"
+	"github.com/hrvadl/gowatchsql/internal/ui/models/statusbar"
+	"github.com/hrvadl/gowatchsql/internal/ui/watch"
 	"github.com/hrvadl/gowatchsql/pkg/direction"
 	"github.com/hrvadl/gowatchsql/pkg/overlay"
+	"github.com/hrvadl/gowatchsql/pkg/xhelp"
 )
 
 type ExplorerFactory interface {
-	Create(ctx context.Context, name, dsn string) (engine.Explorer, error)
+	Create(ctx context.Context, name, driver, dsn string) (engine.Explorer, error)
 }
 
 type ConnectionsRepo interface {
 	GetConnections(context.Context) []cfg.Connection
 	DeleteConnection(ctx context.Context, dsn string) error
+	SaveConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	ImportConnection(ctx context.Context, conn cfg.Connection) (cfg.Connection, error)
+	Discover(ctx context.Context) ([]cfg.Found, error)
 }
 
-func NewModel(log *slog.Logger, ef ExplorerFactory, connections ConnectionsRepo) Model {
+func NewModel(
+	log *slog.Logger,
+	ef ExplorerFactory,
+	connections ConnectionsRepo,
+	keys keymap.Map,
+) Model {
 	return Model{
 		log:     log,
-		command: command.NewModel(),
-		main:    mainpanel.NewModel(ef, connections),
+		keys:    newKeyMap(keys),
+		help:    newHelp(),
+		command: command.NewModel(connections, keys),
+		main:    mainpanel.NewModel(ef, connections, keys),
+		status:  statusbar.NewModel(),
 	}
 }
 
 type Model struct {
 	command command.Model
 	main    mainpanel.Model
+	status  statusbar.Model
 
 	state state
+	keys  keyMap
+	help  help.Model
 
 	modalY int
 	modalX int
@@ -64,15 +87,38 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case message.MoveFocus:
 		return m.handleMoveFocus(msg)
 	case message.Command,
-		message.FetchedTableList,
 		message.FetchedRows,
 		message.FetchedColumns,
 		message.SelectedTable,
 		message.FetchedIndexes,
-		message.FetchedConstraints:
+		message.FetchedConstraints,
+		message.ExecutedQuery,
+		message.ExplainedQuery,
+		message.FetchedTableStats:
 		return m.delegateToMainPanel(msg)
 	case message.SelectedContext:
+		m, cmd := m.delegateToAll(msg)
+		m, statusCmd := m.delegateToStatusBar(msg)
+		return m, tea.Batch(cmd, statusCmd)
+	case message.FetchedTableList:
 		return m.delegateToAll(msg)
+	case session.Result, message.CancelConnect:
+		return m.delegateToMainPanel(msg)
+	case message.ExplorerReady, message.ExplorerFailed:
+		m, cmd := m.delegateToMainPanel(msg)
+		m, statusCmd := m.delegateToStatusBar(msg)
+		return m, tea.Batch(cmd, statusCmd)
+	case spinner.TickMsg, watch.Tick, watch.Result, activity.Tick, activity.Result:
+		return m.delegateToMainPanel(msg)
+	case message.QueryStats,
+		message.FetchedServerInfo,
+		message.Notification,
+		message.Reconnecting:
+		return m.delegateToStatusBar(msg)
+	case message.Error:
+		m, cmd := m.delegateToActive(msg)
+		m, statusCmd := m.delegateToStatusBar(msg)
+		return m, tea.Batch(cmd, statusCmd)
 	case message.BlockCommandLine:
 		return m.handleBlockCommandLine()
 	case message.UnblockCommandLine:
@@ -83,7 +129,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
-	window := lipgloss.JoinVertical(lipgloss.Top, m.command.View(), m.main.View())
+	window := lipgloss.JoinVertical(lipgloss.Top, m.command.View(), m.main.View(), m.status.View())
 	popupStyles := m.newPopupStyles()
 
 	if m.state.showModal {
@@ -110,13 +156,17 @@ func (m Model) handleUnblockCommandLine() (Model, tea.Cmd) {
 }
 
 func (m Model) handleUpdateSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
-	const searchBarHeight = 4
+	const (
+		searchBarHeight = 4
+		statusBarHeight = 1
+	)
 
 	m.height = msg.Height
 	m.width = msg.Width
 
 	m.modalX = m.width / 4
 	m.modalY = m.height / 4
+	m.help.Width = m.width / 2
 
 	searchbar, searchCmd := m.command.Update(tea.WindowSizeMsg{
 		Width:  msg.Width,
@@ -126,29 +176,27 @@ func (m Model) handleUpdateSize(msg tea.WindowSizeMsg) (Model, tea.Cmd) {
 
 	main, mainCmd := m.main.Update(tea.WindowSizeMsg{
 		Width:  msg.Width,
-		Height: msg.Height - searchBarHeight - 1,
+		Height: msg.Height - searchBarHeight - statusBarHeight - 1,
 	})
 	m.main = main.(mainpanel.Model)
 
+	m.status, _ = m.status.Update(tea.WindowSizeMsg{
+		Width:  msg.Width,
+		Height: statusBarHeight,
+	})
+
 	return m, tea.Batch(searchCmd, mainCmd)
 }
 
 func (m Model) handleKeyPress(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyCtrlC:
+	switch {
+	case key.Matches(msg, m.keys.Quit):
 		return m, tea.Quit
-	case tea.KeyEsc:
+	case key.Matches(msg, m.keys.Close):
 		return m.handleEscape(msg)
-	default:
-		return m.handleKeyRunes(msg)
-	}
-}
-
-func (m Model) handleKeyRunes(msg tea.KeyMsg) (Model, tea.Cmd) {
-	switch msg.String() {
-	case "?":
+	case key.Matches(msg, m.keys.Help):
 		return m.handleShowPopup()
-	case ":":
+	case key.Matches(msg, m.keys.Command):
 		return m.handleImmediateMoveFocus(msg)
 	default:
 		return m.delegateToActive(msg)
@@ -241,15 +289,33 @@ func (m Model) delegateToMainPanel(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m Model) delegateToStatusBar(msg tea.Msg) (Model, tea.Cmd) {
+	status, cmd := m.status.Update(msg)
+	m.status = status
+	return m, cmd
+}
+
 func (m Model) getHelpPopupContent() string {
+	var active help.KeyMap
 	switch m.state.active {
 	case cmdFocused:
-		return m.command.Help()
+		active = m.command.Help()
 	case mainFocused:
-		return m.main.Help()
-	default:
-		return ""
+		active = m.main.Help()
 	}
+
+	return m.help.FullHelpView(xhelp.Join(active, m.keys).FullHelp())
+}
+
+func newHelp() help.Model {
+	h := help.New()
+	h.Styles.FullKey = h.Styles.FullKey.Foreground(color.MainAccent)
+	h.Styles.FullDesc = h.Styles.FullDesc.Foreground(color.SecondaryText)
+	h.Styles.FullSeparator = h.Styles.FullSeparator.Foreground(color.Border)
+	h.Styles.ShortKey = h.Styles.ShortKey.Foreground(color.MainAccent)
+	h.Styles.ShortDesc = h.Styles.ShortDesc.Foreground(color.SecondaryText)
+	h.Styles.ShortSeparator = h.Styles.ShortSeparator.Foreground(color.Border)
+	return h
 }
 
 func (m Model) newPopupStyles() lipgloss.Style {
@@ -0,0 +1,61 @@
+package styles
+
+import (
+	"github.com/charmbracelet/huh"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+)
+
+// NewForForm styles the forms of the modals.
+func NewForForm() *huh.Theme {
+	return &huh.Theme{
+		Form: lipgloss.NewStyle().
+			Border(lipgloss.NormalBorder()).
+			BorderForeground(color.Border),
+		Group: lipgloss.NewStyle(),
+		Blurred: huh.FieldStyles{
+			TextInput: newTextInputStyles(),
+		},
+		Focused: huh.FieldStyles{
+			BlurredButton: lipgloss.NewStyle().
+				Foreground(color.Text).
+				Padding(0, 1).
+				Margin(1),
+			FocusedButton: lipgloss.NewStyle().
+				Foreground(color.Text).
+				Background(color.MainAccent).
+				Reverse(color.Disabled()).
+				Padding(0, 1).
+				Margin(1),
+			SelectSelector: lipgloss.NewStyle().
+				Foreground(color.MainAccent).
+				SetString("> "),
+			Option: lipgloss.NewStyle().
+				Foreground(color.Text),
+			MultiSelectSelector: lipgloss.NewStyle().
+				Foreground(color.MainAccent).
+				SetString("> "),
+			SelectedPrefix: lipgloss.NewStyle().
+				Foreground(color.MainAccent).
+				SetString("[x] "),
+			UnselectedPrefix: lipgloss.NewStyle().
+				Foreground(color.SecondaryText).
+				SetString("[ ] "),
+			SelectedOption: lipgloss.NewStyle().
+				Foreground(color.Text),
+			UnselectedOption: lipgloss.NewStyle().
+				Foreground(color.Text),
+			TextInput: newTextInputStyles(),
+		},
+	}
+}
+
+func newTextInputStyles() huh.TextInputStyles {
+	return huh.TextInputStyles{
+		Placeholder: lipgloss.NewStyle().Foreground(color.Placeholder),
+		Text:        lipgloss.NewStyle().Foreground(color.Text),
+		Cursor:      lipgloss.NewStyle().Foreground(color.Text),
+		Prompt:      lipgloss.NewStyle().Foreground(color.MainAccent),
+	}
+}
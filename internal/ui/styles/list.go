@@ -7,13 +7,11 @@ import (
 	"github.com/hrvadl/gowatchsql/internal/ui/color"
 )
 
-var titleStyle = lipgloss.NewStyle().
-	Foreground(color.Text).
-	Bold(true)
-
 func NewForList() list.Styles {
 	s := list.DefaultStyles()
-	s.Title = titleStyle
+	s.Title = lipgloss.NewStyle().
+		Foreground(color.Text).
+		Bold(true)
 	s.TitleBar = lipgloss.NewStyle().
 		Bold(true).
 		Border(lipgloss.NormalBorder(), false, false, true, false).
@@ -0,0 +1,116 @@
+package watch
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+)
+
+type Status int
+
+const (
+	Unchanged Status = iota
+	Inserted
+	Changed
+	Removed
+)
+
+type Row struct {
+	Values []string
+	Status Status
+}
+
+const (
+	keySeparator = "\x1f"
+	dupSeparator = "\x1e"
+)
+
+// Diff compares rows of two runs of the same query. Rows are matched by
+// the values of the key columns, or by the whole row when the key is
+// empty or missing from the columns, in which case a changed row shows
+// up as removed and inserted. Rows of the new run keep their order and
+// removed rows are appended at the end.
+func Diff(cols, key []string, prev, next [][]string) []Row {
+	idx := keyIndexes(cols, key)
+
+	prevByKey := make(map[string][]string, len(prev))
+	for i, k := range rowKeys(prev, idx) {
+		prevByKey[k] = prev[i]
+	}
+
+	nextKeys := rowKeys(next, idx)
+	diff := make([]Row, 0, len(next))
+	for i, k := range nextKeys {
+		status := Inserted
+		if p, ok := prevByKey[k]; ok {
+			status = Unchanged
+			if !slices.Equal(p, next[i]) {
+				status = Changed
+			}
+		}
+		diff = append(diff, Row{Values: next[i], Status: status})
+	}
+
+	seen := make(map[string]bool, len(nextKeys))
+	for _, k := range nextKeys {
+		seen[k] = true
+	}
+
+	for i, k := range rowKeys(prev, idx) {
+		if !seen[k] {
+			diff = append(diff, Row{Values: prev[i], Status: Removed})
+		}
+	}
+
+	return diff
+}
+
+func keyIndexes(cols, key []string) []int {
+	if len(key) == 0 {
+		return nil
+	}
+
+	idx := make([]int, 0, len(key))
+	for _, k := range key {
+		i := slices.Index(cols, k)
+		if i == -1 {
+			return nil
+		}
+		idx = append(idx, i)
+	}
+
+	return idx
+}
+
+// rowKeys builds the key of every row. Equal keys are numbered, so
+// duplicate rows are matched one to one.
+func rowKeys(rows [][]string, idx []int) []string {
+	keys := make([]string, 0, len(rows))
+	seen := make(map[string]int, len(rows))
+
+	for _, row := range rows {
+		k := rowKey(row, idx)
+		seen[k]++
+		if n := seen[k]; n > 1 {
+			k += dupSeparator + strconv.Itoa(n)
+		}
+		keys = append(keys, k)
+	}
+
+	return keys
+}
+
+func rowKey(row []string, idx []int) string {
+	if idx == nil {
+		return strings.Join(row, keySeparator)
+	}
+
+	values := make([]string, 0, len(idx))
+	for _, i := range idx {
+		if i < len(row) {
+			values = append(values, row[i])
+		}
+	}
+
+	return strings.Join(values, keySeparator)
+}
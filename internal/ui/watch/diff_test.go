@@ -0,0 +1,71 @@
+package watch
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestDiff(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	cols := []string{"id", "name"}
+	tests := []struct {
+		name string
+		key  []string
+		prev [][]string
+		next [][]string
+		want []Row
+	}{
+		{
+			name: "Should mark rows by primary key",
+			key:  []string{"id"},
+			prev: [][]string{{"1", "John"}, {"2", "Jane"}, {"3", "Bob"}},
+			next: [][]string{{"1", "John"}, {"2", "Janet"}, {"4", "Ann"}},
+			want: []Row{
+				{Values: []string{"1", "John"}, Status: Unchanged},
+				{Values: []string{"2", "Janet"}, Status: Changed},
+				{Values: []string{"4", "Ann"}, Status: Inserted},
+				{Values: []string{"3", "Bob"}, Status: Removed},
+			},
+		},
+		{
+			name: "Should compare whole rows without key",
+			prev: [][]string{{"1", "John"}, {"2", "Jane"}},
+			next: [][]string{{"1", "John"}, {"2", "Janet"}},
+			want: []Row{
+				{Values: []string{"1", "John"}, Status: Unchanged},
+				{Values: []string{"2", "Janet"}, Status: Inserted},
+				{Values: []string{"2", "Jane"}, Status: Removed},
+			},
+		},
+		{
+			name: "Should compare whole rows when key is not selected",
+			key:  []string{"uuid"},
+			prev: [][]string{{"1", "John"}},
+			next: [][]string{{"1", "Johnny"}},
+			want: []Row{
+				{Values: []string{"1", "Johnny"}, Status: Inserted},
+				{Values: []string{"1", "John"}, Status: Removed},
+			},
+		},
+		{
+			name: "Should match duplicate rows one to one",
+			prev: [][]string{{"1", "John"}, {"1", "John"}},
+			next: [][]string{{"1", "John"}},
+			want: []Row{
+				{Values: []string{"1", "John"}, Status: Unchanged},
+				{Values: []string{"1", "John"}, Status: Removed},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			require.Equal(t, tt.want, Diff(cols, tt.key, tt.prev, tt.next))
+		})
+	}
+}
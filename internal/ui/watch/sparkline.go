@@ -0,0 +1,29 @@
+package watch
+
+import (
+	"slices"
+	"strings"
+)
+
+var bars = []rune("▁▂▃▄▅▆▇█")
+
+// Sparkline draws the values as a line of bars scaled between the
+// smallest and the largest of them.
+func Sparkline(values []int64) string {
+	if len(values) == 0 {
+		return ""
+	}
+
+	lo, hi := slices.Min(values), slices.Max(values)
+
+	var b strings.Builder
+	for _, v := range values {
+		i := 0
+		if hi > lo {
+			i = int((v - lo) * int64(len(bars)-1) / (hi - lo))
+		}
+		b.WriteRune(bars[i])
+	}
+
+	return b.String()
+}
@@ -0,0 +1,189 @@
+package watch
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+)
+
+const (
+	MinInterval = time.Millisecond * 500
+	Off         = "off"
+
+	historySize = 30
+)
+
+var lastID atomic.Int64
+
+// Tick asks the watcher it belongs to for the next run of its query.
+type Tick struct {
+	id int64
+}
+
+// Result is the outcome of one run of a watched query.
+type Result struct {
+	id   int64
+	Rows [][]string
+	Cols []string
+	// Key are the columns identifying a row. It is nil when the key is
+	// not known yet and empty when there is none.
+	Key []string
+	Err error
+}
+
+// ParseInterval parses the argument of the watch command. A bare number
+// is a number of seconds, while an empty string or "off" stops watching
+// and yields zero.
+func ParseInterval(s string) (time.Duration, error) {
+	if s == "" || s == Off {
+		return 0, nil
+	}
+
+	var d time.Duration
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		d = time.Duration(secs * float64(time.Second))
+	} else if d, err = time.ParseDuration(s); err != nil {
+		return 0, fmt.Errorf("%w: invalid interval %q", errs.ErrValidation, s)
+	}
+
+	if d < MinInterval {
+		return 0, fmt.Errorf("%w: interval must be at least %s", errs.ErrValidation, MinInterval)
+	}
+
+	return d, nil
+}
+
+// Watcher re-runs a query every interval and compares each result with
+// the previous one. The zero value is a stopped watcher.
+type Watcher struct {
+	id       int64
+	interval time.Duration
+	key      []string
+	cols     []string
+	prev     [][]string
+	counts   []int64
+	summary  map[Status]int
+}
+
+func New(interval time.Duration) Watcher {
+	return Watcher{id: lastID.Add(1), interval: interval}
+}
+
+func (w Watcher) Active() bool {
+	return w.id != 0
+}
+
+func (w Watcher) OwnsTick(t Tick) bool {
+	return w.Active() && t.id == w.id
+}
+
+func (w Watcher) OwnsResult(r Result) bool {
+	return w.Active() && r.id == w.id
+}
+
+func (w Watcher) Key() []string {
+	return w.key
+}
+
+// Result wraps the outcome of a run so it gets back to this watcher.
+func (w Watcher) Result(rows [][]string, cols, key []string, err error) Result {
+	return Result{id: w.id, Rows: rows, Cols: cols, Key: key, Err: err}
+}
+
+// Schedule waits for the interval before asking for the next run.
+func (w Watcher) Schedule() tea.Cmd {
+	id := w.id
+	return tea.Tick(w.interval, func(time.Time) tea.Msg {
+		return Tick{id: id}
+	})
+}
+
+// Observe compares the result with the previous run. The first run, or
+// a run with other columns, has nothing to be compared with, so all its
+// rows are unchanged.
+func (w Watcher) Observe(r Result) (Watcher, []Row) {
+	if r.Key != nil {
+		w.key = r.Key
+	}
+
+	prev := w.prev
+	if !slices.Equal(w.cols, r.Cols) {
+		prev = r.Rows
+	}
+
+	rows := Diff(r.Cols, w.key, prev, r.Rows)
+
+	w.summary = make(map[Status]int)
+	for _, row := range rows {
+		w.summary[row.Status]++
+	}
+
+	w.cols = r.Cols
+	w.prev = r.Rows
+	w.counts = append(w.counts, int64(len(r.Rows)))
+	if len(w.counts) > historySize {
+		w.counts = w.counts[len(w.counts)-historySize:]
+	}
+
+	return w, rows
+}
+
+func (w Watcher) View() string {
+	if !w.Active() {
+		return ""
+	}
+
+	info := fmt.Sprintf("Watching every %s", w.interval)
+	if len(w.counts) > 0 {
+		info += fmt.Sprintf(
+			", %d rows %s +%d ~%d -%d",
+			w.counts[len(w.counts)-1],
+			Sparkline(w.counts),
+			w.summary[Inserted],
+			w.summary[Changed],
+			w.summary[Removed],
+		)
+	}
+
+	return lipgloss.NewStyle().Foreground(color.SecondaryText).Render(info)
+}
+
+// Split returns values of the rows with the style highlighting their
+// status, in the form the table expects.
+func Split(rows []Row) ([][]string, []lipgloss.Style) {
+	values := make([][]string, 0, len(rows))
+	styles := make([]lipgloss.Style, 0, len(rows))
+	for _, row := range rows {
+		values = append(values, row.Values)
+		styles = append(styles, Style(row.Status))
+	}
+	return values, styles
+}
+
+func Style(s Status) lipgloss.Style {
+	base := lipgloss.NewStyle()
+	switch s {
+	case Inserted:
+		if color.Disabled() {
+			return base.Bold(true)
+		}
+		return base.Foreground(color.Inserted)
+	case Changed:
+		if color.Disabled() {
+			return base.Italic(true)
+		}
+		return base.Foreground(color.Changed)
+	case Removed:
+		return base.Foreground(color.Error).Strikethrough(true)
+	default:
+		return base
+	}
+}
@@ -0,0 +1,88 @@
+package watch
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/domain/errs"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestParseInterval(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	tests := []struct {
+		name    string
+		input   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{
+			name:  "Should parse duration",
+			input: "1m30s",
+			want:  time.Second * 90,
+		},
+		{
+			name:  "Should treat bare number as seconds",
+			input: "2",
+			want:  time.Second * 2,
+		},
+		{
+			name:  "Should stop watching without interval",
+			input: "",
+		},
+		{
+			name:  "Should stop watching with off",
+			input: Off,
+		},
+		{
+			name:    "Should return an error for too short interval",
+			input:   "100ms",
+			wantErr: true,
+		},
+		{
+			name:    "Should return an error for invalid interval",
+			input:   "often",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := ParseInterval(tt.input)
+			if tt.wantErr {
+				require.ErrorIs(t, err, errs.ErrValidation)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSparkline(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+	require.Equal(t, "▁▄█", Sparkline([]int64{1, 5, 9}))
+	require.Equal(t, "▁▁", Sparkline([]int64{3, 3}))
+	require.Empty(t, Sparkline(nil))
+}
+
+func TestWatcher_Observe(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	w := New(time.Second)
+	cols := []string{"id", "name"}
+
+	w, rows := w.Observe(w.Result([][]string{{"1", "John"}}, cols, []string{"id"}, nil))
+	require.Equal(t, []Row{{Values: []string{"1", "John"}, Status: Unchanged}}, rows)
+
+	w, rows = w.Observe(w.Result([][]string{{"1", "Johnny"}}, cols, nil, nil))
+	require.Equal(t, []Row{{Values: []string{"1", "Johnny"}, Status: Changed}}, rows)
+	require.Equal(t, []string{"id"}, w.Key())
+}
@@ -2,22 +2,33 @@ package main
 
 import (
 	"cmp"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"golang.org/x/term"
 
 	"github.com/hrvadl/gowatchsql/internal/domain/engine"
 	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
 	"github.com/hrvadl/gowatchsql/internal/platform/db"
 	"github.com/hrvadl/gowatchsql/internal/platform/logger"
+	"github.com/hrvadl/gowatchsql/internal/platform/secret"
+	"github.com/hrvadl/gowatchsql/internal/ui/color"
+	"github.com/hrvadl/gowatchsql/internal/ui/keymap"
+	"github.com/hrvadl/gowatchsql/internal/ui/message"
 	"github.com/hrvadl/gowatchsql/internal/ui/models/welcome"
 )
 
 const (
 	configPathEnvVarName = "XDG_CONFIG_HOME"
 	homeEnvVarName       = "HOME"
+	// passphraseEnvVarName unlocks the vault without asking, and creates
+	// it when there is none.
+	passphraseEnvVarName = "GOWATCHSQL_VAULT_PASSPHRASE"
+
+	reconnectEventsBuffer = 16
 )
 
 var basePath = cmp.Or(os.Getenv(configPathEnvVarName), os.Getenv(homeEnvVarName))
@@ -28,13 +39,32 @@ func main() {
 		panic(err)
 	}
 
-	l := logger.New(f)
+	l := logger.New(f, slog.LevelInfo)
 	cfg, err := cfg.NewFromFile(basePath)
 	if err != nil {
 		l.Error("Failed to load config", slog.Any("err", err))
 		os.Exit(1)
 	}
 
+	if err := unlockVault(cfg, basePath, askPassphrase); err != nil {
+		l.Error("Failed to unlock the vault", slog.Any("err", err))
+		fmt.Fprintln(os.Stderr, "Failed to unlock the vault:", err)
+		os.Exit(1)
+	}
+
+	palette, err := color.Resolve(cfg.Theme, cfg.Themes)
+	if err != nil {
+		l.Error("Failed to load theme", slog.Any("err", err))
+		os.Exit(1)
+	}
+	color.Apply(palette)
+
+	keys, err := keymap.New(cfg.Keys)
+	if err != nil {
+		l.Error("Failed to load key bindings", slog.Any("err", err))
+		os.Exit(1)
+	}
+
 	pool := db.NewPool(cfg)
 
 	defer func() {
@@ -45,12 +75,81 @@ func main() {
 	}()
 
 	factory := engine.NewFactory(pool)
-	p := tea.NewProgram(welcome.NewModel(l, factory, cfg))
+	p := tea.NewProgram(welcome.NewModel(l, factory, cfg, keys))
+
+	// The pool reports from inside commands, and Send blocks until the
+	// program reads the message, so events are forwarded in order by a
+	// goroutine of their own.
+	events := make(chan tea.Msg, reconnectEventsBuffer)
+	go func() {
+		for msg := range events {
+			p.Send(msg)
+		}
+	}()
+
+	pool.Observe(func(e db.Event) {
+		msg := message.Reconnecting{Context: e.Name, Done: e.State != db.Reconnecting}
+		if e.State == db.Disconnected || e.State == db.Interrupted {
+			msg.Err = e.Err
+		}
+
+		select {
+		case events <- msg:
+		default:
+			l.Warn("Dropped connection event", slog.String("context", e.Name))
+		}
+	})
 
-	slog.SetLogLoggerLevel(slog.LevelDebug)
+	slog.SetDefault(logger.New(f, slog.LevelDebug))
 	l.Info("Starting the program")
 
 	if _, err := p.Run(); err != nil {
 		panic(fmt.Sprintf("Alas, there's been an error: %v", err))
 	}
 }
+
+// unlockVault unlocks the vault of the passwords with the passphrase of
+// the environment, or asks for it. Until there is a vault, it is asked
+// for on every start, as the passwords saved while the vault is locked
+// are lost at exit. The vault stays locked when no passphrase is given.
+func unlockVault(config *cfg.Config, base string, ask func(prompt string) (string, error)) error {
+	path := cfg.VaultPath(base)
+	passphrase, ok := os.LookupEnv(passphraseEnvVarName)
+	if !ok {
+		prompt := "Vault passphrase (empty to skip): "
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			prompt = "New vault passphrase to keep passwords (empty to skip): "
+		}
+
+		var err error
+		if passphrase, err = ask(prompt); err != nil {
+			return err
+		}
+	}
+
+	if passphrase == "" {
+		return nil
+	}
+
+	vault, err := secret.OpenVault(path, passphrase)
+	if err != nil {
+		return err
+	}
+	return config.Unlock(vault)
+}
+
+// askPassphrase reads the passphrase from the terminal without echoing
+// it. Nothing is asked when the app doesn't run in one.
+func askPassphrase(prompt string) (string, error) {
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", nil
+	}
+
+	fmt.Fprint(os.Stderr, prompt)
+	raw, err := term.ReadPassword(int(os.Stdin.Fd()))
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", fmt.Errorf("read passphrase: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/internal/platform/cfg"
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestUnlockVault(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	const (
+		dsn      = "postgres://app@localhost:5432/app"
+		password = "s3cr3t"
+	)
+
+	tests := []struct {
+		name         string
+		passphrase   string
+		wantPrompts  []string
+		wantPassword string
+	}{
+		{
+			name:       "Should keep password of fresh install in created vault",
+			passphrase: "passphrase",
+			wantPrompts: []string{
+				"New vault passphrase to keep passwords (empty to skip): ",
+				"Vault passphrase (empty to skip): ",
+			},
+			wantPassword: password,
+		},
+		{
+			name:       "Should ask to create vault again when skipped",
+			passphrase: "",
+			wantPrompts: []string{
+				"New vault passphrase to keep passwords (empty to skip): ",
+				"New vault passphrase to keep passwords (empty to skip): ",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			base := t.TempDir()
+
+			var prompts []string
+			ask := func(prompt string) (string, error) {
+				prompts = append(prompts, prompt)
+				return tt.passphrase, nil
+			}
+
+			config, err := cfg.NewFromFile(base)
+			require.NoError(t, err)
+			require.NoError(t, unlockVault(config, base, ask))
+
+			_, err = config.SaveConnection(t.Context(), cfg.Connection{Name: "app", DSN: dsn, Password: password})
+			require.NoError(t, err)
+			require.NoError(t, config.Close())
+
+			config, err = cfg.NewFromFile(base)
+			require.NoError(t, err)
+			t.Cleanup(func() {
+				require.NoError(t, config.Close())
+			})
+			require.NoError(t, unlockVault(config, base, ask))
+
+			got, err := config.GetPassword(t.Context(), dsn)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantPassword, got)
+			require.Equal(t, tt.wantPrompts, prompts)
+		})
+	}
+}
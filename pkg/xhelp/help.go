@@ -0,0 +1,48 @@
+package xhelp
+
+import (
+	"github.com/charmbracelet/bubbles/help"
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Join merges several key maps into one, so a parent model can
+// describe its own bindings together with the bindings of its
+// focused child. Nil key maps are skipped.
+func Join(maps ...help.KeyMap) help.KeyMap {
+	return joined(maps)
+}
+
+type joined []help.KeyMap
+
+func (j joined) ShortHelp() []key.Binding {
+	bindings := make([]key.Binding, 0)
+	for _, km := range j {
+		if km == nil {
+			continue
+		}
+		bindings = append(bindings, km.ShortHelp()...)
+	}
+	return bindings
+}
+
+func (j joined) FullHelp() [][]key.Binding {
+	groups := make([][]key.Binding, 0)
+	for _, km := range j {
+		if km == nil {
+			continue
+		}
+		groups = append(groups, km.FullHelp()...)
+	}
+	return groups
+}
+
+// Bindings adapts a flat list of bindings to help.KeyMap.
+type Bindings []key.Binding
+
+func (b Bindings) ShortHelp() []key.Binding {
+	return b
+}
+
+func (b Bindings) FullHelp() [][]key.Binding {
+	return [][]key.Binding{b}
+}
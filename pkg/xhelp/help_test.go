@@ -0,0 +1,22 @@
+package xhelp
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/stretchr/testify/require"
+
+	"github.com/hrvadl/gowatchsql/pkg/xtest"
+)
+
+func TestJoin(t *testing.T) {
+	xtest.SkipUnitIfRequired(t)
+	t.Parallel()
+
+	first := key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "first"))
+	second := key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "second"))
+
+	got := Join(Bindings{first}, nil, Bindings{second})
+	require.Equal(t, []key.Binding{first, second}, got.ShortHelp())
+	require.Equal(t, [][]key.Binding{{first}, {second}}, got.FullHelp())
+}
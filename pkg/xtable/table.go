@@ -4,6 +4,7 @@ import (
 	"math"
 	"strconv"
 
+	"github.com/charmbracelet/bubbles/help"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
@@ -17,21 +18,59 @@ const (
 	scrollRight = "l"
 )
 
+// DefaultKeyMap returns bubble-table defaults with vim-like horizontal
+// scrolling. Paging is moved off h/l so it doesn't clash with scrolling.
+func DefaultKeyMap() table.KeyMap {
+	keymap := table.DefaultKeyMap()
+	keymap.ScrollLeft = key.NewBinding(
+		key.WithKeys(scrollLeft),
+		key.WithHelp(scrollLeft, "scroll left"),
+	)
+	keymap.ScrollRight = key.NewBinding(
+		key.WithKeys(scrollRight),
+		key.WithHelp(scrollRight, "scroll right"),
+	)
+	keymap.PageDown = key.NewBinding(
+		key.WithKeys("right", "pgdown"),
+		key.WithHelp("→/page down", "next page"),
+	)
+	keymap.PageUp = key.NewBinding(
+		key.WithKeys("left", "pgup"),
+		key.WithHelp("←/page up", "previous page"),
+	)
+	return keymap
+}
+
+// NewHelp describes the table bindings from the given key map.
+func NewHelp(km table.KeyMap) help.KeyMap {
+	return keyMap(km)
+}
+
+type keyMap table.KeyMap
+
+func (k keyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.RowDown, k.RowUp, k.ScrollLeft, k.ScrollRight}
+}
+
+func (k keyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{
+		{k.RowDown, k.RowUp, k.PageDown, k.PageUp, k.PageFirst, k.PageLast},
+		{k.ScrollLeft, k.ScrollRight},
+	}
+}
+
 type Model struct {
 	base    table.Model
 	columns []string
 	rows    [][]string
+	styles  []lipgloss.Style
 	width   int
 }
 
 func New(cols []string, entries [][]string) Model {
 	xtable := Model{}
 	columns := toColumns(cols, xtable.getColumnWidth(entries, cols))
-	rows := toRows(entries)
-
-	keymap := table.DefaultKeyMap()
-	keymap.ScrollLeft = key.NewBinding(key.WithKeys(scrollLeft))
-	keymap.ScrollRight = key.NewBinding(key.WithKeys(scrollRight))
+	rows := toRows(entries, nil)
 
 	table := table.New(columns).
 		WithRows(rows).
@@ -41,9 +80,13 @@ func New(cols []string, entries [][]string) Model {
 				return rsfi.Row.Style
 			}
 
+			if color.Disabled() {
+				return rsfi.Row.Style.Reverse(true)
+			}
+
 			return rsfi.Row.Style.Background(color.MainAccent)
 		}).
-		WithKeyMap(keymap).
+		WithKeyMap(DefaultKeyMap()).
 		WithHorizontalFreezeColumnCount(1).
 		Focused(true)
 
@@ -72,15 +115,42 @@ func (t Model) KeyMap() table.KeyMap {
 	return t.base.KeyMap()
 }
 
+func (t Model) Help() help.KeyMap {
+	return NewHelp(t.base.KeyMap())
+}
+
+func (t Model) WithKeyMap(km table.KeyMap) Model {
+	t.base = t.base.WithKeyMap(km)
+	return t
+}
+
 func (t Model) WithTargetWidth(w int) Model {
 	t.base = t.base.WithTargetWidth(w)
 	return t
 }
 
+// HighlightedRow returns the index of the row under the cursor.
+func (t Model) HighlightedRow() int {
+	return t.base.GetHighlightedRowIndex()
+}
+
+// WithHighlightedRow moves the cursor to the row of the given index.
+func (t Model) WithHighlightedRow(i int) Model {
+	t.base = t.base.WithHighlightedRow(i)
+	return t
+}
+
+// WithRowStyles sets the style of each row, matched by index.
+func (t Model) WithRowStyles(styles []lipgloss.Style) Model {
+	t.styles = styles
+	t.base = t.base.WithRows(toRows(t.rows, t.styles))
+	return t
+}
+
 func (t Model) WithMaxTotalWidth(w int) Model {
 	t.width = w
 	columns := toColumns(t.columns, t.getColumnWidth(t.rows, t.columns))
-	rows := toRows(t.rows)
+	rows := toRows(t.rows, t.styles)
 
 	t.base = t.base.WithColumns(columns).WithRows(rows).WithMaxTotalWidth(w)
 	return t
@@ -129,15 +199,20 @@ func toColumns(cols []string, width []int) []table.Column {
 	return t
 }
 
-func toRows(entries [][]string) []table.Row {
+func toRows(entries [][]string, styles []lipgloss.Style) []table.Row {
 	rows := make([]table.Row, 0)
 
-	for _, row := range entries {
+	for i, row := range entries {
 		rowData := make(map[string]any)
 		for i, data := range row {
 			rowData[strconv.Itoa(i)] = data
 		}
-		rows = append(rows, table.NewRow(rowData))
+
+		r := table.NewRow(rowData)
+		if i < len(styles) {
+			r = r.WithStyle(styles[i])
+		}
+		rows = append(rows, r)
 	}
 
 	return rows